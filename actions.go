@@ -0,0 +1,280 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// summaryAction is an action of the summary, run by its key binding or from the command palette.
+type summaryAction struct {
+	binding   key.Binding
+	available func(m model) bool // Whether the action is available in the current view
+	run       func(m model) (model, tea.Cmd)
+}
+
+// withoutChart returns whether the summary shows the list rather than the chart.
+func withoutChart(m model) bool {
+	return !m.showChart
+}
+
+// summaryActions returns the actions of the summary, in the order of the help.
+// It is the registry of both the key bindings and the command palette,
+// so that new actions are shown in the help and listed in the palette.
+func summaryActions() []summaryAction {
+	return []summaryAction{
+		{
+			summaryKeys.chart,
+			func(model) bool { return true },
+			func(m model) (model, tea.Cmd) {
+				m.showChart = !m.showChart
+				return m, nil
+			},
+		},
+		{
+			summaryKeys.normalize,
+			func(m model) bool { return m.showChart },
+			func(m model) (model, tea.Cmd) {
+				m.normalizedChart = !m.normalizedChart
+				return m, nil
+			},
+		},
+		{
+			summaryKeys.sort,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				m.sort = m.sort.next()
+				m.list.Title = m.listTitle()
+				return m, m.list.SetItems(m.sortedItems())
+			},
+		},
+		{
+			summaryKeys.reverseSort,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				m.sort.ascending = !m.sort.ascending
+				m.list.Title = m.listTitle()
+				return m, m.list.SetItems(m.sortedItems())
+			},
+		},
+		{
+			summaryKeys.files,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				if item, ok := m.list.SelectedItem().(ListItem); ok {
+					files := newFilesList(item.AnalysisResult, m.list.Width(), m.list.Height())
+					m.files = &files
+				}
+				return m, nil
+			},
+		},
+		{
+			summaryKeys.annotate,
+			func(m model) bool { return !m.showChart && m.data.ghRepo != "" },
+			func(m model) (model, tea.Cmd) {
+				item, ok := m.list.SelectedItem().(ListItem)
+				if !ok {
+					return m, nil
+				}
+				input := textinput.New()
+				input.Placeholder = "Note about " + item.releaseTag
+				input.SetValue(item.note)
+				input.PromptStyle = svelteText
+				input.Cursor.Style = svelteText
+				input.Focus()
+				m.noteInput, m.noteTag = &input, item.releaseTag
+				m.resizeSummary()
+				return m, textinput.Blink
+			},
+		},
+		{
+			summaryKeys.columns,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				m.columnChooser = &columnChooser{}
+				return m, nil
+			},
+		},
+		{
+			summaryKeys.months,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				m.groupByMonth = !m.groupByMonth
+				return m, m.list.SetItems(m.sortedItems())
+			},
+		},
+		{
+			summaryKeys.pin,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				if item, ok := m.list.SelectedItem().(ListItem); ok {
+					return m.togglePin(item.releaseTag)
+				}
+				return m, nil
+			},
+		},
+		{
+			summaryKeys.language,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				var selected *ListItem
+				if item, ok := m.list.SelectedItem().(ListItem); ok {
+					selected = &item
+				}
+				picker := newLanguagePicker(m.data.analysis, selected, m.languageFilter)
+				m.languagePicker = &picker
+				return m, nil
+			},
+		},
+		{
+			summaryKeys.fromRelease,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				return m.jumpToEndpoint(fromEndpoint)
+			},
+		},
+		{
+			summaryKeys.toRelease,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				return m.jumpToEndpoint(toEndpoint)
+			},
+		},
+		{
+			summaryKeys.nextSpike,
+			withoutChart,
+			func(m model) (model, tea.Cmd) {
+				return m.jumpToNextSpike()
+			},
+		},
+		{
+			summaryKeys.palette,
+			func(model) bool { return true },
+			func(m model) (model, tea.Cmd) {
+				palette := newCommandPalette(m)
+				m.palette = &palette
+				return m, textinput.Blink
+			},
+		},
+	}
+}
+
+// summaryBindings returns the key bindings of the summary actions, to be shown in the list help.
+func summaryBindings() []key.Binding {
+	actions := summaryActions()
+	bindings := make([]key.Binding, len(actions))
+	for i, action := range actions {
+		bindings[i] = action.binding
+	}
+	return bindings
+}
+
+// commandPalette lists the actions available in the current view of the summary,
+// fuzzy filtered by their description and key.
+type commandPalette struct {
+	input   textinput.Model
+	actions []summaryAction // Actions available when the palette was opened
+	matches []int           // Indexes of the actions matching the input, best match first
+	cursor  int             // Index of the highlighted match
+}
+
+// newCommandPalette returns a palette of the actions available in the current view,
+// except the palette itself.
+func newCommandPalette(m model) commandPalette {
+	input := textinput.New()
+	input.Placeholder = "Type to search the actions"
+	input.PromptStyle = svelteText
+	input.Cursor.Style = svelteText
+	input.Focus()
+	palette := commandPalette{input: input}
+	for _, action := range summaryActions() {
+		if action.binding.Help() != summaryKeys.palette.Help() && action.available(m) {
+			palette.actions = append(palette.actions, action)
+		}
+	}
+	palette.filter()
+	return palette
+}
+
+// filter updates the matches to the input, moving the highlight to the best match.
+func (p *commandPalette) filter() {
+	p.cursor = 0
+	p.matches = p.matches[:0]
+	query := strings.TrimSpace(p.input.Value())
+	if query == "" {
+		for i := range p.actions {
+			p.matches = append(p.matches, i)
+		}
+		return
+	}
+	targets := make([]string, len(p.actions))
+	for i, action := range p.actions {
+		targets[i] = action.binding.Help().Desc + " " + action.binding.Help().Key
+	}
+	for _, rank := range list.DefaultFilter(query, targets) {
+		p.matches = append(p.matches, rank.Index)
+	}
+}
+
+func (p commandPalette) view() string {
+	rows := make([]string, len(p.matches))
+	for i, index := range p.matches {
+		help := p.actions[index].binding.Help()
+		if i == p.cursor {
+			rows[i] = svelteText.Render("> "+help.Desc) + blurredStyle.Render("  "+help.Key)
+		} else {
+			rows[i] = "  " + help.Desc + blurredStyle.Render("  "+help.Key)
+		}
+	}
+	if len(rows) == 0 {
+		rows = []string{blurredStyle.Render("  No matching action")}
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		svelteBg.Padding(0, 1).Render("Command palette"),
+		"",
+		p.input.View(),
+		"",
+		strings.Join(rows, "\n"),
+		"",
+		blurredStyle.Render("↑/↓ move • enter run • esc close"),
+	)
+}
+
+// updatePalette handles a key while the command palette is open:
+// enter runs the highlighted action, esc closes the palette leaving the summary as it was,
+// and any other key edits the search.
+func (m model) updatePalette(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.palette = nil
+		return m, nil
+	case tea.KeyUp, tea.KeyCtrlK:
+		if m.palette.cursor > 0 {
+			m.palette.cursor--
+		}
+		return m, nil
+	case tea.KeyDown, tea.KeyCtrlJ:
+		if m.palette.cursor < len(m.palette.matches)-1 {
+			m.palette.cursor++
+		}
+		return m, nil
+	case tea.KeyEnter:
+		if len(m.palette.matches) == 0 {
+			return m, nil
+		}
+		action := m.palette.actions[m.palette.matches[m.palette.cursor]]
+		m.palette = nil
+		return action.run(m)
+	}
+	input, cmd := m.palette.input.Update(msg)
+	m.palette.input = input
+	m.palette.filter()
+	return m, cmd
+}
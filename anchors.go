@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// semver is a parsed semantic version. Build metadata is ignored.
+type semver struct {
+	major, minor, patch int
+	prerelease          string
+}
+
+// parseSemver parses a semantic version, with or without a leading `v`.
+func parseSemver(version string) (semver, bool) {
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, _ := strings.Cut(version, "-")
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return semver{}, false
+		}
+		numbers[i] = number
+	}
+	return semver{numbers[0], numbers[1], numbers[2], prerelease}, true
+}
+
+// less returns whether v precedes other. Prereleases are compared lexically.
+func (v semver) less(other semver) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+	if v.patch != other.patch {
+		return v.patch < other.patch
+	}
+	if v.prerelease == "" || other.prerelease == "" {
+		return v.prerelease != "" && other.prerelease == ""
+	}
+	return v.prerelease < other.prerelease
+}
+
+// releaseSemver parses the semantic version of a GitHub release tag,
+// either as an npm `package@version` tag or as a plain version tag.
+func releaseSemver(tag string) (semver, bool) {
+	if _, version := npmPackageVersion(tag); version != "" {
+		return parseSemver(version)
+	}
+	return parseSemver(tag)
+}
+
+// releaseDate returns the publication date of a release, or its creation date.
+func releaseDate(release Release) time.Time {
+	if release.PublishedAt != nil {
+		return *release.PublishedAt
+	}
+	return release.CreatedAt
+}
+
+// anchor is a release the `to` release is compared against.
+type anchor struct {
+	label string // What the anchor is, e.g. "previous major"
+	index int    // Index of the anchor in the releases
+}
+
+// anchorMaxDrift is the maximum distance between the date of the release
+// resolved as "one year ago" and the exact date one year before.
+const anchorMaxDrift = 31 * 24 * time.Hour
+
+// resolveAnchors resolves the anchors of the release at toIndex among the releases:
+// the `from` release, the latest stable release before the current minor,
+// the latest stable release before the current major, and the release published
+// the nearest to one year before. Missing anchors are omitted, and a release
+// is only used once, for the first anchor it resolves to.
+func resolveAnchors(releases []Release, toIndex, fromIndex int) []anchor {
+	var anchors []anchor
+	used := map[int]bool{toIndex: true}
+	add := func(label string, index int) {
+		if index < 0 || used[index] {
+			return
+		}
+		used[index] = true
+		anchors = append(anchors, anchor{label, index})
+	}
+
+	add("from", fromIndex)
+
+	// Previous minor and major, by semantic version
+	if to, ok := releaseSemver(releases[toIndex].TagName); ok {
+		latestBefore := func(bound semver) int {
+			best, bestVersion := -1, semver{}
+			for i, release := range releases {
+				version, ok := releaseSemver(release.TagName)
+				if !ok || version.prerelease != "" || !version.less(bound) {
+					continue
+				}
+				if best == -1 || bestVersion.less(version) {
+					best, bestVersion = i, version
+				}
+			}
+			return best
+		}
+		add("previous minor", latestBefore(semver{major: to.major, minor: to.minor}))
+		add("previous major", latestBefore(semver{major: to.major}))
+	}
+
+	// One year ago, by publication date
+	if date := releaseDate(releases[toIndex]); !date.IsZero() {
+		target := date.AddDate(-1, 0, 0)
+		nearest, nearestDrift := -1, anchorMaxDrift
+		for i, release := range releases {
+			published := releaseDate(release)
+			if published.IsZero() {
+				// The drift from an unknown date would overflow
+				continue
+			}
+			drift := published.Sub(target)
+			if drift < 0 {
+				drift = -drift
+			}
+			if drift <= nearestDrift {
+				nearest, nearestDrift = i, drift
+			}
+		}
+		add("one year ago", nearest)
+	}
+
+	return anchors
+}
+
+// resolveAnchors resolves the anchors of the compared release among the analyzed releases.
+func (d data) resolveAnchors() []anchor {
+	to, from := d.planReport.ResolvedTo, d.planReport.ResolvedFrom
+	if to == "" {
+		to, from = d.secondRelease, d.firstRelease
+	}
+	toIndex, fromIndex := -1, -1
+	for i, release := range d.releases {
+		switch release.TagName {
+		case to:
+			toIndex = i
+		case from:
+			fromIndex = i
+		}
+	}
+	if toIndex == -1 {
+		return nil
+	}
+	return resolveAnchors(d.releases, toIndex, fromIndex)
+}
+
+// anchorDiff returns the analysis of the compared release, the analysis of the anchor,
+// and the change of lines between them with its unit, see lineChange,
+// not measured if either analysis failed.
+func (d data) anchorDiff(a anchor) (to, other AnalysisResult, diff measure[int], unit string) {
+	to, other = d.analysis[d.anchorsTarget()], d.analysis[a.index]
+	diff, unit = lineChange(other, to)
+	return to, other, diff, unit
+}
+
+// anchorsTarget returns the index of the compared release in the analyzed releases.
+func (d data) anchorsTarget() int {
+	to := d.planReport.ResolvedTo
+	if to == "" {
+		to = d.secondRelease
+	}
+	for i, analysis := range d.analysis {
+		if analysis.releaseTag == to {
+			return i
+		}
+	}
+	return 0
+}
+
+// anchorsPanel renders the comparison of the compared release against each anchor,
+// one anchor per line, or an empty string without anchors.
+func (d data) anchorsPanel() string {
+	rows := make([]string, len(d.anchors))
+	for i, a := range d.anchors {
+		to, other, diff, unit := d.anchorDiff(a)
+		rows[i] = fmt.Sprintf("%s vs %s (%s): %s", to.releaseTag, a.label, other.releaseTag, textForUnitDiff(diff, unit))
+	}
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// anchorReleases are the releases the anchors are resolved among, from the newest to the oldest.
+func anchorReleases() []Release {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	return []Release{
+		testRelease("v3.2.0", date(2025, time.June, 1)),
+		testRelease("v3.1.2", date(2025, time.May, 1)),
+		testRelease("v3.1.0", date(2025, time.March, 1)),
+		testRelease("v3.0.0", date(2025, time.January, 1)),
+		testRelease("v3.0.0-beta.1", date(2024, time.December, 1)),
+		testRelease("v2.5.0", date(2024, time.September, 1)),
+		testRelease("v2.4.0", date(2024, time.June, 3)),
+		testRelease("nightly", date(2024, time.May, 1)),
+		testRelease("v2.0.0", date(2024, time.January, 1)),
+		testRelease("v1.0.0", date(2023, time.January, 1)),
+		{TagName: "v0.1.0"},
+	}
+}
+
+func TestResolveAnchors(t *testing.T) {
+	releases := anchorReleases()
+	index := func(tag string) int {
+		for i, release := range releases {
+			if release.TagName == tag {
+				return i
+			}
+		}
+		return -1
+	}
+	for _, test := range []struct {
+		name     string
+		to, from string
+		anchors  []string // Label and tag of each anchor
+	}{
+		{
+			"every anchor", "v3.2.0", "v1.0.0", []string{
+				"from", "v1.0.0",
+				"previous minor", "v3.1.2",
+				"previous major", "v2.5.0",
+				"one year ago", "v2.4.0",
+			},
+		},
+		{
+			// The prerelease of the current major is never an anchor
+			"from the previous minor", "v3.2.0", "v3.1.2", []string{
+				"from", "v3.1.2",
+				"previous major", "v2.5.0",
+				"one year ago", "v2.4.0",
+			},
+		},
+		{
+			// The previous minor of a major is also its previous major, and one year ago is the from release
+			"major", "v3.0.0", "v2.0.0", []string{
+				"from", "v2.0.0",
+				"previous minor", "v2.5.0",
+			},
+		},
+		{
+			// One year ago is by date, even for a release without a version
+			"one year ago without from", "v3.1.2", "", []string{
+				"previous minor", "v3.0.0",
+				"previous major", "v2.5.0",
+				"one year ago", "nightly",
+			},
+		},
+		{
+			// The previous minor is also the previous major, and nothing was published one year ago within a month
+			"first major", "v1.0.0", "", []string{
+				"previous minor", "v0.1.0",
+			},
+		},
+		{
+			"oldest", "v0.1.0", "", nil,
+		},
+		{
+			// No semantic version, only the dates
+			"without a version", "nightly", "v1.0.0", []string{
+				"from", "v1.0.0",
+			},
+		},
+		{
+			"without a date", "v0.1.0", "v1.0.0", []string{
+				"from", "v1.0.0",
+			},
+		},
+		{
+			"one year ago too far", "v2.5.0", "", []string{
+				"previous minor", "v2.4.0",
+				"previous major", "v1.0.0",
+			},
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				var anchors []string
+				for _, anchor := range resolveAnchors(releases, index(test.to), index(test.from)) {
+					anchors = append(anchors, anchor.label, releases[anchor.index].TagName)
+				}
+				if !reflect.DeepEqual(anchors, test.anchors) {
+					t.Errorf("resolved %q, expected %q", anchors, test.anchors)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// baselineVersion is the version of the baseline file format.
+// It must be bumped whenever the format changes in an incompatible way,
+// migrating the previous versions in ReadBaseline.
+const baselineVersion = 1
+
+// minBaselineVersion is the oldest version of the baseline file format that can be read.
+const minBaselineVersion = 1
+
+// BaselineMode is the mode of the `--baseline` flag.
+type BaselineMode string
+
+const (
+	// BaselineNone means no baseline is used.
+	BaselineNone BaselineMode = ""
+	// BaselineRead means the base release is read from a baseline file.
+	BaselineRead BaselineMode = "read"
+	// BaselineWrite means the compared release is written to a baseline file.
+	BaselineWrite BaselineMode = "write"
+)
+
+// Baseline is the on-disk representation of a single analyzed release,
+// used to compare against a previous run without re-analyzing it.
+type Baseline struct {
+	Version         int               `json:"version"`                      // Version of the baseline format
+	AppVersion      string            `json:"app_version"`                  // Version of the application that wrote the file
+	ReleaseTag      string            `json:"release_tag"`                  // Tag of the analyzed release
+	TotalLines      uint              `json:"total_lines"`                  // Total number of lines
+	TotalFiles      uint              `json:"total_files"`                  // Total number of files
+	LinesByLanguage map[string]uint   `json:"lines_by_language"`            // Number of lines by language
+	LinesByExt      map[string]uint   `json:"lines_by_extension"`           // Number of lines by file extension
+	Files           map[string]uint   `json:"files"`                        // Number of lines by file path
+	ExcludedLines   uint              `json:"excluded_lines"`               // Lines of the files in excluded directories
+	ExcludedFiles   uint              `json:"excluded_files"`               // Files in excluded directories
+	GeneratedLines  uint              `json:"generated_lines,omitempty"`    // Lines of the generated and minified files, unless included
+	GeneratedFiles  uint              `json:"generated_files,omitempty"`    // Generated and minified files, unless included
+	TopFiles        uint              `json:"top_files,omitempty"`          // Number of largest files analyzed, 0 if all the files were
+	Coverage        float64           `json:"coverage,omitempty"`           // Ratio of the bytes covered by the analyzed files
+	ESMFiles        uint              `json:"esm_files"`                    // JavaScript files using ES modules
+	CJSFiles        uint              `json:"cjs_files"`                    // JavaScript files using CommonJS
+	PackageName     string            `json:"package_name"`                 // Name of the package, from its root manifest
+	TarSize         uint64            `json:"tar_size,omitempty"`           // Size of the gzipped tarball in bytes, 0 if unknown
+	TotalDirSize    int64             `json:"total_dir_size"`               // Unpacked bytes of the analyzed files
+	Dependencies    map[string]string `json:"dependencies,omitempty"`       // Version ranges of the dependencies
+	Engines         map[string]string `json:"engines,omitempty"`            // Supported versions of the engines
+	PackageManager  string            `json:"package_manager,omitempty"`    // Package manager of the package
+	Warnings        []string          `json:"warnings,omitempty"`           // Analysis warnings
+	Failed          string            `json:"failed,omitempty"`             // Why the analysis failed, empty if it succeeded
+	LFLines         uint              `json:"lf_lines"`                     // Lines ending with a bare \n
+	CRLFLines       uint              `json:"crlf_lines"`                   // Lines ending with \r\n
+	LargeFileCount  uint              `json:"large_file_count"`             // Files larger than the large file size
+	NormalizedLines *uint             `json:"normalized_lines,omitempty"`   // Lines once whitespace normalized, if counted
+	LineKinds       *LineKinds        `json:"line_kinds,omitempty"`         // Lines by kind, if counted
+	OtherByExt      map[string]uint   `json:"other_by_extension,omitempty"` // Lines of no known language by file extension
+	LargeFiles      map[string]int64  `json:"large_files,omitempty"`        // Sizes of the largest of the large files, by path
+}
+
+// ParseBaselineFlag parses the value of the `--baseline` flag,
+// formatted as `read=path.json` or `write=path.json`.
+func ParseBaselineFlag(value string) (BaselineMode, string, error) {
+	if value == "" {
+		return BaselineNone, "", nil
+	}
+	mode, path, found := strings.Cut(value, "=")
+	if !found || path == "" {
+		return BaselineNone, "", fmt.Errorf("invalid baseline %q. Format: read=path.json or write=path.json", value)
+	}
+	switch BaselineMode(mode) {
+	case BaselineRead, BaselineWrite:
+		return BaselineMode(mode), path, nil
+	default:
+		return BaselineNone, "", fmt.Errorf("invalid baseline mode %q, expected read or write", mode)
+	}
+}
+
+// newBaseline returns the baseline of the analysis result of a release.
+func newBaseline(analysis AnalysisResult) Baseline {
+	baseline := Baseline{
+		Version:         baselineVersion,
+		AppVersion:      appVersion,
+		ReleaseTag:      analysis.releaseTag,
+		TotalLines:      analysis.totalLines,
+		TotalFiles:      analysis.totalFiles,
+		LinesByLanguage: analysis.linesByLanguage,
+		LinesByExt:      analysis.linesByExt,
+		Files:           analysis.files,
+		ExcludedLines:   analysis.excludedLines,
+		ExcludedFiles:   analysis.excludedFiles,
+		GeneratedLines:  analysis.generatedLines,
+		GeneratedFiles:  analysis.generatedFiles,
+		TopFiles:        analysis.topFiles,
+		Coverage:        analysis.coverage,
+		ESMFiles:        analysis.esmFiles,
+		CJSFiles:        analysis.cjsFiles,
+		PackageName:     analysis.packageName,
+		TarSize:         analysis.tarSize,
+		TotalDirSize:    analysis.totalDirSize,
+		Dependencies:    analysis.dependencies,
+		Engines:         analysis.engines,
+		PackageManager:  analysis.packageManager,
+		Warnings:        analysis.warnings,
+		Failed:          analysis.failed,
+		LFLines:         analysis.lfLines,
+		CRLFLines:       analysis.crlfLines,
+		LargeFileCount:  analysis.largeFileCount,
+		OtherByExt:      analysis.otherByExt,
+		LargeFiles:      make(map[string]int64, len(analysis.largeFiles)),
+	}
+	for _, file := range analysis.largeFiles {
+		baseline.LargeFiles[file.path] = file.size
+	}
+	if analysis.whitespaceNormalized {
+		normalized := analysis.normalizedLines
+		baseline.NormalizedLines = &normalized
+	}
+	if analysis.kindsCounted {
+		kinds := analysis.lineKinds
+		baseline.LineKinds = &kinds
+	}
+	return baseline
+}
+
+// analysisResult returns the analysis result the baseline was written from.
+func (b Baseline) analysisResult() AnalysisResult {
+	result := AnalysisResult{
+		releaseTag:      b.ReleaseTag,
+		totalLines:      b.TotalLines,
+		totalFiles:      b.TotalFiles,
+		linesByLanguage: b.LinesByLanguage,
+		linesByExt:      b.LinesByExt,
+		files:           b.Files,
+		excludedLines:   b.ExcludedLines,
+		excludedFiles:   b.ExcludedFiles,
+		generatedLines:  b.GeneratedLines,
+		generatedFiles:  b.GeneratedFiles,
+		topFiles:        b.TopFiles,
+		coverage:        b.Coverage,
+		esmFiles:        b.ESMFiles,
+		cjsFiles:        b.CJSFiles,
+		packageName:     b.PackageName,
+		tarSize:         b.TarSize,
+		totalDirSize:    b.TotalDirSize,
+		dependencies:    b.Dependencies,
+		engines:         b.Engines,
+		packageManager:  b.PackageManager,
+		failed:          b.Failed,
+		lfLines:         b.LFLines,
+		crlfLines:       b.CRLFLines,
+		largeFileCount:  b.LargeFileCount,
+		otherByExt:      b.OtherByExt,
+	}
+	if b.NormalizedLines != nil {
+		result.normalizedLines, result.whitespaceNormalized = *b.NormalizedLines, true
+	}
+	if b.LineKinds != nil {
+		result.lineKinds, result.kindsCounted = *b.LineKinds, true
+	}
+	if result.linesByLanguage == nil {
+		result.linesByLanguage = make(map[string]uint)
+	}
+	if result.linesByExt == nil {
+		result.linesByExt = make(map[string]uint)
+	}
+	for _, warning := range b.Warnings {
+		result.addWarning(warning)
+	}
+	for path, size := range b.LargeFiles {
+		result.largeFiles = append(result.largeFiles, fileSize{path, size})
+	}
+	sortLargestFirst(result.largeFiles)
+	return result
+}
+
+// WriteBaseline writes the analysis result of a release to a baseline file.
+func WriteBaseline(path string, analysis AnalysisResult) error {
+	content, err := json.MarshalIndent(newBaseline(analysis), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// writeBaseline writes the analysis result of the compared release, the newest endpoint of the plan
+// even if the endpoints were given swapped, to the baseline file. A failed analysis isn't written,
+// as comparing to it later would be meaningless. Nothing is written if the release isn't part of the run.
+func (d data) writeBaseline() error {
+	tag := d.planReport.ResolvedTo
+	if tag == "" {
+		tag = d.secondRelease
+	}
+	for _, analysis := range d.analysis {
+		if analysis.releaseTag != tag {
+			continue
+		}
+		if analysis.failed != "" {
+			return fmt.Errorf("the analysis of %s failed (%s), it is not written as a baseline", tag, analysis.failed)
+		}
+		return WriteBaseline(d.baselinePath, analysis)
+	}
+	// The compared release is analyzed by another shard
+	return nil
+}
+
+// ReadBaseline reads the analysis result of a release from a baseline file,
+// from any version since minBaselineVersion.
+func ReadBaseline(path string) (AnalysisResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+
+	var baseline Baseline
+	if err = json.Unmarshal(content, &baseline); err != nil {
+		return AnalysisResult{}, fmt.Errorf("invalid baseline file %s: %w", path, err)
+	}
+	err = checkFormatVersion("baseline file", path, baseline.Version, minBaselineVersion, baselineVersion, baseline.AppVersion)
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+	if baseline.ReleaseTag == "" {
+		return AnalysisResult{}, fmt.Errorf("invalid baseline file %s: missing release tag", path)
+	}
+
+	return baseline.analysisResult(), nil
+}
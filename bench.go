@@ -0,0 +1,240 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// benchSeed is the seed of the synthetic releases, so that every benchmark
+// run measures the same corpus.
+const benchSeed = 42
+
+// benchExtensions are the extensions of the synthetic files, picked at random.
+var benchExtensions = []string{".js", ".js", ".js", ".ts", ".d.ts", ".json", ".md", ".css"}
+
+// BenchOptions gathers every option of the self-benchmark.
+type BenchOptions struct {
+	Releases    int   // Number of synthetic releases processed at each concurrency level
+	Files       int   // Number of files of each synthetic release
+	FileSize    int   // Approximate size of each synthetic file, in bytes
+	Seed        int64 // Seed of the synthetic releases
+	Concurrency []int // Concurrency levels to measure
+}
+
+// BenchResult is the measure of the pipeline at a concurrency level.
+type BenchResult struct {
+	Concurrency int           // Number of releases processed at once
+	Duration    time.Duration // Time taken to extract and analyze every release
+	Files       uint          // Number of analyzed files
+	Lines       uint          // Number of analyzed lines
+	Bytes       int64         // Size of the extracted files
+}
+
+// benchConcurrencyLevels returns the powers of two up to the number of CPUs,
+// plus the number of CPUs itself.
+func benchConcurrencyLevels() []int {
+	var levels []int
+	cpus := runtime.NumCPU()
+	for level := 1; level < cpus; level *= 2 {
+		levels = append(levels, level)
+	}
+	return append(levels, cpus)
+}
+
+// GenerateSyntheticRelease generates a gzipped npm-like tarball of files
+// of roughly fileSize bytes each, spread across nested directories
+// under the `package/` root. The same seed always generates the same tarball.
+func GenerateSyntheticRelease(seed int64, files, fileSize int) ([]byte, error) {
+	random := rand.New(rand.NewSource(seed))
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	for i := 0; i < files; i++ {
+		// Build the file content line by line
+		var content strings.Builder
+		for content.Len() < fileSize {
+			indent := strings.Repeat("\t", random.Intn(4))
+			width := 8 + random.Intn(72)
+			line := make([]byte, width)
+			for j := range line {
+				line[j] = byte('a' + random.Intn(26))
+			}
+			content.WriteString(indent)
+			content.Write(line)
+			content.WriteByte('\n')
+		}
+
+		name := fmt.Sprintf(
+			"package/src/dir%d/sub%d/file%d%s",
+			random.Intn(16), random.Intn(4), i, benchExtensions[random.Intn(len(benchExtensions))],
+		)
+		header := &tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(content.Len()),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(tarWriter, content.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RunBench extracts and analyzes synthetic releases in a temporary directory
+// at each concurrency level, and returns the measure of each level.
+func RunBench(opts BenchOptions, settings AnalysisSettings) ([]BenchResult, error) {
+	tarballs := make([][]byte, opts.Releases)
+	for i := range tarballs {
+		tarball, err := GenerateSyntheticRelease(opts.Seed+int64(i), opts.Files, opts.FileSize)
+		if err != nil {
+			return nil, err
+		}
+		tarballs[i] = tarball
+	}
+
+	results := make([]BenchResult, 0, len(opts.Concurrency))
+	for _, concurrency := range opts.Concurrency {
+		result, err := benchLevel(tarballs, concurrency, settings)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// benchLevel extracts and analyzes the tarballs with up to concurrency releases
+// processed at once, and returns the measure.
+func benchLevel(tarballs [][]byte, concurrency int, settings AnalysisSettings) (BenchResult, error) {
+	result := BenchResult{Concurrency: concurrency}
+	dir, err := os.MkdirTemp("", "npm-stats-comparator-bench-*")
+	if err != nil {
+		return result, err
+	}
+	defer func(dir string) {
+		_ = os.RemoveAll(dir)
+	}(dir)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	slots := make(chan struct{}, concurrency)
+	start := time.Now()
+	for i, tarball := range tarballs {
+		i, tarball := i, tarball
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			tag := fmt.Sprintf("bench-%d", i)
+			analysis, err := benchRelease(dir, tag, tarball, settings)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			result.Files += analysis.totalFiles
+			result.Lines += analysis.totalLines
+		}()
+	}
+	wg.Wait()
+	result.Duration = time.Since(start)
+	if firstErr != nil {
+		return result, firstErr
+	}
+
+	for _, tarball := range tarballs {
+		err := WalkTar(
+			bytes.NewReader(tarball), func(header *tar.Header, _ io.Reader) error {
+				result.Bytes += header.Size
+				return nil
+			},
+		)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// benchRelease extracts a tarball into the directory and analyzes it,
+// the same way the pipeline does with a downloaded release.
+func benchRelease(dir, tag string, tarball []byte, settings AnalysisSettings) (AnalysisResult, error) {
+	releaseDir := filepath.Join(dir, tag)
+	if err := Untar(releaseDir, bytes.NewReader(tarball)); err != nil {
+		return AnalysisResult{}, err
+	}
+	switch msg := AnalyzeRelease(context.Background(), releaseDir, tag, settings, 0)().(type) {
+	case analysisDoneMsg:
+		if msg.failed != "" {
+			return AnalysisResult{}, fmt.Errorf("%s: %s", tag, msg.failed)
+		}
+		return AnalysisResult(msg), nil
+	default:
+		return AnalysisResult{}, fmt.Errorf("unexpected analysis message %T", msg)
+	}
+}
+
+// runBenchCommand runs the self-benchmark and prints its results as a table,
+// returning the exit code of the program.
+func runBenchCommand(opts BenchOptions, settings AnalysisSettings) int {
+	fmt.Printf(
+		"Benchmarking %d synthetic releases of %d files of ~%d bytes (seed %d)...\n",
+		opts.Releases, opts.Files, opts.FileSize, opts.Seed,
+	)
+	results, err := RunBench(opts, settings)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(writer, "concurrency\tduration\treleases/s\tfiles/s\tMB/s\t")
+	for _, result := range results {
+		seconds := result.Duration.Seconds()
+		_, _ = fmt.Fprintf(
+			writer, "%d\t%s\t%.1f\t%.0f\t%.1f\t\n",
+			result.Concurrency,
+			result.Duration.Round(time.Millisecond),
+			float64(opts.Releases)/seconds,
+			float64(result.Files)/seconds,
+			float64(result.Bytes)/1_000_000/seconds,
+		)
+	}
+	if err = writer.Flush(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
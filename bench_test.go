@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateSyntheticRelease(t *testing.T) {
+	first, err := GenerateSyntheticRelease(benchSeed, 10, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GenerateSyntheticRelease(benchSeed, 10, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("the same seed generated different tarballs")
+	}
+	other, err := GenerateSyntheticRelease(benchSeed+1, 10, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, other) {
+		t.Error("different seeds generated the same tarball")
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	opts := BenchOptions{Releases: 3, Files: 20, FileSize: 512, Seed: benchSeed, Concurrency: []int{1, 2}}
+	results, err := RunBench(opts, AnalysisSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(opts.Concurrency) {
+		t.Fatalf("%d results, expected one per concurrency level", len(results))
+	}
+	for i, result := range results {
+		if result.Concurrency != opts.Concurrency[i] {
+			t.Errorf("result %d at concurrency %d, expected %d", i, result.Concurrency, opts.Concurrency[i])
+		}
+		if expected := uint(opts.Releases * opts.Files); result.Files != expected {
+			t.Errorf("concurrency %d: %d files analyzed, expected %d", result.Concurrency, result.Files, expected)
+		}
+		if result.Lines == 0 || result.Bytes < int64(opts.Releases*opts.Files*opts.FileSize) {
+			t.Errorf("concurrency %d: %d lines and %d bytes analyzed", result.Concurrency, result.Lines, result.Bytes)
+		}
+		// Every level analyzes the same corpus
+		if result.Lines != results[0].Lines || result.Bytes != results[0].Bytes {
+			t.Errorf("concurrency %d measured another corpus than concurrency %d", result.Concurrency, results[0].Concurrency)
+		}
+	}
+}
+
+func BenchmarkAnalyzeRelease(b *testing.B) {
+	tarball, err := GenerateSyntheticRelease(benchSeed, 200, 4096)
+	if err != nil {
+		b.Fatal(err)
+	}
+	dir := filepath.Join(b.TempDir(), "bench")
+	if err = Untar(dir, bytes.NewReader(tarball)); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(200 * 4096))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msg, ok := AnalyzeRelease(context.Background(), dir, "bench", AnalysisSettings{}, 0)().(analysisDoneMsg)
+		if !ok || msg.failed != "" || msg.totalFiles != 200 {
+			b.Fatalf("unexpected analysis: %+v", msg)
+		}
+	}
+}
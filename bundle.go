@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// bundleVersion is the version of the comparison bundle format.
+// It must be bumped whenever the format changes in an incompatible way,
+// keeping the reading of the previous versions in ReadBundle.
+const bundleVersion = 1
+
+// minBundleVersion is the oldest version of the comparison bundle format that can be read.
+const minBundleVersion = 1
+
+// Bundle is a portable comparison: everything needed to show its summary
+// without downloading nor analyzing anything, see `--export bundle=path` and `--import`.
+type Bundle struct {
+	Version    int                 `json:"version"`              // Version of the bundle format
+	AppVersion string              `json:"app_version"`          // Version of the application that wrote the bundle
+	Repository string              `json:"repository"`           // GitHub repository of the releases
+	From       string              `json:"from"`                 // Base release
+	To         string              `json:"to"`                   // Release compared to
+	Settings   AnalysisSettings    `json:"settings"`             // Analysis settings of the comparison
+	Report     PlanReport          `json:"report"`               // Report of the selection of the releases
+	Releases   []Release           `json:"releases"`             // GitHub releases, from the newest to the oldest
+	Analysis   []Baseline          `json:"analysis"`             // Analysis results, with the lines of every file
+	Notes      map[string]string   `json:"notes,omitempty"`      // Notes about the releases, by tag
+	Unverified map[string][]string `json:"unverified,omitempty"` // Published files absent from the tagged source, by tag
+	Shard      string              `json:"shard,omitempty"`      // Shard of the releases, such as 1/4, empty if the bundle holds all of them
+	Planned    int                 `json:"planned,omitempty"`    // Number of planned releases, including the ones of the other shards
+}
+
+// EncodeBundle encodes the comparison as a gzipped JSON bundle.
+func EncodeBundle(d data) ([]byte, error) {
+	bundle := Bundle{
+		Version:    bundleVersion,
+		AppVersion: appVersion,
+		Repository: d.ghRepo,
+		From:       d.firstRelease,
+		To:         d.secondRelease,
+		Settings:   d.analysisSettings(),
+		Report:     d.planReport,
+		Releases:   d.releases,
+		Analysis:   make([]Baseline, len(d.analysis)),
+		Notes:      d.notes,
+		Unverified: d.unverified,
+		Shard:      d.shard.String(),
+		Planned:    d.planned,
+	}
+	for i, analysis := range d.analysis {
+		bundle.Analysis[i] = newBaseline(analysis)
+	}
+
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gzWriter).Encode(bundle); err != nil {
+		return nil, err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadBundle reads a comparison bundle, from any version since minBundleVersion.
+func ReadBundle(path string) (Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Bundle{}, err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+	content, err := io.ReadAll(gzReader)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+
+	var bundle Bundle
+	if err = json.Unmarshal(content, &bundle); err != nil {
+		return Bundle{}, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+	err = checkFormatVersion("bundle", path, bundle.Version, minBundleVersion, bundleVersion, bundle.AppVersion)
+	if err != nil {
+		return Bundle{}, err
+	}
+	return bundle, nil
+}
+
+// importBundle loads the comparison of the bundle into the data.
+func (d *data) importBundle(bundle Bundle) {
+	d.ghRepo = bundle.Repository
+	d.firstRelease, d.secondRelease = bundle.From, bundle.To
+	d.ignoreRegex, d.ignoreMode = bundle.Settings.IgnoreRegex, bundle.Settings.IgnoreMode
+	d.planReport = bundle.Report
+	d.releases = bundle.Releases
+	d.analysis = make([]AnalysisResult, len(bundle.Analysis))
+	for i, baseline := range bundle.Analysis {
+		d.analysis[i] = baseline.analysisResult()
+	}
+	d.notes = bundle.Notes
+	d.unverified = bundle.Unverified
+	d.imported = &bundle.Settings
+}
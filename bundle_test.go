@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	published := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	d := data{
+		ghRepo:        "owner/repo",
+		firstRelease:  "v1.0.0",
+		secondRelease: "v2.0.0",
+		ignoreRegex:   "beta",
+		releases: []Release{
+			testRelease("v2.0.0", published.AddDate(0, 1, 0)),
+			testRelease("v1.0.0", published),
+		},
+		planReport: PlanReport{Fetched: 3, IgnoredByRegex: 1, Kept: 2, ResolvedFrom: "v1.0.0", ResolvedTo: "v2.0.0"},
+		analysis:   []AnalysisResult{testAnalysis("v2.0.0", 1200), testAnalysis("v1.0.0", 1000)},
+		notes:      map[string]string{"v2.0.0": "rewrite"},
+		unverified: map[string][]string{"v2.0.0": {"dist/index.js"}},
+	}
+	d.ignoreMode = IgnoreModeSubstring
+
+	content, err := EncodeBundle(d)
+	if err != nil {
+		t.Fatalf("EncodeBundle: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "comparison.nsc")
+	if err = os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := ReadBundle(path)
+	if err != nil {
+		t.Fatalf("ReadBundle: %v", err)
+	}
+	var imported data
+	imported.importBundle(bundle)
+
+	if imported.ghRepo != d.ghRepo || imported.firstRelease != d.firstRelease || imported.secondRelease != d.secondRelease {
+		t.Errorf(
+			"imported %s %s..%s, want %s %s..%s", imported.ghRepo, imported.firstRelease, imported.secondRelease,
+			d.ghRepo, d.firstRelease, d.secondRelease,
+		)
+	}
+	if imported.ignoreRegex != d.ignoreRegex || imported.ignoreMode != d.ignoreMode {
+		t.Errorf("imported ignore %q (%s), want %q (%s)", imported.ignoreRegex, imported.ignoreMode, d.ignoreRegex, d.ignoreMode)
+	}
+	for _, field := range []struct {
+		name      string
+		got, want any
+	}{
+		{"releases", imported.releases, d.releases},
+		{"plan report", imported.planReport, d.planReport},
+		{"analysis", imported.analysis, d.analysis},
+		{"notes", imported.notes, d.notes},
+		{"unverified", imported.unverified, d.unverified},
+	} {
+		if !reflect.DeepEqual(field.got, field.want) {
+			t.Errorf("imported %s\n%+v\nwant\n%+v", field.name, field.got, field.want)
+		}
+	}
+	if imported.imported == nil || !reflect.DeepEqual(*imported.imported, d.analysisSettings()) {
+		t.Errorf("imported settings %+v, want %+v", imported.imported, d.analysisSettings())
+	}
+}
+
+func TestReadBundleRejectsNewerVersion(t *testing.T) {
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gzWriter).Encode(Bundle{Version: bundleVersion + 1, AppVersion: "v99.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "newer.nsc")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBundle(path); err == nil || !strings.Contains(err.Error(), "v99.0.0") {
+		t.Errorf("ReadBundle of a newer bundle: %v, want an error naming the version that wrote it", err)
+	}
+}
+
+func TestReadBundleLargeFileSize(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		settings    string
+		size        int64
+		description string
+	}{
+		{"written before --large-file-size", `{"IgnoreRegex": "beta"}`, defaultLargeFileSize, "ignored releases: beta"},
+		{"default size", `{"LargeFileSize": 1000000}`, defaultLargeFileSize, "defaults"},
+		{"custom size", `{"LargeFileSize": 524288}`, 512 << 10, "large files: over 512.0 KiB"},
+		{"disabled", `{"LargeFileSize": 0}`, 0, "large files: not counted"},
+	} {
+		var buf bytes.Buffer
+		gzWriter := gzip.NewWriter(&buf)
+		if _, err := gzWriter.Write([]byte(`{"version": 1, "settings": ` + test.settings + `}`)); err != nil {
+			t.Fatal(err)
+		}
+		if err := gzWriter.Close(); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "comparison.nsc")
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		bundle, err := ReadBundle(path)
+		if err != nil {
+			t.Fatalf("%s: ReadBundle: %v", test.name, err)
+		}
+		if bundle.Settings.LargeFileSize != test.size {
+			t.Errorf("%s: large file size %d, want %d", test.name, bundle.Settings.LargeFileSize, test.size)
+		}
+		if description := bundle.Settings.String(); !strings.HasSuffix(description, ": "+test.description) {
+			t.Errorf("%s: described as %q, want it to end with %q", test.name, description, test.description)
+		}
+	}
+}
+
+func TestReadBundleRejectsPlainJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plain.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBundle(path); err == nil {
+		t.Error("ReadBundle of an uncompressed file succeeded")
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CachePolicy is how an extracted release is validated before being reused.
+type CachePolicy string
+
+const (
+	// CacheExists reuses an extraction as soon as its metadata file exists.
+	CacheExists CachePolicy = "exists"
+	// CacheShasum also requires the recorded shasum to match the current shasum
+	// of the registry, catching republished artifacts without downloading them.
+	CacheShasum CachePolicy = "shasum"
+	// CacheVerifyFiles also requires the extracted files to match the recorded ones,
+	// catching files modified or removed since the extraction.
+	CacheVerifyFiles CachePolicy = "verify-files"
+)
+
+// ParseCachePolicy parses the value of the `--cache-policy` flag.
+func ParseCachePolicy(value string) (CachePolicy, error) {
+	switch policy := CachePolicy(value); policy {
+	case CacheExists, CacheShasum, CacheVerifyFiles:
+		return policy, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid cache policy %q, expected %s, %s or %s", value, CacheExists, CacheShasum, CacheVerifyFiles,
+		)
+	}
+}
+
+// npmVersionURL returns the URL of the npm registry document of the version of a GitHub release.
+func npmVersionURL(release string) string {
+	name, version := npmPackageVersion(release)
+	return registryURL(escapeScope(name) + "/" + version)
+}
+
+// fetchRegistryShasum fetches the current shasum of the tarball of a GitHub release from the npm registry.
+func fetchRegistryShasum(ctx context.Context, release string) (string, error) {
+	request, err := newRegistryRequest(ctx, http.MethodGet, npmVersionURL(release))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Add("Accept", "application/json")
+
+	response, err := registryClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+	if response.StatusCode != http.StatusOK {
+		return "", registryStatusError("could not fetch the registry metadata of "+release, response)
+	}
+
+	var document struct {
+		Dist struct {
+			Shasum string `json:"shasum"`
+		} `json:"dist"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&document); err != nil {
+		return "", fmt.Errorf("invalid registry metadata of %s: %w", release, err)
+	}
+	if document.Dist.Shasum == "" {
+		return "", fmt.Errorf("no shasum in the registry metadata of %s", release)
+	}
+	return document.Dist.Shasum, nil
+}
+
+// listExtractedFiles returns the sizes of the files of an extracted release
+// by slash-separated path, without its metadata file.
+func listExtractedFiles(dir string) (map[string]int64, error) {
+	files := make(map[string]int64)
+	err := filepath.WalkDir(
+		dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if rel == metadataFileName {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(rel)] = info.Size()
+			return nil
+		},
+	)
+	return files, err
+}
+
+// validateCache returns whether the extraction of a release in dest can be reused
+// under the policy, and why. Failing to reach the registry falls back to the
+// metadata existence, so that cached releases remain usable offline.
+func validateCache(ctx context.Context, dest, release string, policy CachePolicy) (bool, string) {
+	if info, err := os.Stat(dest); err == nil && !info.IsDir() {
+		return false, "not a directory"
+	}
+	metadata, err := ReadExtractionMetadata(dest)
+	if err != nil {
+		return false, "no extraction metadata"
+	}
+	if reason := metadata.invalidFor(release); reason != "" {
+		return false, reason
+	}
+	if policy == CacheExists {
+		return true, string(CacheExists)
+	}
+
+	satisfied := string(policy)
+	shasum, err := fetchRegistryShasum(ctx, release)
+	switch {
+	case err != nil:
+		satisfied = fmt.Sprintf("%s without shasum, the registry shasum is unavailable: %v", policy, err)
+		if policy == CacheShasum {
+			return true, satisfied
+		}
+	case !strings.EqualFold(shasum, metadata.Shasum):
+		return false, fmt.Sprintf("shasum %s differs from the registry shasum %s", metadata.Shasum, shasum)
+	case policy == CacheShasum:
+		return true, satisfied
+	}
+
+	if metadata.Files == nil {
+		return false, "no file list in the extraction metadata"
+	}
+	files, err := listExtractedFiles(dest)
+	if err != nil {
+		return false, err.Error()
+	}
+	if len(files) != len(metadata.Files) {
+		return false, fmt.Sprintf("%d files extracted, %d recorded", len(files), len(metadata.Files))
+	}
+	for path, size := range metadata.Files {
+		if actual, ok := files[path]; !ok || actual != size {
+			return false, fmt.Sprintf("%s is missing or modified", path)
+		}
+	}
+	return true, satisfied
+}
+
+// moveFileAside renames a file found where the release must be extracted, such as one
+// left by a previous run or by the user, so that the release is extracted in its place
+// without deleting the file. It returns a warning describing the move, empty if there was no file.
+func moveFileAside(dest string) (string, error) {
+	info, err := os.Stat(dest)
+	if err != nil || info.IsDir() {
+		return "", nil
+	}
+	aside := fmt.Sprintf("%s.%s.aside", dest, time.Now().Format("20060102-150405"))
+	if err = os.Rename(dest, aside); err != nil {
+		return "", fmt.Errorf("%s is a file, not an extraction directory, and could not be moved aside: %w", dest, err)
+	}
+	return fmt.Sprintf("%s was a file, not an extraction directory, it was moved to %s", dest, aside), nil
+}
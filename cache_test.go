@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// registryShasum is the shasum of pkg@1.2.3 served by the fake registry of the cache tests.
+const registryShasum = "0123456789abcdef0123456789abcdef01234567"
+
+// cachedExtraction returns the directory of an extraction of pkg@1.2.3 with the metadata of testMetadata,
+// changed by the function if any.
+func cachedExtraction(t *testing.T, change func(dir string, metadata *ExtractionMetadata)) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "pkg@1.2.3")
+	for path, content := range map[string]string{
+		"package/index.js":     strings.Repeat("a", 100),
+		"package/package.json": strings.Repeat("b", 20),
+	} {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	metadata := testMetadata()
+	if change != nil {
+		change(dir, &metadata)
+	}
+	if err := WriteExtractionMetadata(dir, metadata); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestValidateCache(t *testing.T) {
+	registryUp := true
+	withFakeAPI(
+		t, http.HandlerFunc(
+			func(writer http.ResponseWriter, request *http.Request) {
+				if !registryUp || !strings.HasSuffix(request.URL.Path, "/pkg/1.2.3") {
+					http.Error(writer, "unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = writer.Write([]byte(`{"dist": {"shasum": "` + registryShasum + `"}}`))
+			},
+		),
+	)
+
+	for _, test := range []struct {
+		name    string
+		dir     func(t *testing.T) string
+		policy  CachePolicy
+		offline bool
+		valid   bool
+		reason  string // Prefix of the reason
+	}{
+		{
+			"missing", func(t *testing.T) string {
+				return filepath.Join(t.TempDir(), "pkg@1.2.3")
+			}, CacheExists, false, false, "no extraction metadata",
+		},
+		{
+			"file instead of a directory", func(t *testing.T) string {
+				path := filepath.Join(t.TempDir(), "pkg@1.2.3")
+				if err := os.WriteFile(path, []byte("left by a previous run"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			}, CacheExists, false, false, "not a directory",
+		},
+		{
+			"without metadata", func(t *testing.T) string {
+				dir := cachedExtraction(t, nil)
+				if err := os.Remove(filepath.Join(dir, metadataFileName)); err != nil {
+					t.Fatal(err)
+				}
+				return dir
+			}, CacheExists, false, false, "no extraction metadata",
+		},
+		{
+			"metadata of another release", func(t *testing.T) string {
+				return cachedExtraction(
+					t, func(_ string, metadata *ExtractionMetadata) {
+						metadata.Tag = "pkg@1.2.2"
+					},
+				)
+			}, CacheExists, false, false, "extraction metadata of pkg@1.2.2 instead",
+		},
+		{
+			"incomplete metadata", func(t *testing.T) string {
+				return cachedExtraction(
+					t, func(_ string, metadata *ExtractionMetadata) {
+						metadata.TarSize = 0
+					},
+				)
+			}, CacheExists, false, false, "no tarball size",
+		},
+		{"exists", func(t *testing.T) string { return cachedExtraction(t, nil) }, CacheExists, false, true, "exists"},
+		{"shasum", func(t *testing.T) string { return cachedExtraction(t, nil) }, CacheShasum, false, true, "shasum"},
+		{
+			"republished", func(t *testing.T) string {
+				return cachedExtraction(
+					t, func(_ string, metadata *ExtractionMetadata) {
+						metadata.Shasum = strings.Repeat("f", 40)
+					},
+				)
+			}, CacheShasum, false, false, "shasum ffff",
+		},
+		{
+			"shasum offline", func(t *testing.T) string { return cachedExtraction(t, nil) },
+			CacheShasum, true, true, "shasum without shasum",
+		},
+		{
+			"verified files", func(t *testing.T) string { return cachedExtraction(t, nil) },
+			CacheVerifyFiles, false, true, "verify-files",
+		},
+		{
+			"modified file", func(t *testing.T) string {
+				return cachedExtraction(
+					t, func(dir string, _ *ExtractionMetadata) {
+						if err := os.WriteFile(filepath.Join(dir, "package", "index.js"), []byte("changed"), 0644); err != nil {
+							t.Fatal(err)
+						}
+					},
+				)
+			}, CacheVerifyFiles, false, false, "package/index.js is missing or modified",
+		},
+		{
+			"removed file", func(t *testing.T) string {
+				return cachedExtraction(
+					t, func(dir string, _ *ExtractionMetadata) {
+						if err := os.Remove(filepath.Join(dir, "package", "package.json")); err != nil {
+							t.Fatal(err)
+						}
+					},
+				)
+			}, CacheVerifyFiles, false, false, "1 files extracted, 2 recorded",
+		},
+		{
+			"without file list", func(t *testing.T) string {
+				return cachedExtraction(
+					t, func(_ string, metadata *ExtractionMetadata) {
+						metadata.Files = nil
+					},
+				)
+			}, CacheVerifyFiles, false, false, "no file list",
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				registryUp = !test.offline
+				valid, reason := validateCache(context.Background(), test.dir(t), "pkg@1.2.3", test.policy)
+				if valid != test.valid || !strings.HasPrefix(reason, test.reason) {
+					t.Errorf("got %v (%s), expected %v (%s...)", valid, reason, test.valid, test.reason)
+				}
+			},
+		)
+	}
+}
+
+func TestMoveFileAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pkg@1.2.3")
+	if err := os.WriteFile(path, []byte("left by a previous run"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	warning, err := moveFileAside(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the file is still at %s", path)
+	}
+	aside, err := filepath.Glob(path + ".*.aside")
+	if err != nil || len(aside) != 1 {
+		t.Fatalf("the file wasn't moved aside: %v %v", aside, err)
+	}
+	if content, err := os.ReadFile(aside[0]); err != nil || string(content) != "left by a previous run" {
+		t.Errorf("the file moved aside has %q, %v", content, err)
+	}
+	if !strings.Contains(warning, aside[0]) {
+		t.Errorf("the warning %q doesn't tell where the file was moved", warning)
+	}
+
+	// Directories and missing paths are left as they are
+	for _, path := range []string{cachedExtraction(t, nil), filepath.Join(t.TempDir(), "missing")} {
+		if warning, err := moveFileAside(path); warning != "" || err != nil {
+			t.Errorf("moved %s aside: %q, %v", path, warning, err)
+		}
+	}
+}
+
+// TestDownloadOverFile checks that a file found where a release is extracted isn't mistaken
+// for a cached extraction: it is moved aside, and the release downloaded in its place.
+func TestDownloadOverFile(t *testing.T) {
+	tarball := packageTarball(t)
+	withFakeAPI(
+		t, http.HandlerFunc(
+			func(writer http.ResponseWriter, request *http.Request) {
+				if !strings.HasSuffix(request.URL.Path, ".tgz") {
+					http.NotFound(writer, request)
+					return
+				}
+				_, _ = writer.Write(tarball)
+			},
+		),
+	)
+	dest := filepath.Join(t.TempDir(), "pkg@1.0.0")
+	if err := os.WriteFile(dest, []byte("left by a previous run"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := DownloadGitHubRelease(context.Background(), "pkg@1.0.0", dest, "", CacheExists, defaultExtractLimits)()
+	downloaded, ok := msg.(gitReleaseDownloadedMsg)
+	if !ok {
+		t.Fatalf("unexpected message %#v", msg)
+	}
+	if downloaded.err != nil || downloaded.cached || downloaded.repaired {
+		t.Fatalf("got %+v, expected a fresh download", downloaded)
+	}
+	if !strings.Contains(downloaded.warning, "moved to") {
+		t.Errorf("got the warning %q, expected the file to be moved aside", downloaded.warning)
+	}
+	if valid, reason := validateCache(context.Background(), dest, "pkg@1.0.0", CacheVerifyFiles); !valid {
+		t.Errorf("the extraction can't be reused: %s", reason)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	day   = 24 * time.Hour
+	week  = 7 * day
+	month = 30 * day
+)
+
+// cadencePoint is a release of the range with its totals, for the cadence statistics.
+type cadencePoint struct {
+	releaseTag string
+	date       time.Time // Publication date of the release, zero if unknown
+	lines      uint
+	tarSize    uint64 // Size of the gzipped tarball in bytes, 0 if unknown
+}
+
+// CadenceStats are the statistics of the release cadence and size velocity over a range.
+type CadenceStats struct {
+	Releases        int     `json:"releases"`                     // Number of releases of the range
+	Dated           int     `json:"dated"`                        // Number of releases with a known date
+	AverageGapDays  float64 `json:"average_gap_days,omitempty"`   // Average days between consecutive dated releases
+	MedianGapDays   float64 `json:"median_gap_days,omitempty"`    // Median days between consecutive dated releases
+	LongestGapDays  float64 `json:"longest_gap_days,omitempty"`   // Longest days between consecutive dated releases
+	LongestGapFrom  string  `json:"longest_gap_from,omitempty"`   // Tag of the release opening the longest gap
+	LongestGapTo    string  `json:"longest_gap_to,omitempty"`     // Tag of the release closing the longest gap
+	LinesPerWeek    float64 `json:"lines_per_week,omitempty"`     // Lines added per week between the first and the last dated releases
+	GzBytesPerMonth float64 `json:"gz_bytes_per_month,omitempty"` // Gzipped bytes added per month between the first and the last sized releases
+
+	hasVelocity     bool // Whether the dated releases span a duration
+	hasSizeVelocity bool // Whether the dated releases with a known size span a duration
+}
+
+// cadenceStats computes the cadence statistics of releases ordered from the oldest
+// to the newest. Releases without a date are skipped for the gaps and the velocity,
+// and releases without a size for the size velocity. A single dated release, or
+// releases all published at once, have no gap nor velocity.
+func cadenceStats(points []cadencePoint) CadenceStats {
+	stats := CadenceStats{Releases: len(points)}
+
+	var dated []cadencePoint
+	for _, point := range points {
+		if !point.date.IsZero() {
+			dated = append(dated, point)
+		}
+	}
+	stats.Dated = len(dated)
+	if len(dated) < 2 {
+		return stats
+	}
+
+	// Gaps between consecutive releases
+	gaps := make([]float64, len(dated)-1)
+	var total float64
+	for i := 1; i < len(dated); i++ {
+		gap := dated[i].date.Sub(dated[i-1].date).Hours() / 24
+		if gap < 0 {
+			// Releases are ordered by version, which may not follow the dates
+			gap = -gap
+		}
+		gaps[i-1] = gap
+		total += gap
+		if gap > stats.LongestGapDays || stats.LongestGapFrom == "" {
+			stats.LongestGapDays = gap
+			stats.LongestGapFrom, stats.LongestGapTo = dated[i-1].releaseTag, dated[i].releaseTag
+		}
+	}
+	stats.AverageGapDays = total / float64(len(gaps))
+	slices.Sort(gaps)
+	if middle := len(gaps) / 2; len(gaps)%2 == 0 {
+		stats.MedianGapDays = (gaps[middle-1] + gaps[middle]) / 2
+	} else {
+		stats.MedianGapDays = gaps[middle]
+	}
+
+	// Velocity between the first and the last releases
+	first, last := dated[0], dated[len(dated)-1]
+	if span := last.date.Sub(first.date); span > 0 {
+		stats.hasVelocity = true
+		stats.LinesPerWeek = (float64(last.lines) - float64(first.lines)) / (float64(span) / float64(week))
+	}
+	var sized []cadencePoint
+	for _, point := range dated {
+		if point.tarSize > 0 {
+			sized = append(sized, point)
+		}
+	}
+	if len(sized) >= 2 {
+		first, last = sized[0], sized[len(sized)-1]
+		if span := last.date.Sub(first.date); span > 0 {
+			stats.hasSizeVelocity = true
+			stats.GzBytesPerMonth = (float64(last.tarSize) - float64(first.tarSize)) / (float64(span) / float64(month))
+		}
+	}
+
+	return stats
+}
+
+// String renders the cadence statistics on a single line.
+func (s CadenceStats) String() string {
+	if s.Dated < 2 {
+		if s.Releases < 2 {
+			return "Cadence: single release"
+		}
+		return "Cadence: unknown, not enough release dates"
+	}
+	parts := []string{
+		fmt.Sprintf(
+			"Cadence: every %.1f days on average, %.1f median, longest %.1f (%s → %s)",
+			s.AverageGapDays, s.MedianGapDays, s.LongestGapDays, s.LongestGapFrom, s.LongestGapTo,
+		),
+	}
+	if s.hasVelocity {
+		parts = append(parts, fmt.Sprintf("%+.0f lines/week", s.LinesPerWeek))
+	}
+	if s.hasSizeVelocity {
+		parts = append(parts, fmt.Sprintf("%s gz/month", formatBytesDiff(s.GzBytesPerMonth)))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// formatBytesDiff formats a signed number of bytes with a binary unit.
+func formatBytesDiff(bytes float64) string {
+	if bytes < 0 {
+		return "-" + formatBytes(math.Abs(bytes))
+	}
+	return "+" + formatBytes(bytes)
+}
+
+// formatBytes formats a number of bytes with a binary unit.
+func formatBytes(bytes float64) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", bytes/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", bytes/(1<<10))
+	default:
+		return fmt.Sprintf("%.0f B", bytes)
+	}
+}
+
+// cadencePoints returns the analyzed releases from the oldest to the newest,
+// with their publication dates. Releases not fetched from GitHub, like
+// the base release of a baseline, have no date. Failed analyses are skipped.
+func (d data) cadencePoints() []cadencePoint {
+	var points []cadencePoint
+	for _, dated := range d.datedAnalysis() {
+		if dated.analysis.failed != "" {
+			continue
+		}
+		points = append(
+			points, cadencePoint{
+				releaseTag: dated.analysis.releaseTag,
+				date:       dated.date,
+				lines:      dated.analysis.totalLines,
+				tarSize:    dated.analysis.tarSize,
+			},
+		)
+	}
+	return points
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCadenceStats(t *testing.T) {
+	date := func(day int) time.Time {
+		return time.Date(2024, time.January, day, 0, 0, 0, 0, time.UTC)
+	}
+	for _, test := range []struct {
+		name     string
+		points   []cadencePoint
+		stats    CadenceStats
+		rendered string
+	}{
+		{
+			"no release", nil,
+			CadenceStats{},
+			"Cadence: single release",
+		},
+		{
+			"one release", []cadencePoint{{"v1.0.0", date(1), 1000, 10000}},
+			CadenceStats{Releases: 1, Dated: 1},
+			"Cadence: single release",
+		},
+		{
+			"zero-time dates", []cadencePoint{
+				{"v1.0.0", time.Time{}, 1000, 10000},
+				{"v1.1.0", date(8), 1100, 11000},
+				{"v1.2.0", time.Time{}, 1200, 12000},
+			},
+			CadenceStats{Releases: 3, Dated: 1},
+			"Cadence: unknown, not enough release dates",
+		},
+		{
+			// The sizes are only known for some releases
+			"normal range", []cadencePoint{
+				{"v1.0.0", date(1), 1000, 10000},
+				{"v1.1.0", date(8), 1100, 0},
+				{"v1.2.0", time.Time{}, 5000, 50000},
+				{"v1.3.0", date(22), 1300, 12000},
+				{"v1.4.0", date(31), 1600, 13000},
+			},
+			CadenceStats{
+				Releases: 5, Dated: 4,
+				AverageGapDays: 10, MedianGapDays: 9,
+				LongestGapDays: 14, LongestGapFrom: "v1.1.0", LongestGapTo: "v1.3.0",
+				LinesPerWeek: 140, GzBytesPerMonth: 3000,
+				hasVelocity: true, hasSizeVelocity: true,
+			},
+			"Cadence: every 10.0 days on average, 9.0 median, longest 14.0 (v1.1.0 → v1.3.0) • +140 lines/week • +2.9 KiB gz/month",
+		},
+		{
+			// Ordered by version, a backport goes back in time
+			"backport", []cadencePoint{
+				{"v1.0.0", date(1), 1000, 0},
+				{"v2.0.0", date(11), 900, 0},
+				{"v1.0.1", date(15), 1010, 0},
+			},
+			CadenceStats{
+				Releases: 3, Dated: 3,
+				AverageGapDays: 7, MedianGapDays: 7,
+				LongestGapDays: 10, LongestGapFrom: "v1.0.0", LongestGapTo: "v2.0.0",
+				LinesPerWeek: 5,
+				hasVelocity:  true,
+			},
+			"Cadence: every 7.0 days on average, 7.0 median, longest 10.0 (v1.0.0 → v2.0.0) • +5 lines/week",
+		},
+		{
+			"published at once", []cadencePoint{
+				{"a@1.0.0", date(1), 1000, 10000},
+				{"b@1.0.0", date(1), 2000, 20000},
+			},
+			CadenceStats{Releases: 2, Dated: 2, LongestGapFrom: "a@1.0.0", LongestGapTo: "b@1.0.0"},
+			"Cadence: every 0.0 days on average, 0.0 median, longest 0.0 (a@1.0.0 → b@1.0.0)",
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				stats := cadenceStats(test.points)
+				// The velocities are divided by fractional weeks and months
+				for _, velocity := range []struct {
+					got, expected *float64
+				}{{&stats.LinesPerWeek, &test.stats.LinesPerWeek}, {&stats.GzBytesPerMonth, &test.stats.GzBytesPerMonth}} {
+					if math.Abs(*velocity.got-*velocity.expected) < 1e-9 {
+						*velocity.got = *velocity.expected
+					}
+				}
+				if stats != test.stats {
+					t.Errorf("got %+v, expected %+v", stats, test.stats)
+				}
+				if rendered := stats.String(); rendered != test.rendered {
+					t.Errorf("rendered %q, expected %q", rendered, test.rendered)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"io"
+	"net/http"
+)
+
+// faultInjector injects failures in the download and analysis paths.
+// It is only meant to exercise the handling of partial failures during development.
+type faultInjector interface {
+	// response may replace an HTTP response by a server error.
+	response(response *http.Response) *http.Response
+	// tarball may truncate the stream of a tarball.
+	tarball(reader io.Reader) io.Reader
+	// walk may return an error for a path walked during an analysis.
+	walk(path string) error
+}
+
+// noFaults is the fault injector that never injects anything.
+type noFaults struct{}
+
+func (noFaults) response(response *http.Response) *http.Response { return response }
+func (noFaults) tarball(reader io.Reader) io.Reader              { return reader }
+func (noFaults) walk(string) error                               { return nil }
+
+// faults is the fault injector in use, injecting nothing unless set up otherwise.
+var faults faultInjector = noFaults{}
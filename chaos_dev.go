@@ -0,0 +1,98 @@
+//go:build chaos
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Development-only flags, hidden from the usage and only compiled in with the chaos build tag.
+var (
+	chaos     = flag.Float64("chaos", 0, "DEVELOPMENT ONLY: probability of injecting each failure in the download and analysis paths")
+	chaosSeed = flag.Int64("chaos-seed", 1, "DEVELOPMENT ONLY: seed of the --chaos failures")
+)
+
+func init() {
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		visible := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+		visible.SetOutput(flag.CommandLine.Output())
+		flag.VisitAll(
+			func(f *flag.Flag) {
+				if !strings.HasPrefix(f.Name, "chaos") {
+					visible.Var(f.Value, f.Name, f.Usage)
+				}
+			},
+		)
+		visible.PrintDefaults()
+	}
+}
+
+// setupFaults injects random failures if the `--chaos` flag is set.
+func setupFaults() error {
+	if *chaos == 0 {
+		return nil
+	}
+	if *chaos < 0 || *chaos > 1 {
+		return fmt.Errorf("invalid --chaos %g, expected a probability between 0 and 1", *chaos)
+	}
+	faults = &randomFaults{
+		probability: *chaos,
+		random:      rand.New(rand.NewSource(*chaosSeed)),
+	}
+	return nil
+}
+
+// randomFaults injects each failure with a given probability,
+// drawn from a seeded random source so that runs can be replayed.
+type randomFaults struct {
+	probability float64
+	mu          sync.Mutex
+	random      *rand.Rand
+}
+
+// roll returns whether a failure is injected, and a random number below n
+// to shape it if n is positive.
+func (f *randomFaults) roll(n int64) (bool, int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.random.Float64() >= f.probability {
+		return false, 0
+	}
+	if n <= 0 {
+		return true, 0
+	}
+	return true, f.random.Int63n(n)
+}
+
+func (f *randomFaults) response(response *http.Response) *http.Response {
+	if fail, _ := f.roll(0); !fail {
+		return response
+	}
+	injected := *response
+	injected.StatusCode = http.StatusInternalServerError
+	injected.Status = "500 Internal Server Error (chaos)"
+	return &injected
+}
+
+func (f *randomFaults) tarball(reader io.Reader) io.Reader {
+	fail, limit := f.roll(64 * 1024)
+	if !fail {
+		return reader
+	}
+	return io.LimitReader(reader, limit)
+}
+
+func (f *randomFaults) walk(path string) error {
+	if fail, _ := f.roll(0); fail {
+		return fmt.Errorf("injected walk error on %s (chaos)", path)
+	}
+	return nil
+}
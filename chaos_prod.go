@@ -0,0 +1,8 @@
+//go:build !chaos
+
+package main
+
+// setupFaults doesn't inject any failure, as fault injection is only compiled in with the chaos build tag.
+func setupFaults() error {
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// chartSeries is a series of values plotted on the chart,
+// extracted from each analysis result.
+type chartSeries struct {
+	name    string
+	style   lipgloss.Style
+	extract func(AnalysisResult) float64
+}
+
+// chartSeriesList is the list of series plotted on the chart.
+var chartSeriesList = []chartSeries{
+	{
+		name:  "lines",
+		style: svelteText,
+		extract: func(a AnalysisResult) float64 {
+			return float64(a.totalLines)
+		},
+	},
+	{
+		name:  "files",
+		style: successStyle,
+		extract: func(a AnalysisResult) float64 {
+			return float64(a.totalFiles)
+		},
+	},
+}
+
+// normalizedSeries wraps a series so that its values are the percentage
+// of change relative to the value of the base result.
+func normalizedSeries(series chartSeries, base AnalysisResult) chartSeries {
+	baseValue := series.extract(base)
+	return chartSeries{
+		name:  series.name,
+		style: series.style,
+		extract: func(a AnalysisResult) float64 {
+			if baseValue == 0 {
+				return 0
+			}
+			return (series.extract(a) - baseValue) / baseValue * 100
+		},
+	}
+}
+
+// RenderChart renders the series for the results, ordered from the oldest
+// to the newest, within the given size, the marked releases being pointed at
+// below the chart. When normalized, the values are rendered as the percentage
+// of change relative to the first result.
+func RenderChart(results []AnalysisResult, series []chartSeries, marked []string, normalized bool, width, height int) string {
+	if len(results) == 0 || len(series) == 0 {
+		return ""
+	}
+	if normalized {
+		normalizedList := make([]chartSeries, len(series))
+		for i, s := range series {
+			normalizedList[i] = normalizedSeries(s, results[0])
+		}
+		series = normalizedList
+	}
+	formatValue := func(value float64) string {
+		if normalized {
+			return fmt.Sprintf("%+.1f%%", value)
+		}
+		return fmt.Sprintf("%.0f", value)
+	}
+
+	// Compute the bounds
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	if normalized {
+		minValue, maxValue = 0, 0 // Always show the zero line
+	}
+	for _, s := range series {
+		for _, result := range results {
+			value := s.extract(result)
+			minValue = math.Min(minValue, value)
+			maxValue = math.Max(maxValue, value)
+		}
+	}
+	if minValue == maxValue {
+		maxValue = minValue + 1
+	}
+
+	// Compute the layout
+	labelWidth := len(formatValue(minValue))
+	if maxLabelWidth := len(formatValue(maxValue)); maxLabelWidth > labelWidth {
+		labelWidth = maxLabelWidth
+	}
+	legendHeight := 2
+	if len(marked) > 0 {
+		legendHeight++ // The marks
+	}
+	rows := height - legendHeight
+	if rows < 3 {
+		rows = 3
+	}
+	columnWidth := (width - labelWidth - 2) / len(results)
+	if columnWidth < 1 {
+		columnWidth = 1
+	}
+	rowOf := func(value float64) int {
+		return int(math.Round((maxValue - value) / (maxValue - minValue) * float64(rows-1)))
+	}
+
+	// Plot the points
+	grid := make([][]string, rows)
+	for y := range grid {
+		grid[y] = make([]string, len(results))
+		for x := range grid[y] {
+			grid[y][x] = strings.Repeat(" ", columnWidth)
+		}
+	}
+	zeroRow := -1
+	if normalized {
+		zeroRow = rowOf(0)
+		for x := range grid[zeroRow] {
+			grid[zeroRow][x] = blurredStyle.Render(strings.Repeat("─", columnWidth))
+		}
+	}
+	for _, s := range series {
+		for x, result := range results {
+			row := rowOf(s.extract(result))
+			padding := strings.Repeat(" ", columnWidth-1)
+			if row == zeroRow {
+				padding = blurredStyle.Render(strings.Repeat("─", columnWidth-1))
+			}
+			grid[row][x] = s.style.Render("●") + padding
+		}
+	}
+
+	// Render the chart
+	var sb strings.Builder
+	for y, row := range grid {
+		label := ""
+		switch y {
+		case 0:
+			label = formatValue(maxValue)
+		case rows - 1:
+			label = formatValue(minValue)
+		}
+		sb.WriteString(blurredStyle.Render(fmt.Sprintf("%*s │", labelWidth, label)))
+		sb.WriteString(strings.Join(row, ""))
+		sb.WriteRune('\n')
+	}
+
+	// Mark the releases
+	if len(marked) > 0 {
+		sb.WriteString(strings.Repeat(" ", labelWidth+2))
+		for _, result := range results {
+			if slices.Contains(marked, result.releaseTag) {
+				sb.WriteString(warningStyle.Render("▲") + strings.Repeat(" ", columnWidth-1))
+			} else {
+				sb.WriteString(strings.Repeat(" ", columnWidth))
+			}
+		}
+		sb.WriteRune('\n')
+	}
+
+	// Render the legend
+	sb.WriteString(strings.Repeat(" ", labelWidth+2))
+	sb.WriteString(
+		blurredStyle.Render(
+			fmt.Sprintf("%s → %s", results[0].releaseTag, results[len(results)-1].releaseTag),
+		),
+	)
+	sb.WriteRune('\n')
+	sb.WriteString(strings.Repeat(" ", labelWidth+2))
+	for i, s := range series {
+		if i > 0 {
+			sb.WriteString("  ")
+		}
+		sb.WriteString(s.style.Render("● " + s.name))
+	}
+	if len(marked) > 0 {
+		sb.WriteString("  " + warningStyle.Render("▲ biggest jumps"))
+	}
+
+	return sb.String()
+}
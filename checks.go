@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checkedEndpoint is an endpoint of the comparison whose existence was checked.
+type checkedEndpoint struct {
+	name string // Name of the endpoint, with its flag
+	tag  string
+}
+
+// checkedEndpoints returns the endpoints whose existence is checked, in the order of the flags.
+func (d data) checkedEndpoints() []checkedEndpoint {
+	return []checkedEndpoint{
+		{"base release (--from)", d.firstRelease},
+		{"release to compare to (--to)", d.secondRelease},
+	}
+}
+
+// handleReleaseCheck records the existence check of an endpoint. Once both endpoints reported,
+// the pipeline goes on if both exist, or fails naming every endpoint that is missing or whose check failed.
+func (m model) handleReleaseCheck(msg gitReleaseExistsMsg) (model, tea.Cmd) {
+	if m.releaseChecks == nil {
+		return m, nil
+	}
+	m.releaseChecks[msg.release] = msg
+	endpoints := m.data.checkedEndpoints()
+	for _, endpoint := range endpoints {
+		if _, ok := m.releaseChecks[endpoint.tag]; !ok {
+			return m, nil
+		}
+	}
+
+	var missing, failed []string
+	for _, endpoint := range endpoints {
+		check := m.releaseChecks[endpoint.tag]
+		switch {
+		case check.err != nil:
+			failed = append(failed, fmt.Sprintf("the %s %s could not be checked: %v", endpoint.name, endpoint.tag, check.err))
+		case !check.exists:
+			missing = append(missing, fmt.Sprintf("the %s %s", endpoint.name, endpoint.tag))
+		}
+	}
+	if len(missing) == 0 && len(failed) == 0 {
+		return m.advance(eventChecked)
+	}
+	var problems []string
+	if len(missing) > 0 {
+		verb := "does not exist"
+		if len(missing) > 1 {
+			verb = "do not exist"
+		}
+		problems = append(
+			problems, fmt.Sprintf(
+				"%s %s, check that you input existing GitHub tags (check at https://github.com/%s/tags)",
+				strings.Join(missing, " and "), verb, m.data.ghRepo,
+			),
+		)
+	}
+	m.fail(errors.New(strings.Join(append(problems, failed...), "; ")))
+	return m, nil
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// plainOutput renders the styles of the application as plain text, without any escape sequence,
+// for `--no-color` runs and the headless mode, whose output is read as text such as in CI logs,
+// whatever the colors the terminal supports. Without it, the colors already follow the terminal
+// and the `NO_COLOR` environment variable.
+// The exports never depend on it: they are written from the analysis results with plain helpers
+// such as diffText, the styles being applied by the summary only.
+func plainOutput() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
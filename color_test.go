@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// colorfulProfile renders the styles with every color for the duration of the test, like a colorful terminal.
+func colorfulProfile(t *testing.T) {
+	t.Helper()
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(
+		func() {
+			lipgloss.SetColorProfile(profile)
+		},
+	)
+}
+
+func TestExportsHaveNoEscapeSequences(t *testing.T) {
+	colorfulProfile(t)
+	if !strings.Contains(textForDiff(measured(12)), "\x1b") {
+		t.Fatal("the summary isn't styled, the profile is not colorful")
+	}
+
+	failed := newAnalysisResult("v1.1.0")
+	failed.failed = "timeout"
+	d := data{
+		ghRepo:        "owner/repo",
+		firstRelease:  "v1.0.0",
+		secondRelease: "v2.0.0",
+		analysis:      []AnalysisResult{testAnalysis("v2.0.0", 1200), failed, testAnalysis("v1.0.0", 1000)},
+		notes:         map[string]string{"v2.0.0": "rewrite"},
+		warnings:      []string{"a warning"},
+	}
+	d.spikeCount = defaultSpikes
+	d.planReport.ResolvedTo = "v2.0.0"
+	chronological := []AnalysisResult{d.analysis[2], d.analysis[1], d.analysis[0]}
+
+	jsonExport, err := EncodeJSONExport(d, ExportChronological, chronological)
+	if err != nil {
+		t.Fatalf("EncodeJSONExport: %v", err)
+	}
+	csvExport, err := EncodeCSVExport(d, ExportChronological, chronological)
+	if err != nil {
+		t.Fatalf("EncodeCSVExport: %v", err)
+	}
+	m := model{data: d}
+	exports := map[string]string{
+		"JSON":         string(jsonExport),
+		"CSV":          string(csvExport),
+		"Markdown":     RenderMarkdownReport(d, chronological),
+		"notification": m.completionMessage(),
+	}
+	for _, state := range []State{StateChecking, StateFetching, StateDownloadExtract, StateAnalyzing, StateSummary} {
+		m.state = state
+		exports["headless "+m.headlessStatus()] = m.headlessStatus()
+	}
+	for name, export := range exports {
+		if i := strings.IndexByte(export, '\x1b'); i >= 0 {
+			t.Errorf("the %s export has an escape sequence at %d:\n%q", name, i, export)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// preferencesFileName is the name of the file of the UI preferences in the application directory.
+const preferencesFileName = "preferences.json"
+
+// descriptionColumn is a segment of the description of the summary list items.
+type descriptionColumn struct {
+	name   string // Name of the column in the preferences and the exports
+	label  string // Label of the column in the column chooser
+	render func(l ListItem) string
+}
+
+// descriptionColumns are the available segments of the description
+// of the summary list items, in display order. Renderers return
+// an empty string when the release has nothing to show.
+var descriptionColumns = []descriptionColumn{
+	{"size", "Files, lines and unpacked size", func(l ListItem) string {
+		segments := []string{fmt.Sprintf("%d files", l.totalFiles), l.linesText()}
+		if size := l.measuredDirSize(); size.measured {
+			segments = append(segments, ByteCountSI(size.value)+" unpacked")
+		}
+		if generated := l.generatedText(); generated != "" {
+			segments = append(segments, generated)
+		}
+		return strings.Join(segments, " • ")
+	}},
+	{"languages", "Top languages", ListItem.languages},
+	{"modules", "Module systems", ListItem.modules},
+	{"density", "Lines per unpacked kB", ListItem.densityText},
+	{"runtime", "Engines and package manager", ListItem.runtimeText},
+	{"tarball", "Gzipped tarball size", func(l ListItem) string {
+		size := l.measuredTarSize()
+		if !size.measured {
+			return ""
+		}
+		return formatBytes(float64(size.value)) + " gz"
+	}},
+	{"endings", "Line endings", ListItem.lineEndingsText},
+	{"large", "Large files", ListItem.largeFilesText},
+	{"provenance", "Provenance", ListItem.provenanceText},
+	{"approximation", "Approximation", ListItem.approximation},
+	{"reactions", "Reactions", func(l ListItem) string {
+		if count := l.reactionsCount(); count > 0 {
+			return fmt.Sprintf("%d reactions", count)
+		}
+		return ""
+	}},
+	{"excluded", "Excluded files", func(l ListItem) string {
+		if l.excludedFiles == 0 {
+			return ""
+		}
+		return fmt.Sprintf("excluded: %d files (%d lines)", l.excludedFiles, l.excludedLines)
+	}},
+	{"note", "Note", func(l ListItem) string {
+		if l.note == "" {
+			return ""
+		}
+		return "✎ " + l.note
+	}},
+	{"unverified", "Files absent from the source", func(l ListItem) string {
+		if len(l.unverified) == 0 {
+			return ""
+		}
+		return "absent from source: " + strings.Join(l.unverified, ", ")
+	}},
+}
+
+// defaultColumns returns the names of all the description columns, shown by default.
+func defaultColumns() []string {
+	names := make([]string, len(descriptionColumns))
+	for i, column := range descriptionColumns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// languageLines is the number of lines of a language.
+type languageLines struct {
+	language string
+	lines    uint
+}
+
+// topLanguages returns the visible languages with the most lines, from the most to the least,
+// followed by the other ones grouped under a name describing them, if any.
+func topLanguages(linesByLanguage map[string]uint, visible int, others func(count int) string) []languageLines {
+	sorted := make([]languageLines, 0, len(linesByLanguage))
+	for language, lines := range linesByLanguage {
+		sorted = append(sorted, languageLines{language, lines})
+	}
+	slices.SortFunc(
+		sorted, func(a, b languageLines) int {
+			if c := cmp.Compare(b.lines, a.lines); c != 0 {
+				return c
+			}
+			return strings.Compare(a.language, b.language)
+		},
+	)
+	if len(sorted) <= visible {
+		return sorted
+	}
+	other := languageLines{language: others(len(sorted) - visible)}
+	for _, language := range sorted[visible:] {
+		other.lines += language.lines
+	}
+	return append(sorted[:visible], other)
+}
+
+// languages renders the lines of the top languages of the release,
+// the other languages being grouped together.
+func (l ListItem) languages() string {
+	// Shorten to 2 languages and concat all the others into the "Other" category
+	others := func(count int) string {
+		return fmt.Sprintf("and %d more", count)
+	}
+	sorted := topLanguages(l.linesByLanguage, 2, others)
+	parts := make([]string, len(sorted))
+	for i, lang := range sorted {
+		parts[i] = fmt.Sprintf("%s (%d lines)", lang.language, lang.lines)
+	}
+	return strings.Join(parts, " / ")
+}
+
+// UIPreferences are the preferences of the user interface, persisted across runs.
+type UIPreferences struct {
+	Columns []string            `json:"columns"`        // Names of the description columns shown in the summary
+	Pins    map[string][]string `json:"pins,omitempty"` // Tags of the pinned releases by repository
+}
+
+// preferencesSavedMsg is a message that carries the result of saving the UI preferences.
+type preferencesSavedMsg struct {
+	err error
+}
+
+// preferencesPath returns the path of the UI preferences file.
+func preferencesPath() (string, error) {
+	dir, err := userDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, preferencesFileName), nil
+}
+
+// LoadPreferences loads the UI preferences. A missing preferences file
+// means the defaults are used, and unknown columns are dropped.
+func LoadPreferences() (UIPreferences, error) {
+	preferences := UIPreferences{Columns: defaultColumns()}
+	path, err := preferencesPath()
+	if err != nil {
+		return preferences, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return preferences, nil
+	} else if err != nil {
+		return preferences, err
+	}
+	var stored UIPreferences
+	if err = json.Unmarshal(content, &stored); err != nil {
+		return preferences, fmt.Errorf("invalid preferences %s: %w", path, err)
+	}
+	if stored.Columns != nil {
+		preferences.Columns = slices.DeleteFunc(
+			stored.Columns, func(name string) bool {
+				return !slices.ContainsFunc(
+					descriptionColumns, func(column descriptionColumn) bool {
+						return column.name == name
+					},
+				)
+			},
+		)
+	}
+	return preferences, nil
+}
+
+// SavePreferences atomically writes the UI preferences.
+func SavePreferences(preferences UIPreferences) tea.Cmd {
+	return func() tea.Msg {
+		path, err := preferencesPath()
+		if err != nil {
+			return preferencesSavedMsg{err}
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return preferencesSavedMsg{err}
+		}
+		content, err := json.MarshalIndent(preferences, "", "  ")
+		if err != nil {
+			return preferencesSavedMsg{err}
+		}
+		return preferencesSavedMsg{writeFileAtomically(path, content)}
+	}
+}
+
+// columnChooser is the overlay choosing the description columns of the summary list.
+type columnChooser struct {
+	cursor int // Index of the highlighted column
+}
+
+// toggleColumn shows the named column if it is hidden, or hides it, keeping the display order.
+func toggleColumn(columns []string, name string) []string {
+	if slices.Contains(columns, name) {
+		return slices.DeleteFunc(
+			slices.Clone(columns), func(column string) bool {
+				return column == name
+			},
+		)
+	}
+	var toggled []string
+	for _, column := range descriptionColumns {
+		if column.name == name || slices.Contains(columns, column.name) {
+			toggled = append(toggled, column.name)
+		}
+	}
+	return toggled
+}
+
+// view renders the column chooser, with the shown columns checked.
+func (c columnChooser) view(columns []string) string {
+	rows := make([]string, len(descriptionColumns))
+	for i, column := range descriptionColumns {
+		check := "[ ]"
+		if slices.Contains(columns, column.name) {
+			check = "[x]"
+		}
+		row := fmt.Sprintf("%s %s", check, column.label)
+		if i == c.cursor {
+			row = svelteText.Render("> " + row)
+		} else {
+			row = "  " + row
+		}
+		rows[i] = row
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		svelteBg.Padding(0, 1).Render("Description columns"),
+		"",
+		strings.Join(rows, "\n"),
+		"",
+		blurredStyle.Render("↑/↓ move • space toggle • esc close"),
+	)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// csvSchemaVersion is the version of the columns of the CSV export,
+// incremented on every breaking change.
+const csvSchemaVersion = 2
+
+// EncodeCSVExport encodes leading comment rows with the description of the analysis settings and the order,
+// starting with `#` to be skipped by the readers supporting comments, then a row per release,
+// in the given order: its tag, publication date, total files and lines, lines of each language
+// (the languages of all the releases, sorted alphabetically, 0 when absent),
+// tarball size, differences of total lines and of code lines from the previous release in chronological order
+// and the note of the user, then the versions of the schema and of the application that wrote the file,
+// last so that readers indexing the columns aren't affected.
+// Metrics that were not measured are empty cells.
+func EncodeCSVExport(d data, order ExportOrder, releases []AnalysisResult) ([]byte, error) {
+	chronological := d.chronological()
+	previous := make(map[string]AnalysisResult, len(chronological))
+	for i := 1; i < len(chronological); i++ {
+		previous[chronological[i].releaseTag] = chronological[i-1]
+	}
+
+	var languages []string
+	for _, release := range releases {
+		for language := range release.linesByLanguage {
+			if !slices.Contains(languages, language) {
+				languages = append(languages, language)
+			}
+		}
+	}
+	slices.Sort(languages)
+
+	header := []string{"tag", "published_at", "total_files", "total_lines"}
+	for _, language := range languages {
+		header = append(header, language+" lines")
+	}
+	header = append(header, "tar_size", "total_lines_delta", "code_lines_delta", "note", "schema_version", "tool_version")
+	rows := [][]string{header}
+	for _, release := range d.withDates(releases) {
+		analysis := release.analysis
+		published := ""
+		if !release.date.IsZero() {
+			published = release.date.UTC().Format(time.RFC3339)
+		}
+		row := []string{
+			analysis.releaseTag,
+			published,
+			csvCell(analysis.measuredFiles()),
+			csvCell(analysis.measuredLines()),
+		}
+		for _, language := range languages {
+			if analysis.failed != "" {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, strconv.FormatUint(uint64(analysis.linesByLanguage[language]), 10))
+		}
+		linesDelta, codeDelta := measure[int]{}, measure[int]{}
+		if previous, ok := previous[analysis.releaseTag]; ok {
+			linesDelta = analysis.measuredLines().since(previous.measuredLines())
+			codeDelta = analysis.measuredCodeLines().since(previous.measuredCodeLines())
+		}
+		row = append(
+			row,
+			csvCell(analysis.measuredTarSize()),
+			csvCell(linesDelta),
+			csvCell(codeDelta),
+			d.notes[analysis.releaseTag],
+			strconv.Itoa(csvSchemaVersion),
+			appVersion,
+		)
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# " + d.settingsLine() + "\n")
+	buf.WriteString("# order: " + string(order) + "\n")
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvCell renders a measure as a CSV cell, empty if it was not measured.
+func csvCell[T int | int64 | float64](value measure[T]) string {
+	if !value.measured {
+		return ""
+	}
+	return fmt.Sprint(value.value)
+}
+
+// WriteCSVExport writes the CSV export of the releases, in the given order, to the path,
+// reporting a failure as an error of the comparison.
+func WriteCSVExport(path string, d data, order ExportOrder, releases []AnalysisResult) tea.Cmd {
+	return func() tea.Msg {
+		content, err := EncodeCSVExport(d, order, releases)
+		if err == nil {
+			err = os.WriteFile(path, content, 0644)
+		}
+		if err != nil {
+			return errMsg(fmt.Errorf("could not write the CSV export: %w", err))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// density returns the number of lines per unpacked kilobyte of the release,
+// and false if its size is unknown. Minified bundles are much denser in bytes
+// than source files, so a shift of density hints at a packaging change.
+func (a AnalysisResult) density() (float64, bool) {
+	if a.totalDirSize <= 0 {
+		return 0, false
+	}
+	return float64(a.totalLines) / (float64(a.totalDirSize) / 1000), true
+}
+
+// densityChange returns the relative change of density from the previous release,
+// and false if either density is unknown or the previous density is 0.
+func densityChange(previous, current AnalysisResult) (float64, bool) {
+	before, ok := previous.density()
+	if !ok || before == 0 {
+		return 0, false
+	}
+	after, ok := current.density()
+	if !ok {
+		return 0, false
+	}
+	return (after - before) / before, true
+}
+
+// packagingChangeSuspected returns whether the density changed by more than
+// the threshold ratio from the previous release. A threshold of 0 disables it.
+func packagingChangeSuspected(previous, current AnalysisResult, threshold float64) bool {
+	change, ok := densityChange(previous, current)
+	return ok && threshold > 0 && math.Abs(change) > threshold
+}
+
+// densityText renders the density of the release, with its change from the previous release.
+func (l ListItem) densityText() string {
+	density := l.measuredDensity()
+	if !density.measured {
+		return ""
+	}
+	text := density.format("%.1f lines/kB")
+	if l.previous != nil && l.previous.failed == "" {
+		if change, ok := densityChange(l.previous.AnalysisResult, l.AnalysisResult); ok {
+			text += fmt.Sprintf(" (%+.1f%%)", change*100)
+		}
+	}
+	return text
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxGraphChanges is the number of dependency changes above which the edges
+// of the dependency graph only show the number of changes of each kind.
+const maxGraphChanges = 100
+
+// DependencyChangeKind is how a dependency changed between two releases.
+type DependencyChangeKind string
+
+const (
+	// DependencyAdded means the dependency was added.
+	DependencyAdded DependencyChangeKind = "added"
+	// DependencyRemoved means the dependency was removed.
+	DependencyRemoved DependencyChangeKind = "removed"
+	// DependencyBumped means the version range of the dependency changed.
+	DependencyBumped DependencyChangeKind = "bumped"
+)
+
+// dependencyChange is a change of a dependency between two releases.
+type dependencyChange struct {
+	name     string
+	kind     DependencyChangeKind
+	from, to string // Version ranges before and after the change, empty if absent
+}
+
+// String renders the change on a single line, e.g. `↑ svelte ^4.0.0 → ^5.0.0`.
+func (c dependencyChange) String() string {
+	switch c.kind {
+	case DependencyAdded:
+		return fmt.Sprintf("+ %s %s", c.name, c.to)
+	case DependencyRemoved:
+		return fmt.Sprintf("- %s %s", c.name, c.from)
+	default:
+		return fmt.Sprintf("↑ %s %s → %s", c.name, c.from, c.to)
+	}
+}
+
+// dependencyChanges returns the changes between two sets of dependencies, sorted by name.
+func dependencyChanges(before, after map[string]string) []dependencyChange {
+	var changes []dependencyChange
+	for name, to := range after {
+		if from, ok := before[name]; !ok {
+			changes = append(changes, dependencyChange{name: name, kind: DependencyAdded, to: to})
+		} else if from != to {
+			changes = append(changes, dependencyChange{name: name, kind: DependencyBumped, from: from, to: to})
+		}
+	}
+	for name, from := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, dependencyChange{name: name, kind: DependencyRemoved, from: from})
+		}
+	}
+	slices.SortFunc(
+		changes, func(a, b dependencyChange) int {
+			return strings.Compare(a.name, b.name)
+		},
+	)
+	return changes
+}
+
+// dependencyStep is the dependency changes from a release to the next one.
+type dependencyStep struct {
+	from, to string // Tags of the releases
+	changes  []dependencyChange
+	measured bool // Whether both releases were analyzed, their analysis not having failed
+}
+
+// dependencySteps returns the dependency changes between consecutive releases,
+// ordered from the oldest to the newest. The changes of a step with a failed analysis
+// are not measured, as its dependencies are unknown.
+func dependencySteps(releases []AnalysisResult) []dependencyStep {
+	var steps []dependencyStep
+	for i := 1; i < len(releases); i++ {
+		step := dependencyStep{
+			from:     releases[i-1].releaseTag,
+			to:       releases[i].releaseTag,
+			measured: releases[i-1].failed == "" && releases[i].failed == "",
+		}
+		if step.measured {
+			step.changes = dependencyChanges(releases[i-1].dependencies, releases[i].dependencies)
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// graphEdgeLabels returns the label lines of the edge of each step: one line
+// per change, or the number of changes of each kind when there are more than
+// maxGraphChanges changes in total, so that the graph stays readable.
+func graphEdgeLabels(steps []dependencyStep) [][]string {
+	total := 0
+	for _, step := range steps {
+		total += len(step.changes)
+	}
+	labels := make([][]string, len(steps))
+	for i, step := range steps {
+		if !step.measured {
+			labels[i] = []string{notMeasuredText}
+			continue
+		}
+		if total <= maxGraphChanges {
+			for _, change := range step.changes {
+				labels[i] = append(labels[i], change.String())
+			}
+			continue
+		}
+		counts := make(map[DependencyChangeKind]int)
+		for _, change := range step.changes {
+			counts[change.kind]++
+		}
+		for _, kind := range []DependencyChangeKind{DependencyAdded, DependencyRemoved, DependencyBumped} {
+			if counts[kind] > 0 {
+				labels[i] = append(labels[i], fmt.Sprintf("%d %s", counts[kind], kind))
+			}
+		}
+	}
+	return labels
+}
+
+// RenderMermaidGraph renders the dependency changes across the releases,
+// ordered from the oldest to the newest, as a Mermaid flowchart: a node
+// per release, and an edge per step labeled with its changes.
+func RenderMermaidGraph(releases []AnalysisResult) string {
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, `"`, "#quot;")
+	}
+	var sb strings.Builder
+	sb.WriteString("%% Generated by " + appDirName + " " + appVersion + "\n")
+	sb.WriteString("graph LR\n")
+	for i, release := range releases {
+		sb.WriteString(fmt.Sprintf("  r%d[\"%s\"]\n", i, escape(release.releaseTag)))
+	}
+	steps := dependencySteps(releases)
+	for i, lines := range graphEdgeLabels(steps) {
+		if len(lines) == 0 {
+			sb.WriteString(fmt.Sprintf("  r%d --> r%d\n", i, i+1))
+			continue
+		}
+		for j, line := range lines {
+			lines[j] = escape(line)
+		}
+		sb.WriteString(fmt.Sprintf("  r%d -->|\"%s\"| r%d\n", i, strings.Join(lines, "<br/>"), i+1))
+	}
+	return sb.String()
+}
+
+// RenderDOTGraph renders the dependency changes across the releases,
+// ordered from the oldest to the newest, as a Graphviz DOT digraph: a node
+// per release, and an edge per step labeled with its changes.
+func RenderDOTGraph(releases []AnalysisResult) string {
+	escape := func(s string) string {
+		return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+	}
+	var sb strings.Builder
+	sb.WriteString("// Generated by " + appDirName + " " + appVersion + "\n")
+	sb.WriteString("digraph dependencies {\n  rankdir=LR;\n  node [shape=box];\n")
+	for i, release := range releases {
+		sb.WriteString(fmt.Sprintf("  r%d [label=\"%s\"];\n", i, escape(release.releaseTag)))
+	}
+	steps := dependencySteps(releases)
+	for i, lines := range graphEdgeLabels(steps) {
+		if len(lines) == 0 {
+			sb.WriteString(fmt.Sprintf("  r%d -> r%d;\n", i, i+1))
+			continue
+		}
+		for j, line := range lines {
+			lines[j] = escape(line)
+		}
+		// Left-justify each line of the label
+		sb.WriteString(fmt.Sprintf("  r%d -> r%d [label=\"%s\\l\"];\n", i, i+1, strings.Join(lines, `\l`)))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
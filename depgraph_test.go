@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// graphReleases returns the analyses of releases with the dependencies, from the oldest to the newest.
+func graphReleases(dependencies ...map[string]string) []AnalysisResult {
+	releases := make([]AnalysisResult, len(dependencies))
+	for i, deps := range dependencies {
+		releases[i] = testAnalysis(fmt.Sprintf("v1.%d.0", i), 1000)
+		releases[i].dependencies = deps
+	}
+	return releases
+}
+
+func TestDependencyChanges(t *testing.T) {
+	changes := dependencyChanges(
+		map[string]string{"a": "^1.0.0", "b": "^1.0.0", "c": "~2.0.0"},
+		map[string]string{"a": "^1.0.0", "c": "~2.1.0", "d": "^4.0.0"},
+	)
+	expected := []dependencyChange{
+		{name: "b", kind: DependencyRemoved, from: "^1.0.0"},
+		{name: "c", kind: DependencyBumped, from: "~2.0.0", to: "~2.1.0"},
+		{name: "d", kind: DependencyAdded, to: "^4.0.0"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got %v, expected %v", changes, expected)
+	}
+}
+
+func TestDependencyGraphGoldens(t *testing.T) {
+	failed := graphReleases(map[string]string{"a": "^1.0.0"}, nil, map[string]string{"a": "^2.0.0"})
+	failed[1] = failedAnalysis("v1.1.0", "404 Not Found")
+
+	// More changes than maxGraphChanges, grouped by release
+	many := make([]map[string]string, 3)
+	for i := range many {
+		many[i] = make(map[string]string)
+	}
+	for i := 0; i < maxGraphChanges; i++ {
+		name := fmt.Sprintf("dep-%03d", i)
+		many[1][name] = "^1.0.0"
+		if i%2 == 0 {
+			many[2][name] = "^2.0.0"
+		}
+	}
+
+	for _, test := range []struct {
+		name     string
+		releases []AnalysisResult
+	}{
+		{"empty", nil},
+		{"single", graphReleases(map[string]string{"a": "^1.0.0"})},
+		{
+			"changes", graphReleases(
+				map[string]string{"svelte": "^4.0.0", "tslib": "^2.0.0"},
+				map[string]string{"svelte": "^4.0.0", "tslib": "^2.0.0"},
+				map[string]string{"svelte": "^5.0.0", `"quoted" \ dep`: "*"},
+			),
+		},
+		{"failed", failed},
+		{"grouped", graphReleases(many...)},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				// The goldens don't change with the version of the program
+				unversioned := func(graph string) string {
+					return strings.Replace(graph, " "+appVersion+"\n", " VERSION\n", 1)
+				}
+				checkGolden(t, "graphs/"+test.name+".mmd", unversioned(RenderMermaidGraph(test.releases)))
+				checkGolden(t, "graphs/"+test.name+".dot", unversioned(RenderDOTGraph(test.releases)))
+			},
+		)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detailChromeHeight is the number of lines of the detail pane around its viewport: the title and the help.
+const detailChromeHeight = 4
+
+// releaseDetail is the full-screen pane of everything known about a release of the summary,
+// opened with enter, its content scrolling in a viewport.
+type releaseDetail struct {
+	tag      string
+	viewport viewport.Model
+}
+
+// openDetail opens the detail pane of the release.
+func (m model) openDetail(item ListItem) model {
+	m.detail = &releaseDetail{tag: item.releaseTag, viewport: viewport.New(0, 0)}
+	m.detail.viewport.SetContent(m.detailContent(item))
+	m.resizeSummary()
+	return m
+}
+
+// updateDetail handles a key while the detail pane is open: esc goes back to the list,
+// and the other keys scroll the pane.
+func (m model) updateDetail(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q", "backspace":
+		m.detail = nil
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.detail.viewport, cmd = m.detail.viewport.Update(msg)
+	return m, cmd
+}
+
+func (d releaseDetail) view() string {
+	help := "↑/↓ scroll • esc back"
+	if !d.viewport.AtTop() || !d.viewport.AtBottom() {
+		help = fmt.Sprintf("%3.f%% • %s", d.viewport.ScrollPercent()*100, help)
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		svelteBg.Padding(0, 1).Render(d.tag),
+		"",
+		d.viewport.View(),
+		"",
+		blurredStyle.Render(help),
+	)
+}
+
+// detailContent renders everything known about the release: its GitHub release,
+// its sizes and their changes from the previous and the base releases, and all its languages.
+func (m model) detailContent(item ListItem) string {
+	var sb strings.Builder
+	row := func(label, value string) {
+		sb.WriteString(blurredStyle.Render(fmt.Sprintf("%-22s", label)) + value + "\n")
+	}
+	section := func(title string) {
+		sb.WriteString("\n" + svelteText.Render(title) + "\n")
+	}
+
+	section("Release")
+	if i := m.releaseIndex(item.releaseTag); i != -1 {
+		release := m.data.releases[i]
+		published := release.CreatedAt
+		if release.PublishedAt != nil {
+			published = *release.PublishedAt
+		}
+		if !published.IsZero() {
+			row("Published", published.Format("2006-01-02 15:04 MST"))
+		}
+		row("Prerelease", yesNo(release.Prerelease))
+		if release.HtmlUrl != "" {
+			row("URL", release.HtmlUrl)
+		}
+	} else {
+		row("Published", notMeasuredText+" (not a GitHub release)")
+	}
+	if item.endpoint != noEndpoint {
+		row("Compared", strings.TrimSpace(item.endpoint.marker()))
+	}
+	if item.packageName != "" {
+		row("Package", item.packageName)
+	}
+	if item.note != "" {
+		row("Note", item.note)
+	}
+	if item.failed != "" {
+		row("Analysis", errorStyle.Render("failed: "+item.failed))
+	}
+
+	section("Size")
+	row("Files", item.measuredFiles().format("%d"))
+	row("Lines", item.measuredLines().format("%d"))
+	if item.kindsCounted && item.failed == "" {
+		row("Code lines", fmt.Sprintf("%d", item.lineKinds.Code))
+		row("Comment lines", fmt.Sprintf("%d", item.lineKinds.Comment))
+		row("Blank lines", fmt.Sprintf("%d", item.lineKinds.Blank))
+	}
+	if generated := item.generatedText(); generated != "" {
+		row("Left out", generated)
+	}
+	row("Extracted size", formatMeasuredBytes(item.measuredDirSize(), ByteCountSI))
+	row("Gzipped tarball", formatMeasuredBytes(item.measuredTarSize(), binaryByteCount))
+	if approximation := item.approximation(); approximation != "" {
+		row("Approximation", approximation)
+	}
+	if item.warningsCount > 0 {
+		row("Warnings", warningStyle.Render(fmt.Sprintf("%d", item.warningsCount)))
+	}
+
+	if item.previous != nil {
+		section("Since the previous release (" + item.previous.releaseTag + ")")
+		detailChanges(row, item.previous.AnalysisResult, item.AnalysisResult)
+		if top, ok := topChangedFile(item.previous.AnalysisResult, item.AnalysisResult); ok {
+			label := "Top file"
+			if item.dominantFile != nil {
+				label = "Mostly"
+			}
+			row(label, top.String())
+		}
+	}
+	base := m.releaseIndex(m.data.firstRelease)
+	if base != -1 && base < len(m.data.analysis) && m.data.firstRelease != item.releaseTag {
+		section("Since the base release (" + m.data.firstRelease + ")")
+		detailChanges(row, m.data.analysis[base], item.AnalysisResult)
+	}
+
+	section(fmt.Sprintf("Languages (%d)", len(item.linesByLanguage)))
+	var previousLines map[string]uint
+	if item.previous != nil {
+		previousLines = item.previous.linesByLanguage
+	}
+	for _, language := range topLanguages(item.linesByLanguage, len(item.linesByLanguage), nil) {
+		value := fmt.Sprintf("%d lines", language.lines)
+		if item.totalLines > 0 {
+			value += fmt.Sprintf(" (%.1f%%)", float64(language.lines)/float64(item.totalLines)*100)
+		}
+		if previousLines != nil {
+			value += "  " + textForDiff(measured(int(language.lines)-int(previousLines[language.language])))
+		}
+		row(language.language, value)
+	}
+	if previousLines != nil {
+		for _, language := range topLanguages(previousLines, len(previousLines), nil) {
+			if _, ok := item.linesByLanguage[language.language]; !ok {
+				row(language.language, blurredStyle.Render("removed")+"  "+textForDiff(measured(-int(language.lines))))
+			}
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// detailChanges renders the changes of the sizes of a release from an earlier one.
+func detailChanges(row func(label, value string), from, to AnalysisResult) {
+	row("Files", to.measuredFiles().since(from.measuredFiles()).format("%+d"))
+	row("Lines", textForDiff(to.measuredLines().since(from.measuredLines())))
+	if change, unit := lineChange(from, to); unit != "lines" {
+		row("Code lines", textForUnitDiff(change, unit))
+	}
+	row("Extracted size", formatMeasuredBytes(to.measuredDirSize().since(from.measuredDirSize()), byteCountSIDiff))
+	row("Gzipped tarball", formatMeasuredBytes(to.measuredTarSize().since(from.measuredTarSize()), binaryByteCountDiff))
+}
+
+// binaryByteCount formats a number of bytes with a binary unit, see formatBytes.
+func binaryByteCount(bytes int64) string {
+	return formatBytes(float64(bytes))
+}
+
+// binaryByteCountDiff formats a signed difference of bytes with a binary unit, see formatBytesDiff.
+func binaryByteCountDiff(bytes int64) string {
+	return formatBytesDiff(float64(bytes))
+}
+
+// formatMeasuredBytes formats a measured number or difference of bytes with the formatter,
+// or "n/a" if it was not measured.
+func formatMeasuredBytes(bytes measure[int64], format func(int64) string) string {
+	if !bytes.measured {
+		return notMeasuredText
+	}
+	return format(bytes.value)
+}
+
+// yesNo renders a boolean as yes or no.
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitHubURLRegex matches the owner and repository name of a GitHub URL, in the HTTPS
+// (https://github.com/owner/repo.git), SSH (git@github.com:owner/repo.git
+// or ssh://git@github.com/owner/repo) and npm shorthand (github:owner/repo) forms.
+var gitHubURLRegex = regexp.MustCompile(`^(?:(?:git\+)?(?:https?|ssh|git)://(?:[^@/]+@)?github\.com/|git@github\.com:|github:)([\w.-]+)/([\w.-]+?)(?:\.git)?/?$`)
+
+// shorthandRepoRegex matches the `owner/repo` shorthand of the package.json `repository` field.
+var shorthandRepoRegex = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)$`)
+
+// ParseGitHubRepository extracts the `owner/repo` of a GitHub URL or shorthand.
+func ParseGitHubRepository(url string) (string, bool) {
+	url = strings.TrimSpace(url)
+	for _, regex := range []*regexp.Regexp{gitHubURLRegex, shorthandRepoRegex} {
+		if matches := regex.FindStringSubmatch(url); matches != nil {
+			return matches[1] + "/" + matches[2], true
+		}
+	}
+	return "", false
+}
+
+// DetectRepository tries to infer the GitHub repository of the project in dir,
+// from the origin remote of its git configuration, then from the `repository`
+// field of its package.json. It returns the repository and where it was detected from,
+// or empty strings if nothing was found.
+func DetectRepository(dir string) (repo, source string) {
+	if url := gitOriginURL(filepath.Join(dir, ".git", "config")); url != "" {
+		if repo, ok := ParseGitHubRepository(url); ok {
+			return repo, "git remote"
+		}
+	}
+	if url := packageJSONRepository(filepath.Join(dir, "package.json")); url != "" {
+		if repo, ok := ParseGitHubRepository(url); ok {
+			return repo, "package.json"
+		}
+	}
+	return "", ""
+}
+
+// gitOriginURL returns the URL of the origin remote of a git configuration file.
+func gitOriginURL(configPath string) string {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return ""
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	inOrigin := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		if key, value, found := strings.Cut(line, "="); found && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// packageJSONRepository returns the `repository` field of a package.json file,
+// either as a string or as the `url` of an object.
+func packageJSONRepository(packageJSONPath string) string {
+	content, err := os.ReadFile(packageJSONPath)
+	if err != nil {
+		return ""
+	}
+
+	var packageJSON struct {
+		Repository json.RawMessage `json:"repository"`
+	}
+	if err = json.Unmarshal(content, &packageJSON); err != nil || packageJSON.Repository == nil {
+		return ""
+	}
+
+	var url string
+	if err = json.Unmarshal(packageJSON.Repository, &url); err == nil {
+		return url
+	}
+	var repository struct {
+		URL string `json:"url"`
+	}
+	if err = json.Unmarshal(packageJSON.Repository, &repository); err == nil {
+		return repository.URL
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultDirTemplate is the template of the extraction directories,
+// extracting each release to a directory named after its tag.
+const defaultDirTemplate = "{tag}"
+
+// dirTemplatePlaceholder matches a placeholder of a directory template, such as "{version}".
+var dirTemplatePlaceholder = regexp.MustCompile(`\{([^{}]*)}`)
+
+// dirTemplateVariables are the variables available in a directory template.
+var dirTemplateVariables = []string{"tag", "package", "version", "owner", "repo"}
+
+// ValidateDirTemplate checks that the directory template is not empty
+// and only uses known variables.
+func ValidateDirTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("invalid --dir-template: empty template")
+	}
+	for _, match := range dirTemplatePlaceholder.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(dirTemplateVariables, match[1]) {
+			return fmt.Errorf(
+				"invalid --dir-template: unknown variable {%s}, expected one of {%s}",
+				match[1], strings.Join(dirTemplateVariables, "}, {"),
+			)
+		}
+	}
+	return nil
+}
+
+// renderDirTemplate substitutes the variables of the directory template for a release
+// of the owner/repo repository, e.g. "{package}/{version}" -> "@sveltejs/kit/1.0.0".
+func renderDirTemplate(template, ownerRepo, tag string) string {
+	owner, repo, _ := strings.Cut(ownerRepo, "/")
+	name, version := npmPackageVersion(tag)
+	values := map[string]string{
+		"tag":     tag,
+		"package": name,
+		"version": version,
+		"owner":   owner,
+		"repo":    repo,
+	}
+	return dirTemplatePlaceholder.ReplaceAllStringFunc(
+		template, func(placeholder string) string {
+			return values[placeholder[1:len(placeholder)-1]]
+		},
+	)
+}
+
+// planReleaseDirs renders the extraction directory of each release under the output directory.
+// It fails if a directory escapes the output directory, is the output directory itself
+// or the directory of the run manifests, or if two releases render to the same directory
+// or to nested ones, as the analysis of the outer one would count the files of the other.
+func planReleaseDirs(output, template, ownerRepo string, tags []string) (map[string]string, error) {
+	dirs := make(map[string]string, len(tags))
+	owners := make(map[string]string, len(tags)) // Tag of each relative directory
+	for _, tag := range tags {
+		rendered := renderDirTemplate(template, ownerRepo, tag)
+		relative := filepath.Clean(filepath.FromSlash(rendered))
+		if filepath.IsAbs(relative) || relative == "." || relative == ".." ||
+			strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("--dir-template renders %s to %q, outside of the output directory", tag, rendered)
+		}
+		if root := strings.SplitN(relative, string(filepath.Separator), 2)[0]; root == runsDirName {
+			return nil, fmt.Errorf("--dir-template renders %s to %q, reserved for the run manifests", tag, rendered)
+		}
+		for dir, other := range owners {
+			switch {
+			case dir == relative:
+				return nil, fmt.Errorf("--dir-template renders both %s and %s to %q", other, tag, rendered)
+			case strings.HasPrefix(relative, dir+string(filepath.Separator)),
+				strings.HasPrefix(dir, relative+string(filepath.Separator)):
+				return nil, fmt.Errorf(
+					"--dir-template renders %s and %s to nested directories %q and %q",
+					other, tag, filepath.ToSlash(dir), rendered,
+				)
+			}
+		}
+		owners[relative] = tag
+		dirs[tag] = filepath.Join(output, relative)
+	}
+	return dirs, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "sync/atomic"
+
+// DiskBudget is the number of bytes all the extractions of a run may write to disk, see `--max-disk`.
+// It counts the bytes actually written by the extractions, which can run concurrently.
+// A nil budget is no budget.
+type DiskBudget struct {
+	limit int64
+	used  int64 // Accessed atomically
+}
+
+// NewDiskBudget returns a budget of limit bytes, or nil if limit is 0.
+func NewDiskBudget(limit int64) *DiskBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &DiskBudget{limit: limit}
+}
+
+// Write counts the bytes written to disk.
+func (b *DiskBudget) Write(p []byte) (int, error) {
+	if b != nil {
+		atomic.AddInt64(&b.used, int64(len(p)))
+	}
+	return len(p), nil
+}
+
+// Exhausted returns whether the bytes written reached the budget,
+// after which no new extraction is started.
+func (b *DiskBudget) Exhausted() bool {
+	return b != nil && atomic.LoadInt64(&b.used) >= b.limit
+}
+
+// String renders the limit of the budget.
+func (b *DiskBudget) String() string {
+	if b == nil {
+		return "unlimited"
+	}
+	return formatBytes(float64(b.limit))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"path"
+)
+
+// defaultDominantFileThreshold is the default of `--dominant-file-threshold`.
+const defaultDominantFileThreshold = "50%"
+
+// minDominantChange is the number of changed lines below which a release isn't labeled
+// as mostly changed in a single file, as most small releases only touch a few files.
+const minDominantChange = 100
+
+// fileAttribution is the file contributing the most to the change of lines of a release
+// from the previous one.
+type fileAttribution struct {
+	path  string  // Normalized path of the file, see normalizeReleasePath
+	delta int     // Change of lines of the file, negative if removed lines
+	share float64 // Ratio of the changed lines of all the files that are the file's
+	total int     // Lines added and removed across all the files
+}
+
+// topChangedFile returns the file whose lines changed the most from the previous release,
+// its share being relative to the lines added and removed across all the files, so that
+// a file growing while another one shrinks isn't hidden by a small net change.
+// It returns false if either analysis failed or no file changed.
+func topChangedFile(previous, current AnalysisResult) (fileAttribution, bool) {
+	if previous.failed != "" || current.failed != "" {
+		return fileAttribution{}, false
+	}
+	previousFiles, currentFiles := previous.normalizedFiles(), current.normalizedFiles()
+	var top fileAttribution
+	var changed float64
+	consider := func(file string, delta int) {
+		if delta == 0 {
+			return
+		}
+		changed += math.Abs(float64(delta))
+		if top.path == "" || abs(delta) > abs(top.delta) || abs(delta) == abs(top.delta) && cmp.Less(file, top.path) {
+			top = fileAttribution{path: file, delta: delta}
+		}
+	}
+	for file, lines := range currentFiles {
+		consider(file, int(lines)-int(previousFiles[file]))
+	}
+	for file, lines := range previousFiles {
+		if _, ok := currentFiles[file]; !ok {
+			consider(file, -int(lines))
+		}
+	}
+	if top.path == "" {
+		return fileAttribution{}, false
+	}
+	top.share, top.total = math.Abs(float64(top.delta))/changed, int(changed)
+	return top, true
+}
+
+// dominantFile returns the file accounting for more than the threshold of the changed lines
+// from the previous release, if any. A threshold of 0 disables it.
+func dominantFile(previous, current AnalysisResult, threshold float64) *fileAttribution {
+	top, ok := topChangedFile(previous, current)
+	if threshold <= 0 || !ok || top.share <= threshold || top.total < minDominantChange {
+		return nil
+	}
+	return &top
+}
+
+// String renders the attribution, such as `dist/types.d.ts: +1200 lines (82% of the changed lines)`.
+func (f fileAttribution) String() string {
+	return fmt.Sprintf("%s: %+d lines (%.0f%% of the changed lines)", f.path, f.delta, f.share*100)
+}
+
+// dominantFileText renders the label of a release mostly changed in a single file.
+func (l ListItem) dominantFileText() string {
+	if l.dominantFile == nil {
+		return ""
+	}
+	return "mostly " + path.Base(l.dominantFile.path)
+}
+
+// abs returns the absolute value of an integer.
+func abs[T int | int64](value T) T {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// JSONFileAttribution is the file contributing the most to a delta in the JSON export.
+type JSONFileAttribution struct {
+	Path     string  `json:"path"`
+	Lines    int     `json:"lines"`    // Change of lines of the file
+	Share    float64 `json:"share"`    // Ratio of the changed lines of all the files that are the file's
+	Dominant bool    `json:"dominant"` // Whether the share is above --dominant-file-threshold
+}
+
+// jsonTopFile returns the JSON export of the file contributing the most to the change of lines, nil if none.
+func jsonTopFile(previous, current AnalysisResult, threshold float64) *JSONFileAttribution {
+	top, ok := topChangedFile(previous, current)
+	if !ok {
+		return nil
+	}
+	return &JSONFileAttribution{
+		Path:     top.path,
+		Lines:    top.delta,
+		Share:    math.Round(top.share*1000) / 1000,
+		Dominant: dominantFile(previous, current, threshold) != nil,
+	}
+}
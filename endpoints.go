@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// endpoint is which of the compared releases a release of the summary is, if any.
+type endpoint string
+
+const (
+	noEndpoint   endpoint = ""
+	fromEndpoint endpoint = "from" // Base release of the comparison
+	toEndpoint   endpoint = "to"   // Release compared to the base one
+)
+
+// endpointOf returns which of the compared releases the release is.
+func (d data) endpointOf(tag string) endpoint {
+	switch tag {
+	case d.firstRelease:
+		return fromEndpoint
+	case d.secondRelease:
+		return toEndpoint
+	default:
+		return noEndpoint
+	}
+}
+
+// marker renders the prefix of the title of an endpoint release.
+// The marker is textual, so that the endpoints stand out without colors too.
+func (e endpoint) marker() string {
+	if e == noEndpoint {
+		return ""
+	}
+	return svelteText.Bold(true).Render("▌"+string(e)) + " "
+}
+
+// jumpToEndpoint selects the given endpoint release in the summary list.
+func (m model) jumpToEndpoint(which endpoint) (model, tea.Cmd) {
+	tag := m.data.firstRelease
+	if which == toEndpoint {
+		tag = m.data.secondRelease
+	}
+	return m.jumpToRelease(tag)
+}
+
+// jumpToRelease selects the release of the tag in the summary list,
+// expanding its month first when the list is grouped by month.
+// Nothing is selected if the release is filtered out.
+func (m model) jumpToRelease(tag string) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	if m.groupByMonth {
+		for _, group := range groupByMonth(m.data.datedAnalysis()) {
+			for _, release := range group.releases {
+				if release.releaseTag == tag && !m.expandedMonths[group.key()] {
+					if m.expandedMonths == nil {
+						m.expandedMonths = make(map[string]bool)
+					}
+					m.expandedMonths[group.key()] = true
+					cmd = m.list.SetItems(m.sortedItems())
+				}
+			}
+		}
+	}
+	if i := releaseIndex(m.list.VisibleItems(), tag); i != -1 {
+		m.list.Select(i)
+	}
+	return m, cmd
+}
+
+// releaseIndex returns the index of the release of the tag among the items, or -1 if absent.
+func releaseIndex(items []list.Item, tag string) int {
+	for i, item := range items {
+		if item, ok := item.(ListItem); ok && item.releaseTag == tag {
+			return i
+		}
+	}
+	return -1
+}
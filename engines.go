@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// unspecified is the value of an engine or package manager missing from a manifest.
+const unspecified = "unspecified"
+
+// runtimeChange is a change of an engine or of the package manager between two releases.
+type runtimeChange struct {
+	field    string // "engines.<engine>" or "packageManager"
+	from, to string
+}
+
+// label returns what the change is about, e.g. "node support".
+func (c runtimeChange) label() string {
+	if engine, ok := strings.CutPrefix(c.field, "engines."); ok {
+		return engine + " support"
+	}
+	return "package manager"
+}
+
+// runtimeChanges returns the changes of the engines and of the package manager
+// from the previous release, sorted by field. A missing field only changes
+// when it appears or disappears, from or to "unspecified".
+func runtimeChanges(previous, current AnalysisResult) []runtimeChange {
+	var changes []runtimeChange
+	compare := func(field, from, to string) {
+		if from == to {
+			return
+		}
+		if from == "" {
+			from = unspecified
+		}
+		if to == "" {
+			to = unspecified
+		}
+		changes = append(changes, runtimeChange{field, from, to})
+	}
+
+	var engines []string
+	for engine := range previous.engines {
+		engines = append(engines, engine)
+	}
+	for engine := range current.engines {
+		if _, ok := previous.engines[engine]; !ok {
+			engines = append(engines, engine)
+		}
+	}
+	slices.Sort(engines)
+	for _, engine := range engines {
+		compare("engines."+engine, previous.engines[engine], current.engines[engine])
+	}
+	compare("packageManager", previous.packageManager, current.packageManager)
+	return changes
+}
+
+// runtimeTransitions renders the changes of the engines and of the package manager
+// across the analyzed releases, from the oldest to the newest, one per line,
+// e.g. "node support changed at v5.0.0: >=16 → >=18".
+func (d data) runtimeTransitions() []string {
+	var transitions []string
+	chronological := d.chronological()
+	for i := 1; i < len(chronological); i++ {
+		previous, current := chronological[i-1], chronological[i]
+		if previous.failed != "" || current.failed != "" {
+			continue
+		}
+		for _, change := range runtimeChanges(previous, current) {
+			transitions = append(
+				transitions,
+				fmt.Sprintf("%s changed at %s: %s → %s", change.label(), current.releaseTag, change.from, change.to),
+			)
+		}
+	}
+	return transitions
+}
+
+// runtimeText renders the engines and the package manager of the release.
+func (l ListItem) runtimeText() string {
+	engines := make([]string, 0, len(l.engines))
+	for engine, version := range l.engines {
+		engines = append(engines, engine+" "+version)
+	}
+	slices.Sort(engines)
+	if l.packageManager != "" {
+		engines = append(engines, l.packageManager)
+	}
+	return strings.Join(engines, ", ")
+}
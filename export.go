@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ExportOrder is the order of the releases in the exports.
+type ExportOrder string
+
+const (
+	// ExportChronological exports the releases from the oldest to the newest,
+	// regardless of the order of the summary list.
+	ExportChronological ExportOrder = "chronological"
+	// ExportDisplay exports the releases in the current order of the summary list.
+	ExportDisplay ExportOrder = "display"
+)
+
+// ParseExportOrder parses the value of the `--export-order` flag.
+func ParseExportOrder(value string) (ExportOrder, error) {
+	switch order := ExportOrder(value); order {
+	case ExportChronological, ExportDisplay:
+		return order, nil
+	default:
+		return "", fmt.Errorf("invalid export order %q, expected %s or %s", value, ExportChronological, ExportDisplay)
+	}
+}
+
+// exportedAnalysis returns the analysis results to export, in the export order.
+func (m model) exportedAnalysis(order ExportOrder) []AnalysisResult {
+	// Releases of collapsed months aren't displayed, so grouped lists are exported chronologically
+	if order == ExportDisplay && m.list != nil && !m.groupByMonth {
+		items := m.sortedItems()
+		analysis := make([]AnalysisResult, 0, len(items))
+		for _, item := range items {
+			if item, ok := item.(ListItem); ok {
+				analysis = append(analysis, item.AnalysisResult)
+			}
+		}
+		return analysis
+	}
+	return m.data.chronological()
+}
+
+// chronological returns a copy of the analysis results from the oldest to the newest,
+// as they are ordered from the newest to the oldest.
+func (d data) chronological() []AnalysisResult {
+	analysis := slices.Clone(d.analysis)
+	slices.Reverse(analysis)
+	return analysis
+}
+
+// ExportFormat is the format of an export file.
+type ExportFormat string
+
+const (
+	// ExportMermaid exports the dependency changes across the releases as a Mermaid graph.
+	ExportMermaid ExportFormat = "mermaid"
+	// ExportDOT exports the dependency changes across the releases as a Graphviz DOT graph.
+	ExportDOT ExportFormat = "dot"
+	// ExportBundle exports the whole comparison as a portable bundle, see Bundle.
+	ExportBundle ExportFormat = "bundle"
+)
+
+// exportFormats are the supported export formats.
+var exportFormats = []ExportFormat{ExportMermaid, ExportDOT, ExportBundle}
+
+// ExportTarget is an export file to write once the comparison is done.
+type ExportTarget struct {
+	Format ExportFormat
+	Path   string
+}
+
+// exportDoneMsg is a message that carries the result of writing the exports.
+type exportDoneMsg struct {
+	err error
+}
+
+// ParseExports parses the value of the `--export` flag,
+// formatted as comma-separated `format=path` pairs.
+func ParseExports(value string) ([]ExportTarget, error) {
+	var targets []ExportTarget
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		format, path, found := strings.Cut(pair, "=")
+		if !found || path == "" {
+			return nil, fmt.Errorf("invalid export %q. Format: format=path", pair)
+		}
+		if !slices.Contains(exportFormats, ExportFormat(format)) {
+			return nil, fmt.Errorf("invalid export format %q, expected one of %v", format, exportFormats)
+		}
+		targets = append(targets, ExportTarget{ExportFormat(format), path})
+	}
+	return targets, nil
+}
+
+// WriteExports writes the export files of the analysis results.
+// Graphs are timelines, so they are always written in chronological order.
+func WriteExports(targets []ExportTarget, d data, chronological []AnalysisResult) tea.Cmd {
+	return func() tea.Msg {
+		for _, target := range targets {
+			var content []byte
+			switch target.Format {
+			case ExportMermaid:
+				content = []byte(RenderMermaidGraph(chronological))
+			case ExportDOT:
+				content = []byte(RenderDOTGraph(chronological))
+			case ExportBundle:
+				var err error
+				if content, err = EncodeBundle(d); err != nil {
+					return exportDoneMsg{fmt.Errorf("could not encode the %s export: %w", target.Format, err)}
+				}
+			}
+			if err := os.WriteFile(target.Path, content, 0644); err != nil {
+				return exportDoneMsg{fmt.Errorf("could not write the %s export: %w", target.Format, err)}
+			}
+		}
+		return exportDoneMsg{}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestExportOrder checks that the exports list the releases from the oldest to the newest
+// whatever the sort of the summary list, unless exported in the display order.
+func TestExportOrder(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	m := viewModel(StateSummary)
+	m.data.analysis = []AnalysisResult{
+		testAnalysis("v2.0.0", 1000),
+		testAnalysis("v1.1.0", 1500),
+		testAnalysis("v1.0.0", 1100),
+	}
+	m = m.buildSummary()
+	// Sort the list by lines, the way the user does
+	for m.sort.metric != sortLines {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+		m = updated.(model)
+	}
+
+	for _, test := range []struct {
+		order ExportOrder
+		tags  []string
+	}{
+		{ExportChronological, []string{"v1.0.0", "v1.1.0", "v2.0.0"}},
+		{ExportDisplay, []string{"v1.1.0", "v1.0.0", "v2.0.0"}},
+	} {
+		t.Run(
+			string(test.order), func(t *testing.T) {
+				releases := m.exportedAnalysis(test.order)
+
+				content, err := EncodeJSONExport(m.data, test.order, releases)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var export struct {
+					Metadata struct {
+						Order ExportOrder `json:"order"`
+					} `json:"metadata"`
+					Releases []struct {
+						Tag string `json:"tag"`
+					} `json:"releases"`
+				}
+				if err := json.Unmarshal(content, &export); err != nil {
+					t.Fatal(err)
+				}
+				var tags []string
+				for _, release := range export.Releases {
+					tags = append(tags, release.Tag)
+				}
+				if !reflect.DeepEqual(tags, test.tags) {
+					t.Errorf("JSON export of the releases %v, expected %v", tags, test.tags)
+				}
+				if export.Metadata.Order != test.order {
+					t.Errorf("JSON export marked %q, expected %q", export.Metadata.Order, test.order)
+				}
+
+				content, err = EncodeCSVExport(m.data, test.order, releases)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if marker := "\n# order: " + string(test.order) + "\n"; !bytes.Contains(content, []byte(marker)) {
+					t.Errorf("CSV export without the marker %q:\n%s", strings.TrimSpace(marker), content)
+				}
+				reader := csv.NewReader(bytes.NewReader(content))
+				reader.Comment = '#'
+				rows, err := reader.ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+				tags = nil
+				delta := slices.Index(rows[0], "total_lines_delta")
+				for _, row := range rows[1:] {
+					tags = append(tags, row[0])
+					// The deltas are from the previous release by date, whatever the export order
+					if row[0] == "v1.1.0" && row[delta] != "400" {
+						t.Errorf("CSV export of the delta %s for v1.1.0, expected 400", row[delta])
+					}
+				}
+				if !reflect.DeepEqual(tags, test.tags) {
+					t.Errorf("CSV export of the releases %v, expected %v", tags, test.tags)
+				}
+			},
+		)
+	}
+}
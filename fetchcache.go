@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// partialFetchVersion is the version of the partial fetch format.
+// Partial fetches of other versions are ignored, and the fetch starts over.
+const partialFetchVersion = 1
+
+// partialFetchWindow is how long a partial fetch can be resumed by a later run,
+// as releases published in the meantime shift the pages.
+const partialFetchWindow = 15 * time.Minute
+
+// PartialFetch is the on-disk representation of the progress of an interrupted releases fetching,
+// so that a later run with the same repository and endpoints resumes it.
+type PartialFetch struct {
+	Version   int       `json:"version"`    // Version of the partial fetch format
+	SavedAt   time.Time `json:"saved_at"`   // When the fetching was interrupted
+	Page      int       `json:"page"`       // Next page to fetch
+	PerPage   int       `json:"per_page"`   // Number of releases per page of the fetched pages
+	Releases  []Release `json:"releases"`   // Releases fetched so far
+	FoundFrom bool      `json:"found_from"` // Whether the base release was fetched
+	FoundTo   bool      `json:"found_to"`   // Whether the release to compare to was fetched
+}
+
+// partialFetchPath returns the path of the partial fetch of the releases of a repository between two endpoints.
+func partialFetchPath(ownerRepo, from, to string) string {
+	hash := sha256.Sum256([]byte(ownerRepo + "\n" + from + "\n" + to))
+	return filepath.Join(*extractionDir, runsDirName, "fetch-"+hex.EncodeToString(hash[:8])+".json")
+}
+
+// SavePartialFetch writes the progress of an interrupted releases fetching.
+func SavePartialFetch(ownerRepo, from, to string, progress fetchProgress) error {
+	content, err := json.Marshal(
+		PartialFetch{
+			Version:   partialFetchVersion,
+			SavedAt:   time.Now(),
+			Page:      progress.page,
+			PerPage:   progress.perPage,
+			Releases:  progress.releases,
+			FoundFrom: progress.foundFrom,
+			FoundTo:   progress.foundTo,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	path := partialFetchPath(ownerRepo, from, to)
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return writeFileAtomically(path, content)
+}
+
+// loadPartialFetch returns the progress of the releases fetching interrupted by a previous run,
+// if it was saved within the partialFetchWindow. The partial fetch is consumed either way.
+func loadPartialFetch(ownerRepo, from, to string) (fetchProgress, bool) {
+	path := partialFetchPath(ownerRepo, from, to)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fetchProgress{}, false
+	}
+	_ = os.Remove(path)
+	var partial PartialFetch
+	if err = json.Unmarshal(content, &partial); err != nil ||
+		partial.Version != partialFetchVersion || time.Since(partial.SavedAt) > partialFetchWindow {
+		return fetchProgress{}, false
+	}
+	return fetchProgress{
+		page:      partial.Page,
+		perPage:   partial.PerPage,
+		releases:  partial.Releases,
+		foundFrom: partial.FoundFrom,
+		foundTo:   partial.FoundTo,
+	}, true
+}
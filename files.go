@@ -0,0 +1,173 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sparklineLevels are the characters of a sparkline, from the lowest to the highest value.
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparklineAbsent is the sparkline character of a release without the file.
+const sparklineAbsent = '·'
+
+// normalizeReleasePath strips the top-level directory npm wraps
+// the content of a tarball in, usually `package/`, from a release file path,
+// so that the paths of different releases can be compared.
+func normalizeReleasePath(path string) string {
+	if _, rest, found := strings.Cut(path, "/"); found {
+		return rest
+	}
+	return path
+}
+
+// normalizedFiles returns the lines of the files of a release by normalized path.
+func (a AnalysisResult) normalizedFiles() map[string]uint {
+	files := make(map[string]uint, len(a.files))
+	for path, lines := range a.files {
+		files[normalizeReleasePath(path)] = lines
+	}
+	return files
+}
+
+// filePoint is the line count of a file in a release.
+type filePoint struct {
+	releaseTag string
+	lines      uint
+	present    bool // Whether the release contains the file
+	measured   bool // Whether the release was analyzed, its analysis not having failed
+}
+
+// fileTimeline returns the line count of the file at the normalized path
+// in each release, ordered like the results. Renamed files are reported
+// as disappearing, as their new path is a different file.
+func fileTimeline(results []AnalysisResult, path string) []filePoint {
+	points := make([]filePoint, len(results))
+	for i, result := range results {
+		lines, ok := result.normalizedFiles()[path]
+		points[i] = filePoint{releaseTag: result.releaseTag, lines: lines, present: ok, measured: result.failed == ""}
+	}
+	return points
+}
+
+// sparkline renders the line counts of the points as a sparkline.
+func sparkline(points []filePoint) string {
+	var highest uint
+	for _, point := range points {
+		if point.lines > highest {
+			highest = point.lines
+		}
+	}
+	var sb strings.Builder
+	for _, point := range points {
+		if !point.present {
+			sb.WriteRune(sparklineAbsent)
+			continue
+		}
+		level := 0
+		if highest > 0 {
+			level = int(point.lines * uint(len(sparklineLevels)-1) / highest)
+		}
+		sb.WriteRune(sparklineLevels[level])
+	}
+	return sb.String()
+}
+
+// renderFileTimeline renders the sparkline and the table
+// of the line counts of a file across the releases.
+func renderFileTimeline(path string, points []filePoint) string {
+	tagWidth := 0
+	for _, point := range points {
+		if width := lipgloss.Width(point.releaseTag); width > tagWidth {
+			tagWidth = width
+		}
+	}
+
+	rows := []string{svelteText.Render(sparkline(points)), ""}
+	var previous *filePoint
+	for i, point := range points {
+		row := fmt.Sprintf("%-*s  ", tagWidth, point.releaseTag)
+		switch {
+		case !point.measured:
+			row += blurredStyle.Render(notMeasuredText)
+		case !point.present && previous != nil && previous.present:
+			row += errorStyle.Render("removed")
+		case !point.present:
+			row += blurredStyle.Render("absent")
+		case previous != nil && !previous.measured:
+			row += fmt.Sprintf("%d lines  ", point.lines) + textForDiff(measure[int]{})
+		case previous != nil && !previous.present:
+			row += fmt.Sprintf("%d lines ", point.lines) + successStyle.Render("added")
+		case previous != nil:
+			row += fmt.Sprintf("%d lines  ", point.lines) + textForDiff(measured(int(point.lines)-int(previous.lines)))
+		default:
+			row += fmt.Sprintf("%d lines", point.lines)
+		}
+		rows = append(rows, row)
+		previous = &points[i]
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		svelteBg.Padding(0, 1).Render("File timeline: "+path),
+		"",
+		strings.Join(rows, "\n"),
+		"",
+		blurredStyle.Render("esc back to the files"),
+	)
+}
+
+// FileItem is a file of a release in the file drill-down.
+type FileItem struct {
+	path  string // Normalized path of the file
+	lines uint
+}
+
+func (f FileItem) Title() string {
+	return f.path
+}
+
+func (f FileItem) Description() string {
+	return fmt.Sprintf("%d lines", f.lines)
+}
+
+func (f FileItem) FilterValue() string {
+	return f.path
+}
+
+var _ list.DefaultItem = (*FileItem)(nil)
+
+// newFilesList creates the list of the files of a release, from the largest
+// to the smallest, selecting one shows its timeline across the releases.
+func newFilesList(result AnalysisResult, width, height int) list.Model {
+	files := result.normalizedFiles()
+	items := make([]FileItem, 0, len(files))
+	for path, lines := range files {
+		items = append(items, FileItem{path, lines})
+	}
+	slices.SortFunc(
+		items, func(a, b FileItem) int {
+			if c := cmp.Compare(b.lines, a.lines); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.path, b.path)
+		},
+	)
+	listItems := make([]list.Item, len(items))
+	for i, item := range items {
+		listItems[i] = item
+	}
+
+	l := list.New(listItems, list.NewDefaultDelegate(), width, height)
+	l.Title = "Files of " + result.releaseTag
+	l.Styles.Title = svelteBg.Padding(0, 1)
+	l.Styles.FilterPrompt = svelteText
+	l.Styles.FilterCursor = svelteText
+	l.DisableQuitKeybindings()
+	return l
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testAnalysis returns the analysis result of a release as counted by the analysis,
+// with every field that a baseline or a bundle records set.
+func testAnalysis(tag string, lines uint) AnalysisResult {
+	analysis := newAnalysisResult(tag)
+	analysis.totalLines = lines
+	analysis.totalFiles = 2
+	analysis.linesByLanguage["JavaScript"] = lines - lines/4
+	analysis.linesByLanguage["TypeScript"] = lines / 4
+	analysis.linesByExt[".js"] = lines - lines/4
+	analysis.linesByExt[".d.ts"] = lines / 4
+	analysis.files["package/index.js"] = lines - lines/4
+	analysis.files["package/index.d.ts"] = lines / 4
+	analysis.otherByExt[".map"] = 1
+	analysis.esmFiles = 1
+	analysis.packageName = "pkg"
+	analysis.tarSize = uint64(lines) * 10
+	analysis.totalDirSize = int64(lines) * 40
+	analysis.engines = map[string]string{"node": ">=18"}
+	analysis.lfLines = lines
+	analysis.lineKinds = LineKinds{Code: lines - lines/5, Comment: lines / 10, Blank: lines/5 - lines/10}
+	return analysis
+}
+
+// testRelease returns a published GitHub release of the tag.
+func testRelease(tag string, createdAt time.Time) Release {
+	return Release{TagName: tag, CreatedAt: createdAt, PublishedAt: &createdAt}
+}
+
+// tarEntry is an entry of a test archive, with the content of a regular file.
+type tarEntry struct {
+	header  tar.Header
+	content string
+}
+
+// tarFile returns the entry of a regular file of the content.
+func tarFile(name, content string) tarEntry {
+	return tarEntry{tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}, content}
+}
+
+// testTarball returns the gzipped tar of the entries, in their order.
+func testTarball(t testing.TB, entries ...tarEntry) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	writer := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := entry.header
+		if err := writer.WriteHeader(&header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// packageTarball returns the tarball of a package as published on npm, under `package/`,
+// with files of every kind the analysis tells apart.
+func packageTarball(t testing.TB) []byte {
+	t.Helper()
+	return testTarball(
+		t,
+		tarEntry{tar.Header{Name: "package/", Typeflag: tar.TypeDir, Mode: 0755}, ""},
+		tarFile("package/package.json", `{"name": "pkg", "type": "module", "engines": {"node": ">=18"}}`+"\n"),
+		tarFile("package/index.js", "// Entry point\nimport { a } from './lib/a.mjs';\n\nexport default a;\n"),
+		tarFile("package/lib/a.mjs", "/* A */\nexport const a = 1;\n"),
+		tarFile("package/lib/b.cjs", "module.exports = {};\r\n\r\n"),
+		tarFile("package/lib/legacy/package.json", `{"type": "commonjs"}`),
+		tarFile("package/lib/legacy/index.js", "'use strict';\nmodule.exports = 2;\n"),
+		tarFile("package/index.d.ts", "export default number;\n"),
+		tarFile("package/dist/index.min.js", "var a=1;"+strings.Repeat("a=a+1;", 200)+"\n"),
+		tarFile("package/test/index.test.js", "test('a', () => {});\n"),
+		tarFile("package/README.md", "# pkg\n\nA package.\n"),
+		tarFile("package/LICENSE", "MIT\n"),
+		tarFile("package/bin/cli", "#!/usr/bin/env node\nconsole.log('pkg');\n"),
+		tarEntry{tar.Header{Name: "package/link.js", Typeflag: tar.TypeSymlink, Linkname: "index.js"}, ""},
+	)
+}
+
+// craftedEntries are the entries of a crafted archive trying to write outside of its extraction directory.
+func craftedEntries() []tarEntry {
+	return []tarEntry{
+		tarFile("../escaped.js", "escaped"),
+		tarFile("package/../../escaped.js", "escaped"),
+		tarFile("/tmp/absolute.js", "absolute"),
+		{tar.Header{Name: "package/link", Typeflag: tar.TypeSymlink, Linkname: "../.."}, ""},
+		tarFile("package/link/escaped.js", "through the link"),
+		{tar.Header{Name: "package/hard", Typeflag: tar.TypeLink, Linkname: "/etc/passwd"}, ""},
+	}
+}
+
+// fakeGitHub serves the releases of a repository like the GitHub API, page by page,
+// recording the pages it served.
+type fakeGitHub struct {
+	mu        sync.Mutex
+	pages     [][]Release // Releases of each page, the newest first; the pages after them are empty
+	limitedAt int         // Page answered once with a rate limit error, 0 for none
+	served    []int       // Pages served, in the order of the requests
+	limited   []int       // Pages answered with a rate limit error
+}
+
+func (f *fakeGitHub) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page, err := strconv.Atoi(request.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		http.Error(writer, "invalid page", http.StatusBadRequest)
+		return
+	}
+	if page == f.limitedAt {
+		f.limitedAt = 0
+		f.limited = append(f.limited, page)
+		writer.Header().Set("X-RateLimit-Remaining", "0")
+		writer.Header().Set("X-RateLimit-Reset", "0")
+		http.Error(writer, "API rate limit exceeded", http.StatusForbidden)
+		return
+	}
+	f.served = append(f.served, page)
+	releases := []Release{}
+	if page <= len(f.pages) {
+		releases = f.pages[page-1]
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(releases)
+}
+
+// rewriteTransport sends every request to a test server instead of its host.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.URL.Scheme, request.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// withFakeAPI sends the requests of the default HTTP client, such as those to the GitHub API,
+// and those to the npm registry to the handler for the duration of the test.
+// The partial fetches are saved in a temporary directory.
+func withFakeAPI(t *testing.T, handler http.Handler) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, registry := http.DefaultClient, registryClient
+	http.DefaultClient = &http.Client{Transport: rewriteTransport{target}}
+	registryClient = http.DefaultClient
+	withFlags(t, map[*string]string{extractionDir: t.TempDir()})
+	t.Cleanup(
+		func() {
+			http.DefaultClient, registryClient = client, registry
+			server.Close()
+		},
+	)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// inputField is the field of the data a text input of the form fills.
+type inputField int
+
+const (
+	fieldRepository inputField = iota
+	fieldToken
+	fieldFirstRelease
+	fieldSecondRelease
+	fieldPackage
+	fieldIgnore
+)
+
+// formInput is a text input of the form, along with the field of the data it fills.
+// Only the values that weren't provided through the flags have an input, see newInputs.
+type formInput struct {
+	textinput.Model
+	field inputField
+}
+
+// isRelease returns whether the input is one of the release inputs.
+func (i formInput) isRelease() bool {
+	return i.field == fieldFirstRelease || i.field == fieldSecondRelease
+}
+
+// inputValue returns the value of the input of the field, and false if the field has no input.
+func (m model) inputValue(field inputField) (string, bool) {
+	for _, input := range m.inputs {
+		if input.field == field {
+			return input.Value(), true
+		}
+	}
+	return "", false
+}
+
+// submitInputs reads the values of the inputs into their fields of the data, then validates them
+// in the order of the form, failing on the first invalid one. Every value is read even if one is invalid,
+// for the form to be filled again with them. The fields without an input keep their value.
+func (m *model) submitInputs() error {
+	for _, input := range m.inputs {
+		value := input.Value()
+		switch input.field {
+		case fieldRepository:
+			m.data.ghRepo = value
+		case fieldToken:
+			m.data.ghToken = value
+		case fieldFirstRelease:
+			m.data.firstRelease = value
+		case fieldSecondRelease:
+			m.data.secondRelease = value
+		case fieldPackage:
+			m.data.npmPackage = strings.TrimSpace(value)
+		case fieldIgnore:
+			m.data.ignoreRegex = value
+		}
+	}
+
+	for _, input := range m.inputs {
+		switch input.field {
+		case fieldRepository:
+			if m.data.ghRepo == "" || strings.Count(m.data.ghRepo, "/") != 1 {
+				return fmt.Errorf("invalid GitHub repository format. Format: owner/repo")
+			}
+		case fieldFirstRelease:
+			if m.data.firstRelease == "" {
+				return fmt.Errorf("invalid base release")
+			}
+		case fieldSecondRelease:
+			if m.data.secondRelease == "" {
+				return fmt.Errorf("invalid release to compare to")
+			}
+		case fieldPackage:
+			if err := ValidatePackageName(m.data.npmPackage); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// formFlag is a flag of a value the form asks for when it isn't provided.
+type formFlag struct {
+	pointer *string
+	field   inputField
+	flag    string // Value of the flag, when provided
+	form    string // Value entered in the form, when not provided
+	value   func(d data) string
+}
+
+// formFlags are the flags of the values of the form, in its order.
+func formFlags() []formFlag {
+	return []formFlag{
+		{ghRepo, fieldRepository, "flag/repo", "form/repo", func(d data) string { return d.ghRepo }},
+		{firstRelease, fieldFirstRelease, "v1.0.0", "v1.1.0", func(d data) string { return d.firstRelease }},
+		{secondRelease, fieldSecondRelease, "v2.0.0", "v2.1.0", func(d data) string { return d.secondRelease }},
+		{npmPackage, fieldPackage, "flag-pkg", "form-pkg", func(d data) string { return d.npmPackage }},
+		{ignoreRegex, fieldIgnore, "flag-beta", "form-rc", func(d data) string { return d.ignoreRegex }},
+	}
+}
+
+// TestSubmitInputsFlagCombinations checks that whichever flags are provided, the form only asks for
+// the other values, and each value entered lands in its own field of the data.
+func TestSubmitInputsFlagCombinations(t *testing.T) {
+	flags := formFlags()
+	for provided := 0; provided < 1<<len(flags); provided++ {
+		t.Run(
+			fmt.Sprintf("%05b", provided), func(t *testing.T) {
+				values := make(map[*string]string)
+				var expectedFields []inputField
+				for i, flag := range flags {
+					if provided&(1<<i) != 0 {
+						values[flag.pointer] = flag.flag
+						continue
+					}
+					values[flag.pointer] = ""
+					expectedFields = append(expectedFields, flag.field)
+					if flag.field == fieldRepository {
+						// The token is asked for along with the repository
+						expectedFields = append(expectedFields, fieldToken)
+					}
+				}
+				withFlags(t, values)
+
+				// The data starts with the values of the flags, as in main
+				m := model{
+					data: data{
+						ghRepo:        *ghRepo,
+						npmPackage:    *npmPackage,
+						firstRelease:  *firstRelease,
+						secondRelease: *secondRelease,
+						ignoreRegex:   *ignoreRegex,
+					},
+				}
+				m.inputs = newInputs(m.data)
+				var fields []inputField
+				for i, input := range m.inputs {
+					fields = append(fields, input.field)
+					if i > 0 && input.Focused() {
+						t.Errorf("the input %d is focused, expected the first one only", i)
+					}
+					if input.field == fieldToken {
+						m.inputs[i].SetValue("form-token")
+					}
+					for _, flag := range flags {
+						if flag.field == input.field {
+							m.inputs[i].SetValue(flag.form)
+						}
+					}
+				}
+				if !reflect.DeepEqual(fields, expectedFields) {
+					t.Fatalf("the form asks for the fields %v, expected %v", fields, expectedFields)
+				}
+
+				if err := m.submitInputs(); err != nil {
+					t.Fatal(err)
+				}
+				for i, flag := range flags {
+					expected := flag.form
+					if provided&(1<<i) != 0 {
+						expected = flag.flag
+					}
+					if value := flag.value(m.data); value != expected {
+						t.Errorf("the field %d holds %q, expected %q", flag.field, value, expected)
+					}
+				}
+				expectedToken := ""
+				if provided&1 == 0 {
+					expectedToken = "form-token"
+				}
+				if m.data.ghToken != expectedToken {
+					t.Errorf("the token is %q, expected %q", m.data.ghToken, expectedToken)
+				}
+			},
+		)
+	}
+}
+
+// TestNewInputsResolvedToken checks that the form doesn't ask for a token already resolved from the environment.
+func TestNewInputsResolvedToken(t *testing.T) {
+	withFlags(t, map[*string]string{ghRepo: "", firstRelease: "", secondRelease: "", npmPackage: "", ignoreRegex: ""})
+	var prefill data
+	prefill.tokenSource = "GITHUB_TOKEN"
+	for _, input := range newInputs(prefill) {
+		if input.field == fieldToken {
+			t.Error("the form asks for a token already resolved")
+		}
+	}
+}
+
+func TestSubmitInputsInvalid(t *testing.T) {
+	withFlags(t, map[*string]string{ghRepo: "", firstRelease: "", secondRelease: "", npmPackage: "", ignoreRegex: ""})
+	for _, test := range []struct {
+		name   string
+		values map[inputField]string
+		err    string
+	}{
+		{"repository", map[inputField]string{fieldRepository: "repo"}, "invalid GitHub repository format. Format: owner/repo"},
+		{"base release", map[inputField]string{fieldRepository: "owner/repo"}, "invalid base release"},
+		{
+			"release to compare to", map[inputField]string{fieldRepository: "owner/repo", fieldFirstRelease: "v1.0.0"},
+			"invalid release to compare to",
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				m := model{}
+				m.inputs = newInputs(m.data)
+				for i, input := range m.inputs {
+					m.inputs[i].SetValue(test.values[input.field])
+				}
+				if err := m.submitInputs(); err == nil || err.Error() != test.err {
+					t.Errorf("got %v, expected %q", err, test.err)
+				}
+				// Every value is read even if one is invalid, to fill the form again
+				if m.data.ghRepo != test.values[fieldRepository] || m.data.firstRelease != test.values[fieldFirstRelease] {
+					t.Errorf("read %q and %q", m.data.ghRepo, m.data.firstRelease)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "fmt"
+
+// checkFormatVersion checks that a file written by writer, another version of the application,
+// uses a version of its format between oldest and current, which this version can read.
+// Files of older versions are migrated by their readers when they are read.
+func checkFormatVersion(kind, path string, version, oldest, current int, writer string) error {
+	if writer == "" {
+		writer = "an unknown version"
+	}
+	switch {
+	case version > current:
+		return fmt.Errorf(
+			"%s %s uses format version %d, written by %s %s, but this version (%s) only reads up to version %d: upgrade %s to read it",
+			kind, path, version, appDirName, writer, appVersion, current, appDirName,
+		)
+	case version < oldest:
+		return fmt.Errorf(
+			"%s %s uses format version %d, written by %s %s, which is no longer supported since version %d: create it again with this version (%s)",
+			kind, path, version, appDirName, writer, oldest, appVersion,
+		)
+	}
+	return nil
+}
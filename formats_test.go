@@ -0,0 +1,98 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// formatFixture returns the path of a file of a format version in testdata/formats.
+func formatFixture(name string) string {
+	return filepath.Join("testdata", "formats", name)
+}
+
+func TestReadBaselineFormats(t *testing.T) {
+	// The current version reads back everything it wrote
+	current, err := ReadBaseline(formatFixture("baseline-v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := testAnalysis("v1.0.0", 1000); !reflect.DeepEqual(current, expected) {
+		t.Errorf("read\n%+v\nexpected\n%+v", current, expected)
+	}
+
+	// The baselines written before the lines were counted by kind are compared by total lines
+	early, err := ReadBaseline(formatFixture("baseline-v1-early.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if early.releaseTag != "v1.0.0" || early.totalLines != 1000 || early.files["package/index.js"] != 750 {
+		t.Errorf("read %+v, expected the counts of v1.0.0", early)
+	}
+	if early.kindsCounted || early.whitespaceNormalized || early.measuredTarSize().measured {
+		t.Errorf("read %+v, expected the counts it doesn't record to be unmeasured", early)
+	}
+	if change, unit := lineChange(early, testAnalysis("v2.0.0", 1200)); change != measured(200) || unit != "lines" {
+		t.Errorf("got a change of %+v %s, expected 200 lines", change, unit)
+	}
+
+	for _, test := range []struct {
+		name string
+		err  string
+	}{
+		{"baseline-v2.json", "uses format version 2, written by npm-stats-comparator 2.0.0, but this version (" + appVersion + ") only reads up to version 1: upgrade"},
+		{"baseline-unversioned.json", "uses format version 0, written by npm-stats-comparator an unknown version, which is no longer supported since version 1"},
+	} {
+		if _, err := ReadBaseline(formatFixture(test.name)); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("reading %s: got %v, expected an error with %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestReadBundleFormats(t *testing.T) {
+	bundle, err := ReadBundle(formatFixture("bundle-v1.nsc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d data
+	d.importBundle(bundle)
+	if d.ghRepo != "owner/repo" || d.firstRelease != "v1.0.0" || d.secondRelease != "v2.0.0" || d.ignoreRegex != "beta" {
+		t.Errorf("imported %s %s..%s ignoring %q", d.ghRepo, d.firstRelease, d.secondRelease, d.ignoreRegex)
+	}
+	if tags := tagNames(d.releases); !reflect.DeepEqual(tags, []string{"v2.0.0", "v1.0.0"}) {
+		t.Errorf("imported the releases %v", tags)
+	}
+	if len(d.analysis) != 2 || d.analysis[0].totalLines != 1200 || d.analysis[1].totalLines != 1000 {
+		t.Fatalf("imported the analysis %+v", d.analysis)
+	}
+	if change, unit := lineChange(d.analysis[1], d.analysis[0]); change != measured(200) || unit != "lines" {
+		t.Errorf("got a change of %+v %s, expected 200 lines", change, unit)
+	}
+	if d.notes["v2.0.0"] != "rewrite" || d.shard.String() != "" {
+		t.Errorf("imported the notes %v and the shard %q", d.notes, d.shard)
+	}
+
+	_, err = ReadBundle(formatFixture("bundle-v2.nsc"))
+	if expected := "only reads up to version 1: upgrade"; err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("got %v, expected an error with %q", err, expected)
+	}
+}
+
+func TestCheckFormatVersion(t *testing.T) {
+	for _, test := range []struct {
+		version, oldest, current int
+		valid                    bool
+	}{
+		{1, 1, 1, true},
+		{1, 1, 3, true},
+		{3, 1, 3, true},
+		{4, 1, 3, false},
+		{1, 2, 3, false},
+	} {
+		err := checkFormatVersion("file", "path", test.version, test.oldest, test.current, "1.0.0")
+		if (err == nil) != test.valid {
+			t.Errorf("version %d of %d..%d: %v", test.version, test.oldest, test.current, err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// generatedLineLength is the average length of the lines of a file, in bytes,
+// above which the file is deemed generated, such as a bundle or a minified file.
+const generatedLineLength = 500
+
+// isGenerated returns whether a file is deemed generated or minified: a source map,
+// a `.min.` file, or a file whose lines are on average longer than generatedLineLength.
+func isGenerated(filePath string, lines uint, size int64) bool {
+	name := strings.ToLower(path.Base(filePath))
+	if strings.HasSuffix(name, ".map") || strings.Contains(name, ".min.") {
+		return true
+	}
+	if lines == 0 {
+		// A file without any line ending is a single line
+		lines = 1
+	}
+	return size/int64(lines) > generatedLineLength
+}
+
+// generatedText renders the lines of the generated and minified files of the release,
+// left out of its lines unless `--include-generated`.
+func (l ListItem) generatedText() string {
+	if l.generatedFiles == 0 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"generated/minified: %d files (%d lines, %.0f%% of all)",
+		l.generatedFiles, l.generatedLines, float64(l.generatedLines)/float64(l.generatedLines+l.totalLines)*100,
+	)
+}
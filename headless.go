@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Exit codes of the headless mode.
+const (
+	exitFailure      = 1 // The pipeline failed, or a release failed to download or analyze
+	exitUsage        = 2 // The flags are invalid or incomplete
+	exitTokenExpired = 3 // The GitHub token expired during the run
+	exitLargeFiles   = 4 // The release to compare to has more large files than --fail-if-large-files
+)
+
+// headlessWidth is the width the summary header is wrapped to in the headless mode.
+const headlessWidth = 100
+
+// runHeadless runs the pipeline without the user interface, returning the exit code of the program.
+// The commands of the model run concurrently, the downloads and the analyses being bounded by
+// the worker pool, and their messages are fed back to it one at a time as they complete.
+// The progress is printed to the progress writer, usually stderr, through a headlessLog,
+// and the final comparison to stdout.
+func runHeadless(m model, progressWriter io.Writer) int {
+	progress := newHeadlessLog(progressWriter)
+	defer progress.Close()
+	if m.err != nil {
+		progress.Println("Error:", m.err)
+		return exitUsage
+	}
+	if m.state == StateSummary {
+		// Imported comparison, nothing to run
+		progress.Close()
+		printHeadlessComparison(m)
+		printDiagnostics(m)
+		return 0
+	}
+	if m.data.baseline == nil && ((*ghRepo == "" && !*registryOnly) || *firstRelease == "" || *secondRelease == "") {
+		progress.Println("Error: --headless requires --repo, --from and --to")
+		return exitUsage
+	}
+	m.headless = true
+	defer func() {
+		// Abort the requests of the commands still running, such as after a failure
+		m.cancel()
+		waitDownloads(downloadsCleanupTimeout)
+	}()
+
+	m, cmd := m.start()
+	progress.Println(m.headlessStatus())
+	var commands headlessCommands
+	commands.run(cmd)
+	for commands.pending > 0 && m.err == nil {
+		msg := commands.next()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, cmd := range batch {
+				commands.run(cmd)
+			}
+			continue
+		}
+		if wrapped, ok := msg.(runMsg); ok && wrapped.run == m.run {
+			msg = wrapped.msg
+		}
+		switch msg := msg.(type) {
+		case nil:
+			continue
+		case tea.QuitMsg:
+			commands.pending = 0
+			continue
+		case rateLimitedMsg:
+			if wait := (errRateLimited{msg.reset}).resetWait(); wait <= autoBackoffLimit {
+				// The model waits for the reset
+				progress.Printf("Warning: GitHub API rate limit exceeded, resuming in %s\n", wait.Round(time.Second))
+				break
+			}
+			if err := SavePartialFetch(m.data.ghRepo, m.data.firstRelease, m.data.secondRelease, msg.progress); err != nil {
+				progress.Println("Warning: could not save the partial fetch:", err)
+			}
+			progress.Printf(
+				"Error: %s; a run within %.0f minutes resumes the fetching at page %d\n",
+				errRateLimited{msg.reset}, partialFetchWindow.Minutes(), msg.progress.page,
+			)
+			return exitFailure
+		case tokenExpiredMsg:
+			if err := SavePartialFetch(m.data.ghRepo, m.data.firstRelease, m.data.secondRelease, msg.progress); err != nil {
+				progress.Println("Warning: could not save the partial fetch:", err)
+			}
+			progress.Printf(
+				"Error: the GitHub token expired after %d releases fetched; a run with a new --token within %.0f minutes resumes the fetching at page %d\n",
+				len(msg.progress.releases), partialFetchWindow.Minutes(), msg.progress.page,
+			)
+			return exitTokenExpired
+		case gitReleaseDownloadedMsg:
+			if msg.err != nil {
+				progress.Printf("%s: download failed: %v\n", msg.release, msg.err)
+			} else if msg.cached {
+				progress.Printf("%s: reused\n", msg.release)
+			} else if msg.repaired {
+				progress.Printf("%s: downloaded again over an incomplete extraction\n", msg.release)
+			} else {
+				progress.Printf("%s: downloaded\n", msg.release)
+			}
+		case analysisDoneMsg:
+			if msg.failed != "" {
+				progress.Printf("%s: failed: %s\n", msg.releaseTag, msg.failed)
+			} else {
+				progress.Printf("%s: analyzed\n", msg.releaseTag)
+			}
+		}
+
+		previousState := m.state
+		updated, cmd := m.Update(msg)
+		m = updated.(model)
+		if m.state != previousState {
+			progress.Println(m.headlessStatus())
+		}
+		if m.confirmSameTarball {
+			// Nobody can confirm, the comparison goes on
+			progress.Printf("Warning: both endpoints resolve to the same tarball %s\n", m.data.planReport.SameTarball)
+			updated, next := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+			m = updated.(model)
+			cmd = tea.Batch(cmd, next)
+		}
+		commands.run(cmd)
+	}
+
+	if m.err != nil {
+		progress.Println("Error:", m.err)
+		return exitFailure
+	}
+	if m.state != StateSummary {
+		progress.Println("Error: the comparison stopped before its end")
+		return exitFailure
+	}
+	printReleaseStatuses(progress, m)
+
+	// The progress is flushed before the comparison
+	progress.Close()
+	if m.stdoutExports != nil {
+		// The JSON export replaces the comparison
+		_, _ = os.Stdout.Write(m.stdoutExports)
+	} else {
+		printHeadlessComparison(m)
+	}
+	printDiagnostics(m)
+	for _, analysis := range m.data.analysis {
+		if analysis.failed != "" {
+			return exitFailure
+		}
+	}
+	if err := m.data.checkLargeFiles(*failIfLargeFiles); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitLargeFiles
+	}
+	return 0
+}
+
+// printReleaseStatuses prints the final status of each release, from the oldest to the newest,
+// whatever the order the releases completed in, so that the logs of two runs can be compared.
+func printReleaseStatuses(progress *headlessLog, m model) {
+	progress.Println("Releases:")
+	for _, analysis := range m.data.chronological() {
+		if analysis.failed != "" {
+			progress.Printf("  %s: failed: %s\n", analysis.releaseTag, analysis.failed)
+		} else {
+			progress.Printf("  %s: analyzed\n", analysis.releaseTag)
+		}
+	}
+}
+
+// headlessCommands runs the commands of the headless mode concurrently,
+// collecting their messages in the order they complete.
+type headlessCommands struct {
+	messages chan tea.Msg
+	pending  int // Commands whose message wasn't collected yet
+}
+
+// run runs the command in the background, if any.
+func (c *headlessCommands) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if c.messages == nil {
+		c.messages = make(chan tea.Msg)
+	}
+	c.pending++
+	go func() {
+		c.messages <- cmd()
+	}()
+}
+
+// next waits for the message of the next command to complete.
+func (c *headlessCommands) next() tea.Msg {
+	c.pending--
+	return <-c.messages
+}
+
+// headlessLog serializes the progress lines of the headless mode through a single writer goroutine,
+// so that the lines are never interleaved, whoever prints them.
+type headlessLog struct {
+	lines chan string
+	done  chan struct{}
+	once  sync.Once
+}
+
+// newHeadlessLog starts the writer goroutine of the progress lines to out.
+func newHeadlessLog(out io.Writer) *headlessLog {
+	l := &headlessLog{lines: make(chan string, 64), done: make(chan struct{})}
+	go func() {
+		defer close(l.done)
+		for line := range l.lines {
+			_, _ = io.WriteString(out, line)
+		}
+	}()
+	return l
+}
+
+// Printf prints a progress line formatted like fmt.Printf.
+func (l *headlessLog) Printf(format string, args ...any) {
+	l.lines <- fmt.Sprintf(format, args...)
+}
+
+// Println prints a progress line formatted like fmt.Println.
+func (l *headlessLog) Println(args ...any) {
+	l.lines <- fmt.Sprintln(args...)
+}
+
+// Close flushes the progress lines and stops the writer goroutine. Nothing can be printed afterward.
+func (l *headlessLog) Close() {
+	l.once.Do(
+		func() {
+			close(l.lines)
+			<-l.done
+		},
+	)
+}
+
+// printHeadlessComparison prints the summary header and the releases to stdout.
+func printHeadlessComparison(m model) {
+	for _, line := range strings.Split(m.summaryHeader(headlessWidth), "\n") {
+		// The lines are padded to the width of the longest one
+		_, _ = fmt.Fprintln(os.Stdout, strings.TrimRight(line, " "))
+	}
+	for _, item := range m.items {
+		if item, ok := item.(ListItem); ok {
+			_, _ = fmt.Fprintln(os.Stdout, item.Title())
+			_, _ = fmt.Fprintln(os.Stdout, "  "+item.Description())
+		}
+	}
+}
+
+// headlessStatus describes the current state of the pipeline in a progress line.
+func (m model) headlessStatus() string {
+	switch m.state {
+	case StateChecking:
+		return fmt.Sprintf("Checking that %s and %s exist...", m.data.firstRelease, m.data.secondRelease)
+	case StateFetching:
+		if m.flow() == flowRegistryOnly {
+			return fmt.Sprintf("Fetching the versions of %s...", m.data.npmPackage)
+		}
+		return fmt.Sprintf("Fetching the releases of %s...", m.data.ghRepo)
+	case StateDownloadExtract:
+		return fmt.Sprintf("Downloading %d releases...", len(m.data.releases))
+	case StateAnalyzing:
+		return fmt.Sprintf("Analyzing %d releases...", len(m.data.releases))
+	case StateSummary:
+		return "Done."
+	default:
+		return ""
+	}
+}
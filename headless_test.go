@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestHeadlessLogSerializes checks that the lines printed concurrently are never interleaved,
+// and that all of them are written once the log is closed.
+func TestHeadlessLogSerializes(t *testing.T) {
+	const printers, lines = 20, 50
+	var out bytes.Buffer
+	progress := newHeadlessLog(&out)
+	var wg sync.WaitGroup
+	for i := 0; i < printers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < lines; j++ {
+				if j%2 == 0 {
+					progress.Printf("printer %02d line %02d %s\n", i, j, strings.Repeat("=", 200))
+				} else {
+					progress.Println(fmt.Sprintf("printer %02d line %02d", i, j), strings.Repeat("=", 200))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	progress.Close()
+	// Closing again is harmless
+	progress.Close()
+
+	written := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(written) != printers*lines {
+		t.Fatalf("got %d lines, expected %d", len(written), printers*lines)
+	}
+	for _, line := range written {
+		var i, j int
+		if n, err := fmt.Sscanf(line, "printer %d line %d", &i, &j); n != 2 || err != nil {
+			t.Fatalf("interleaved line %q", line)
+		}
+		if expected := fmt.Sprintf("printer %02d line %02d %s", i, j, strings.Repeat("=", 200)); line != expected {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
+
+func TestHeadlessLogGoldens(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		code int
+		run  func(t *testing.T, out *bytes.Buffer) int
+	}{
+		{
+			// The releases are listed from the oldest to the newest, whatever their completion order
+			"statuses", 0, func(t *testing.T, out *bytes.Buffer) int {
+				m := viewModel(StateSummary)
+				m.data.analysis = []AnalysisResult{
+					testAnalysis("v2.0.0", 1500),
+					failedAnalysis("v1.1.0", "timed out after 1m0s, 3 files processed"),
+					testAnalysis("v1.0.0", 1000),
+				}
+				progress := newHeadlessLog(out)
+				printReleaseStatuses(progress, m)
+				progress.Close()
+				return 0
+			},
+		},
+		{
+			"usage", exitUsage, func(t *testing.T, out *bytes.Buffer) int {
+				m := viewModel(StateInit)
+				m.err = errors.New("invalid --top-files -1, expected a positive number")
+				return runHeadless(m, out)
+			},
+		},
+		{
+			// The fetching stops at the second page, the first one being saved for the next run
+			"token-expired", exitTokenExpired, func(t *testing.T, out *bytes.Buffer) int {
+				// A full first page, without v1.0.0
+				tags := []string{"v2.0.0"}
+				for minor := githubReleasesPerPage - 1; minor > 0; minor-- {
+					tags = append(tags, fmt.Sprintf("v1.%d.0", minor))
+				}
+				releases := plannerReleases(tags...)
+				withFakeAPI(
+					t, http.HandlerFunc(
+						func(writer http.ResponseWriter, request *http.Request) {
+							if request.URL.Query().Get("page") != "1" {
+								http.Error(writer, "Bad credentials", http.StatusUnauthorized)
+								return
+							}
+							writer.Header().Set("Content-Type", "application/json")
+							_ = json.NewEncoder(writer).Encode(releases)
+						},
+					),
+				)
+				withFlags(t, map[*string]string{ghRepo: "owner/repo", firstRelease: "v1.0.0", secondRelease: "v2.0.0"})
+				m := viewModel(StateInit)
+				m.ctx, m.cancel = context.WithCancel(context.Background())
+				m.data.ghToken, m.data.skipCheck = "expired", true
+				code := runHeadless(m, out)
+				if partial, ok := loadPartialFetch("owner/repo", "v1.0.0", "v2.0.0"); !ok || partial.page != 2 || len(partial.releases) != len(releases) {
+					t.Errorf("saved the partial fetch %+v, expected the first page", partial)
+				}
+				return code
+			},
+		},
+		{
+			"missing-flags", exitUsage, func(t *testing.T, out *bytes.Buffer) int {
+				withFlags(t, map[*string]string{ghRepo: "owner/repo", firstRelease: "v1.0.0", secondRelease: ""})
+				return runHeadless(viewModel(StateInit), out)
+			},
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				var out bytes.Buffer
+				if code := test.run(t, &out); code != test.code {
+					t.Errorf("exited with %d, expected %d", code, test.code)
+				}
+				checkGolden(t, "headless/"+test.name+".log", out.String())
+			},
+		)
+	}
+}
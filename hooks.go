@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hookTimeout is the maximum duration of a hook command.
+const hookTimeout = 2 * time.Minute
+
+// hookDoneMsg is a message that carries the result of the completion hook.
+type hookDoneMsg struct {
+	err error
+}
+
+// shellQuote quotes a value to be safely substituted in a shell command.
+func shellQuote(value string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// RunHook runs a hook command after substituting its `{placeholder}`s
+// with the shell-quoted values. The output of the command is written
+// to the verbose log, and a non-zero exit status is returned as an error.
+func RunHook(name, command string, values map[string]string) error {
+	for placeholder, value := range values {
+		command = strings.ReplaceAll(command, "{"+placeholder+"}", shellQuote(value))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	log.Printf("[%s hook] running %s", name, command)
+	cmd.Stdout = log.Writer()
+	cmd.Stderr = log.Writer()
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s hook timed out after %s", name, hookTimeout)
+		}
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
+
+// RunCompletionHook writes the JSON export of the comparison, see EncodeJSONExport,
+// to a temporary file, and runs the completion hook with its path substituted to `{json}`.
+// The releases are exported in the given order.
+func RunCompletionHook(command string, d data, order ExportOrder, releases []AnalysisResult) tea.Cmd {
+	return func() tea.Msg {
+		content, err := EncodeJSONExport(d, order, releases)
+		if err != nil {
+			return hookDoneMsg{err}
+		}
+
+		file, err := os.CreateTemp("", "npm-stats-comparator-*.json")
+		if err != nil {
+			return hookDoneMsg{err}
+		}
+		defer func(name string) {
+			_ = os.Remove(name)
+		}(file.Name())
+		_, err = file.Write(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return hookDoneMsg{err}
+		}
+
+		return hookDoneMsg{RunHook("on-complete", command, map[string]string{"json": file.Name()})}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"net/http"
+)
+
+// defaultUserAgent identifies the application in its requests,
+// as some registry mirrors throttle anonymous clients aggressively.
+const defaultUserAgent = appDirName + "/" + appVersion + " (+https://github.com/WarningImHack3r/npm-stats-comparator)"
+
+// newRequest creates an HTTP request identified by the User-Agent
+// of the application, or the one of `--user-agent`, cancelled with the context.
+func newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	agent := defaultUserAgent
+	if *userAgent != "" {
+		agent = *userAgent
+	}
+	request.Header.Set("User-Agent", agent)
+	return request, nil
+}
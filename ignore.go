@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// IgnoreMode is how the `--ignore` pattern matches the tags of the releases to ignore.
+type IgnoreMode string
+
+const (
+	// IgnoreModeRegex matches the tags against a regular expression.
+	IgnoreModeRegex IgnoreMode = "regex"
+	// IgnoreModeSubstring matches the tags containing the pattern.
+	IgnoreModeSubstring IgnoreMode = "substring"
+	// IgnoreModeGlob matches the full tags against a path.Match pattern.
+	IgnoreModeGlob IgnoreMode = "glob"
+)
+
+// ParseIgnoreMode parses the value of the `--ignore-mode` flag.
+func ParseIgnoreMode(value string) (IgnoreMode, error) {
+	switch mode := IgnoreMode(value); mode {
+	case IgnoreModeRegex, IgnoreModeSubstring, IgnoreModeGlob:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid ignore mode %q, expected %s, %s or %s",
+			value, IgnoreModeRegex, IgnoreModeSubstring, IgnoreModeGlob,
+		)
+	}
+}
+
+// compileIgnore compiles the ignore pattern in the given mode into a function
+// returning whether a tag is ignored. An empty pattern ignores nothing.
+func compileIgnore(mode IgnoreMode, pattern string) (func(tag string) bool, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	switch mode {
+	case IgnoreModeSubstring:
+		return func(tag string) bool {
+			return strings.Contains(tag, pattern)
+		}, nil
+	case IgnoreModeGlob:
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		return func(tag string) bool {
+			matched, _ := path.Match(pattern, tag)
+			return matched
+		}, nil
+	default:
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return regex.MatchString, nil
+	}
+}
+
+// placeholder returns the placeholder of the ignore input in the mode.
+func (m IgnoreMode) placeholder() string {
+	switch m {
+	case IgnoreModeSubstring:
+		return "Substring of the releases names to ignore (optional)"
+	case IgnoreModeGlob:
+		return "Glob of the releases names to ignore (optional)"
+	default:
+		return "Regex to ignore releases names (optional)"
+	}
+}
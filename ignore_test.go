@@ -0,0 +1,127 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// ignoreTags are the tags the ignore patterns of every mode are matched against, the newest first.
+var ignoreTags = []string{
+	"v2.0.0",
+	"v2.0.0-rc.1",
+	"v2.0.0-beta.1",
+	"v1.1.0",
+	"v1.1.0-rc.2",
+	"v1.0.1",
+	"pkg@1.0.0",
+	"v1.0.0",
+}
+
+func TestCompileIgnore(t *testing.T) {
+	for _, test := range []struct {
+		mode    IgnoreMode
+		pattern string
+		ignored []string
+	}{
+		{IgnoreModeRegex, "", nil},
+		{IgnoreModeRegex, "-rc\\.", []string{"v2.0.0-rc.1", "v1.1.0-rc.2"}},
+		{IgnoreModeRegex, "-(rc|beta)", []string{"v2.0.0-rc.1", "v2.0.0-beta.1", "v1.1.0-rc.2"}},
+		{IgnoreModeRegex, "^v1\\.", []string{"v1.1.0", "v1.1.0-rc.2", "v1.0.1", "v1.0.0"}},
+		// The dot of a regular expression matches any character
+		{IgnoreModeRegex, "0.rc", []string{"v2.0.0-rc.1", "v1.1.0-rc.2"}},
+		{IgnoreModeSubstring, "", nil},
+		{IgnoreModeSubstring, "-rc.", []string{"v2.0.0-rc.1", "v1.1.0-rc.2"}},
+		{IgnoreModeSubstring, "0.rc", nil},
+		{IgnoreModeSubstring, "1.0", []string{"v1.1.0", "v1.1.0-rc.2", "v1.0.1", "pkg@1.0.0", "v1.0.0"}},
+		// The metacharacters of regular expressions are literal
+		{IgnoreModeSubstring, "-(rc|beta)", nil},
+		{IgnoreModeSubstring, "pkg@", []string{"pkg@1.0.0"}},
+		{IgnoreModeGlob, "", nil},
+		{IgnoreModeGlob, "*-rc.*", []string{"v2.0.0-rc.1", "v1.1.0-rc.2"}},
+		{IgnoreModeGlob, "v1.*", []string{"v1.1.0", "v1.1.0-rc.2", "v1.0.1", "v1.0.0"}},
+		{IgnoreModeGlob, "v?.0.0", []string{"v2.0.0", "v1.0.0"}},
+		{IgnoreModeGlob, "v2.0.0-[br]*", []string{"v2.0.0-rc.1", "v2.0.0-beta.1"}},
+		// The pattern matches the full tag, not a part of it
+		{IgnoreModeGlob, "rc", nil},
+	} {
+		t.Run(
+			string(test.mode)+" "+test.pattern, func(t *testing.T) {
+				ignore, err := compileIgnore(test.mode, test.pattern)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var ignored []string
+				for _, tag := range ignoreTags {
+					if ignore != nil && ignore(tag) {
+						ignored = append(ignored, tag)
+					}
+				}
+				if !reflect.DeepEqual(ignored, test.ignored) {
+					t.Errorf("ignored %v, expected %v", ignored, test.ignored)
+				}
+
+				// The planner keeps the endpoints, and ignores the same releases between them
+				plan, report, err := planReleases(
+					plannerReleases(ignoreTags...),
+					PlanOptions{From: "v1.0.0", To: "v2.0.0", Ignore: ignore},
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var kept []string
+				for _, tag := range ignoreTags {
+					if tag == "v1.0.0" || tag == "v2.0.0" || !slices.Contains(test.ignored, tag) {
+						kept = append(kept, tag)
+					}
+				}
+				if tags := tagNames(plan); !reflect.DeepEqual(tags, kept) {
+					t.Errorf("planned %v, expected %v", tags, kept)
+				}
+				if report.IgnoredByRegex != len(ignoreTags)-len(kept) {
+					t.Errorf("reported %d ignored releases, expected %d", report.IgnoredByRegex, len(ignoreTags)-len(kept))
+				}
+			},
+		)
+	}
+}
+
+func TestCompileIgnoreInvalid(t *testing.T) {
+	for _, test := range []struct {
+		mode    IgnoreMode
+		pattern string
+	}{
+		{IgnoreModeRegex, "-(rc"},
+		{IgnoreModeRegex, "*"},
+		{IgnoreModeGlob, "v[1"},
+		{IgnoreModeGlob, "v1\\"},
+	} {
+		if _, err := compileIgnore(test.mode, test.pattern); err == nil {
+			t.Errorf("the %s %q compiled", test.mode, test.pattern)
+		}
+	}
+	// Any pattern is a valid substring
+	if _, err := compileIgnore(IgnoreModeSubstring, "v[1"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseIgnoreMode(t *testing.T) {
+	placeholders := make(map[string]IgnoreMode)
+	for _, mode := range []IgnoreMode{IgnoreModeRegex, IgnoreModeSubstring, IgnoreModeGlob} {
+		parsed, err := ParseIgnoreMode(string(mode))
+		if err != nil || parsed != mode {
+			t.Errorf("ParseIgnoreMode(%q) = %q, %v", mode, parsed, err)
+		}
+		// The placeholder of the input tells the mode apart
+		if other, ok := placeholders[mode.placeholder()]; ok {
+			t.Errorf("the %s and %s modes have the same placeholder", mode, other)
+		}
+		placeholders[mode.placeholder()] = mode
+	}
+	for _, value := range []string{"", "Regex", "literal"} {
+		if _, err := ParseIgnoreMode(value); err == nil {
+			t.Errorf("ParseIgnoreMode(%q) succeeded", value)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+)
+
+// jsonSchemaVersion is the version of the schema of the JSON export,
+// incremented on every breaking change. Version 2 is also the summary passed to the completion hook.
+const jsonSchemaVersion = 2
+
+// stdoutPath is the path of the exports written to stdout, such as with `--json -`.
+const stdoutPath = "-"
+
+// JSONExport is the JSON export of the analysis results, see `--json`,
+// also passed to the completion hook, see `--on-complete`.
+type JSONExport struct {
+	Metadata JSONMetadata  `json:"metadata"`
+	Releases []JSONRelease `json:"releases"`
+	Deltas   []JSONDelta   `json:"deltas"`
+	Anchors  []JSONAnchor  `json:"anchors"` // Comparisons of the compared release against its anchors
+	Cadence  CadenceStats  `json:"cadence"`
+	Spikes   []JSONSpike   `json:"spikes"` // Biggest jumps between consecutive releases, see --spikes
+}
+
+// JSONMetadata describes what produced the JSON export.
+type JSONMetadata struct {
+	SchemaVersion int         `json:"schema_version"`
+	Tool          string      `json:"tool"`
+	ToolVersion   string      `json:"tool_version"`
+	Repository    string      `json:"repository"`
+	From          string      `json:"from"`
+	To            string      `json:"to"`
+	Order         ExportOrder `json:"order"`    // Order of the releases
+	Settings      string      `json:"settings"` // Description of the analysis settings, see AnalysisSettings
+}
+
+// JSONRelease is the analysis result of a release in the JSON export.
+// Metrics that were not measured are null.
+type JSONRelease struct {
+	Tag              string             `json:"tag"`
+	TotalLines       measure[int]       `json:"total_lines"`
+	TotalFiles       measure[int]       `json:"total_files"`
+	LinesByLanguage  map[string]uint    `json:"lines_by_language"`
+	LinesByExtension map[string]uint    `json:"lines_by_extension"` // Lines by lowercase file extension, "" for files without one
+	ESMFiles         uint               `json:"esm_files"`          // JavaScript files using ES modules
+	CJSFiles         uint               `json:"cjs_files"`          // JavaScript files using CommonJS
+	TarSize          measure[int64]     `json:"tar_size"`
+	DirSize          measure[int64]     `json:"dir_size"`
+	LinesPerKB       measure[float64]   `json:"lines_per_kb"`     // Lines per unpacked kilobyte
+	CRLFRatio        measure[float64]   `json:"crlf_ratio"`       // Ratio of the lines ending with \r\n
+	LargeFiles       uint               `json:"large_files"`      // Files larger than --large-file-size
+	NormalizedLines  measure[int]       `json:"normalized_lines"` // Lines once whitespace normalized, with --ignore-whitespace
+	CodeLines        measure[int]       `json:"code_lines"`       // Lines with code, null if not counted by kind
+	CommentLines     measure[int]       `json:"comment_lines"`    // Lines with nothing but comments
+	BlankLines       measure[int]       `json:"blank_lines"`
+	OtherExtensions  map[string]uint    `json:"other_extensions,omitempty"` // Lines of no known language by file extension
+	GeneratedLines   uint               `json:"generated_lines"`            // Lines of the generated and minified files, left out of the total
+	Approximation    *JSONApproximation `json:"approximation"`              // Approximation of the counts with --top-files, null if exact
+	Engines          map[string]string  `json:"engines,omitempty"`          // Supported versions of the engines, from the root manifest
+	PackageManager   string             `json:"package_manager,omitempty"`  // Package manager of the package, from the root manifest
+	Note             string             `json:"note,omitempty"`             // Note of the user about the release
+	Failed           string             `json:"failed,omitempty"`           // Reason of the failure of the analysis
+}
+
+// JSONApproximation is the approximation of the counts of a release analyzed with `--top-files`.
+type JSONApproximation struct {
+	TopFiles uint    `json:"top_files"` // Number of largest files analyzed
+	Coverage float64 `json:"coverage"`  // Ratio of the bytes of the release covered by the analyzed files
+}
+
+// JSONAnchor is the comparison of the compared release against an anchor in the JSON export.
+type JSONAnchor struct {
+	Label     string       `json:"label"`
+	Tag       string       `json:"tag"`
+	LinesDiff measure[int] `json:"lines_diff"` // Change of lines in the unit, see lineChange
+	Unit      string       `json:"unit"`       // "code lines", or "lines" if either release wasn't counted by kind
+}
+
+// JSONDelta is the difference between two consecutive releases in the JSON export.
+// Differences involving a metric that was not measured are null.
+type JSONDelta struct {
+	From       string               `json:"from"`
+	To         string               `json:"to"`
+	TotalLines measure[int]         `json:"total_lines"`
+	CodeLines  measure[int]         `json:"code_lines"` // Null if either release wasn't counted by kind
+	TotalFiles measure[int]         `json:"total_files"`
+	TarSize    measure[int64]       `json:"tar_size"`
+	DirSize    measure[int64]       `json:"dir_size"`
+	TopFile    *JSONFileAttribution `json:"top_file"` // File whose lines changed the most, null if none changed
+}
+
+// JSONSpike is one of the biggest changes of a metric between two consecutive releases in the JSON export.
+type JSONSpike struct {
+	Metric string `json:"metric"` // lines or tar_size
+	Rank   int    `json:"rank"`   // Rank among the changes of the metric, from 1 for the biggest
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Delta  int64  `json:"delta"`
+}
+
+// jsonSpikes returns the JSON export of the spikes, ranked per metric.
+func jsonSpikes(spikes []spike) []JSONSpike {
+	exported := make([]JSONSpike, len(spikes))
+	ranks := make(map[string]int)
+	for i, s := range spikes {
+		ranks[s.metric.name]++
+		exported[i] = JSONSpike{s.metric.name, ranks[s.metric.name], s.from, s.to, s.delta}
+	}
+	return exported
+}
+
+// jsonRelease returns the JSON export of an analysis result, with the note of the user about the release.
+func jsonRelease(analysis AnalysisResult, note string) JSONRelease {
+	var approximation *JSONApproximation
+	if analysis.topFiles > 0 {
+		approximation = &JSONApproximation{TopFiles: analysis.topFiles, Coverage: analysis.coverage}
+	}
+	return JSONRelease{
+		Tag:              analysis.releaseTag,
+		TotalLines:       analysis.measuredLines(),
+		TotalFiles:       analysis.measuredFiles(),
+		LinesByLanguage:  analysis.linesByLanguage,
+		LinesByExtension: analysis.linesByExt,
+		ESMFiles:         analysis.esmFiles,
+		CJSFiles:         analysis.cjsFiles,
+		TarSize:          analysis.measuredTarSize(),
+		DirSize:          analysis.measuredDirSize(),
+		LinesPerKB:       analysis.measuredDensity(),
+		CRLFRatio:        analysis.measuredCRLFRatio(),
+		LargeFiles:       analysis.largeFileCount,
+		NormalizedLines:  analysis.measuredNormalizedLines(),
+		CodeLines:        analysis.measuredCodeLines(),
+		CommentLines:     analysis.measuredLineKind(analysis.lineKinds.Comment),
+		BlankLines:       analysis.measuredLineKind(analysis.lineKinds.Blank),
+		OtherExtensions:  analysis.otherByExt,
+		GeneratedLines:   analysis.generatedLines,
+		Approximation:    approximation,
+		Engines:          analysis.engines,
+		PackageManager:   analysis.packageManager,
+		Note:             note,
+		Failed:           analysis.failed,
+	}
+}
+
+// EncodeJSONExport encodes the analysis results in the given order, and the deltas
+// between consecutive releases, always from the oldest to the newest.
+func EncodeJSONExport(d data, order ExportOrder, releases []AnalysisResult) ([]byte, error) {
+	export := JSONExport{
+		Metadata: JSONMetadata{
+			SchemaVersion: jsonSchemaVersion,
+			Tool:          appDirName,
+			ToolVersion:   appVersion,
+			Repository:    d.ghRepo,
+			From:          d.firstRelease,
+			To:            d.secondRelease,
+			Order:         order,
+			Settings:      d.settingsLine(),
+		},
+		Releases: make([]JSONRelease, len(releases)),
+		Deltas:   []JSONDelta{},
+		Anchors:  make([]JSONAnchor, len(d.anchors)),
+		Cadence:  d.cadence,
+		Spikes:   jsonSpikes(d.biggestJumps(d.spikeCount)),
+	}
+	for i, analysis := range releases {
+		export.Releases[i] = jsonRelease(analysis, d.notes[analysis.releaseTag])
+	}
+	for i, a := range d.anchors {
+		_, other, diff, unit := d.anchorDiff(a)
+		export.Anchors[i] = JSONAnchor{Label: a.label, Tag: other.releaseTag, LinesDiff: diff, Unit: unit}
+	}
+
+	chronological := d.chronological()
+	for i := 1; i < len(chronological); i++ {
+		previous, current := chronological[i-1], chronological[i]
+		export.Deltas = append(
+			export.Deltas, JSONDelta{
+				From:       previous.releaseTag,
+				To:         current.releaseTag,
+				TotalLines: current.measuredLines().since(previous.measuredLines()),
+				CodeLines:  current.measuredCodeLines().since(previous.measuredCodeLines()),
+				TotalFiles: current.measuredFiles().since(previous.measuredFiles()),
+				TarSize:    current.measuredTarSize().since(previous.measuredTarSize()),
+				DirSize:    current.measuredDirSize().since(previous.measuredDirSize()),
+				TopFile:    jsonTopFile(previous, current, d.dominantFileThreshold),
+			},
+		)
+	}
+
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// languageFilterThresholds are the thresholds, in lines, the language filter cycles through.
+var languageFilterThresholds = []int{0, 10, 100, 1000, 10000}
+
+// languageFilter restricts the summary list to the releases where the lines
+// of a language changed from the previous release by more than a threshold.
+type languageFilter struct {
+	language  string
+	threshold int // Change of lines to exceed
+}
+
+// matches returns whether the lines of the language changed by more than the threshold
+// from the previous release. The oldest release and failed analyses never match,
+// as their change is not measured.
+func (f languageFilter) matches(l ListItem) bool {
+	if l.previous == nil || l.failed != "" || l.previous.failed != "" {
+		return false
+	}
+	diff := int(l.linesByLanguage[f.language]) - int(l.previous.linesByLanguage[f.language])
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > f.threshold
+}
+
+// String describes the filter, e.g. "TypeScript changed by more than 100 lines".
+func (f languageFilter) String() string {
+	if f.threshold == 0 {
+		return f.language + " changed"
+	}
+	return fmt.Sprintf("%s changed by more than %d lines", f.language, f.threshold)
+}
+
+// languagePicker is the overlay choosing the language filter of the summary list.
+type languagePicker struct {
+	languages []string // Languages of the analyzed releases, sorted
+	cursor    int      // Index of the highlighted language
+	threshold int      // Index of the threshold in languageFilterThresholds
+}
+
+// newLanguagePicker creates a language picker highlighting the language of the current filter,
+// or else the language whose lines changed the most in the selected release.
+func newLanguagePicker(results []AnalysisResult, selected *ListItem, current *languageFilter) languagePicker {
+	var picker languagePicker
+	for _, result := range results {
+		for language := range result.linesByLanguage {
+			if !slices.Contains(picker.languages, language) {
+				picker.languages = append(picker.languages, language)
+			}
+		}
+	}
+	slices.Sort(picker.languages)
+
+	highlighted := ""
+	if current != nil {
+		highlighted = current.language
+		if index := slices.Index(languageFilterThresholds, current.threshold); index != -1 {
+			picker.threshold = index
+		}
+	} else if selected != nil && selected.previous != nil {
+		biggest := -1
+		for _, language := range picker.languages {
+			diff := int(selected.linesByLanguage[language]) - int(selected.previous.linesByLanguage[language])
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > biggest {
+				highlighted, biggest = language, diff
+			}
+		}
+	}
+	if index := slices.Index(picker.languages, highlighted); index != -1 {
+		picker.cursor = index
+	}
+	return picker
+}
+
+// filter returns the filter of the highlighted language, and false without any language.
+func (p languagePicker) filter() (languageFilter, bool) {
+	if len(p.languages) == 0 {
+		return languageFilter{}, false
+	}
+	return languageFilter{p.languages[p.cursor], languageFilterThresholds[p.threshold]}, true
+}
+
+// view renders the language picker, with the highlighted language and the threshold.
+func (p languagePicker) view() string {
+	rows := make([]string, len(p.languages))
+	for i, language := range p.languages {
+		if i == p.cursor {
+			rows[i] = svelteText.Render("> " + language)
+		} else {
+			rows[i] = "  " + language
+		}
+	}
+	if len(rows) == 0 {
+		rows = []string{blurredStyle.Render("  No language to filter on")}
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		svelteBg.Padding(0, 1).Render("Filter releases where a language changed"),
+		"",
+		strings.Join(rows, "\n"),
+		"",
+		fmt.Sprintf("Threshold: more than %d lines", languageFilterThresholds[p.threshold]),
+		"",
+		blurredStyle.Render("↑/↓ move • +/- threshold • enter apply • x clear • esc close"),
+	)
+}
+
+// visibleItems returns the summary list items matching the language filter if any,
+// pinned and endpoint releases being always visible.
+func (m model) visibleItems() []list.Item {
+	if m.languageFilter == nil {
+		return m.items
+	}
+	var visible []list.Item
+	for _, item := range m.items {
+		if item, ok := item.(ListItem); ok && (item.pinned || item.endpoint != noEndpoint || m.languageFilter.matches(item)) {
+			visible = append(visible, item)
+		}
+	}
+	return visible
+}
+
+// listTitle returns the title of the summary list, with its sort unless the default one
+// and the language filter if any.
+func (m model) listTitle() string {
+	if m.liveSummary {
+		return fmt.Sprintf("Releases comparison — %d/%d analyzed", m.analyzedCount(), len(m.data.releases))
+	}
+	title := "Releases comparison"
+	if !m.sort.isDefault() {
+		title += " · " + m.sort.String()
+	}
+	if m.languageFilter != nil {
+		title += " · " + m.languageFilter.String()
+	}
+	return title
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-enry/go-enry/v2"
+)
+
+// ParseLangMap parses the value of the `--lang-map` flag, formatted as
+// comma-separated `.ext=Language` pairs, e.g. `.svelte=Svelte,.wxt=Config`.
+// An empty language excludes the files of the extension from the analysis.
+func ParseLangMap(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		extension, language, found := strings.Cut(strings.TrimSpace(pair), "=")
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if !found || !strings.HasPrefix(extension, ".") || len(extension) < 2 || strings.Count(extension, ".") > 1 {
+			return nil, fmt.Errorf("invalid language mapping %q. Format: .ext=Language", pair)
+		}
+		if _, ok := overrides[extension]; ok {
+			return nil, fmt.Errorf("extension %s is mapped more than once", extension)
+		}
+		overrides[extension] = strings.TrimSpace(language)
+	}
+	return overrides, nil
+}
+
+// contentHeadSize is the number of bytes at the start of a file the language detection looks at.
+const contentHeadSize = 4 << 10
+
+// contentHead is an io.Writer keeping the first bytes written to it, up to contentHeadSize,
+// to detect the language of a file while its lines are counted.
+type contentHead []byte
+
+func (h *contentHead) Write(p []byte) (int, error) {
+	if missing := contentHeadSize - len(*h); missing > 0 {
+		if len(p) < missing {
+			missing = len(p)
+		}
+		*h = append(*h, p[:missing]...)
+	}
+	return len(p), nil
+}
+
+// detectLanguage returns the Linguist language of a file from its path and the head of its content,
+// or an empty string if unknown. Known filenames such as `Makefile` come first, then the extensions,
+// case-insensitively and including multi-dot ones such as `.d.ts`. The content only decides between
+// the candidates of an ambiguous extension, or classifies the files without a known extension by their shebang.
+// Unlike enry.GetLanguage, a shebang doesn't override a known extension.
+func detectLanguage(path string, head contentHead) string {
+	if language, safe := enry.GetLanguageByFilename(path); safe {
+		return language
+	}
+	candidates := enry.GetLanguagesByExtension(path, nil, nil)
+	switch len(candidates) {
+	case 0:
+		language, _ := enry.GetLanguageByShebang(head)
+		return language
+	case 1:
+		return candidates[0]
+	}
+	if enry.IsBinary(head) {
+		return ""
+	}
+	if languages := enry.GetLanguagesByContent(path, head, candidates); len(languages) == 1 {
+		return languages[0]
+	}
+	language, _ := enry.GetLanguageByClassifier(head, candidates)
+	return language
+}
+
+// formatLangMap formats language overrides in the `--lang-map` syntax,
+// sorted by extension.
+func formatLangMap(overrides map[string]string) string {
+	pairs := make([]string, 0, len(overrides))
+	for extension, language := range overrides {
+		pairs = append(pairs, extension+"="+language)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
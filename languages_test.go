@@ -0,0 +1,94 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLangMap(t *testing.T) {
+	for _, test := range []struct {
+		value     string
+		overrides map[string]string // nil if invalid
+	}{
+		{"", nil},
+		{".svelte=Svelte,.wxt=Config", map[string]string{".svelte": "Svelte", ".wxt": "Config"}},
+		{" .SVELTE = Svelte , .md= ", map[string]string{".svelte": "Svelte", ".md": ""}},
+		{".md=", map[string]string{".md": ""}},
+	} {
+		overrides, err := ParseLangMap(test.value)
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(overrides, test.overrides) {
+			t.Errorf("%q: parsed %v, expected %v", test.value, overrides, test.overrides)
+		}
+	}
+
+	for _, value := range []string{
+		"svelte=Svelte",       // No leading dot
+		".svelte",             // No language
+		"=Svelte",             // No extension
+		".=Svelte",            // Only a dot
+		".d.ts=TypeScript",    // Two-dot extension
+		".svelte=Svelte,",     // Empty pair
+		".js=JS,.JS=Other",    // Mapped twice, whatever the case
+		".svelte:Svelte,.a=b", // Wrong separator
+	} {
+		if overrides, err := ParseLangMap(value); err == nil {
+			t.Errorf("%q: parsed %v, expected an error", value, overrides)
+		}
+	}
+}
+
+func TestAnalysisSettingsLanguage(t *testing.T) {
+	settings := AnalysisSettings{LangMap: map[string]string{".svelte": "Svelte", ".js": "TypeScript", ".map": "", ".md": ""}}
+	for _, test := range []struct {
+		path     string
+		head     string
+		language string
+	}{
+		// The overrides win over Linguist and extToLang, whatever the case of the extension
+		{"src/App.svelte", "<script></script>\n", "Svelte"},
+		{"index.JS", "export default 1;\n", "TypeScript"},
+		{"index.js.map", "{}", ""},
+		{"README.md", "# pkg\n", ""},
+		// The other extensions are detected as usual
+		{"index.ts", "export default 1;\n", "TypeScript"},
+		{"lib/module.wasm", "\x00asm", "WebAssembly"},
+		{"bin/cli", "#!/usr/bin/env node\n", "JavaScript"},
+		{"data.unknownext", "?", otherLanguage},
+	} {
+		if language := settings.language(test.path, contentHead(test.head)); language != test.language {
+			t.Errorf("%s: got %q, expected %q", test.path, language, test.language)
+		}
+	}
+	if language := (AnalysisSettings{}).language("index.js.map", contentHead("{}")); language != "Source Map" {
+		t.Errorf("index.js.map: got %q without overrides, expected Source Map", language)
+	}
+}
+
+// TestLangMapExcludes checks that the files of an extension mapped to no language are left out
+// of the lines and the languages of the release, and counted as excluded.
+func TestLangMapExcludes(t *testing.T) {
+	settings := AnalysisSettings{LangMap: map[string]string{".md": ""}}
+	result := newAnalysisResult("pkg@1.0.0")
+	for path, content := range map[string]string{
+		"package/index.js":  "export default 1;\n",
+		"package/README.md": "# pkg\n\nA package.\n",
+	} {
+		if err := result.addFile(path, strings.NewReader(content), settings); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result.totalFiles != 1 || result.totalLines != 1 {
+		t.Errorf("counted %d files and %d lines, expected only index.js", result.totalFiles, result.totalLines)
+	}
+	if result.excludedFiles != 1 || result.excludedLines != 3 {
+		t.Errorf("excluded %d files and %d lines, expected README.md", result.excludedFiles, result.excludedLines)
+	}
+	if _, ok := result.linesByLanguage["Markdown"]; ok {
+		t.Errorf("README.md counted as Markdown: %v", result.linesByLanguage)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// defaultLargeFileSize is the size above which a file is counted as large, see `--large-file-size`.
+const defaultLargeFileSize = 1_000_000
+
+// maxLargeFiles is the number of largest files recorded per release among the large ones.
+const maxLargeFiles = 5
+
+// addLargeFile counts the file if it is larger than the threshold of the settings,
+// keeping the largest ones. Excluded files are counted too, as large fixtures
+// usually hide in the test directories.
+func (a *AnalysisResult) addLargeFile(path string, size int64, settings AnalysisSettings) {
+	if settings.LargeFileSize <= 0 || size <= settings.LargeFileSize {
+		return
+	}
+	a.largeFileCount++
+	a.largeFiles = append(a.largeFiles, fileSize{path, size})
+	sortLargestFirst(a.largeFiles)
+	if len(a.largeFiles) > maxLargeFiles {
+		a.largeFiles = a.largeFiles[:maxLargeFiles]
+	}
+}
+
+// sortLargestFirst sorts the files from the largest, ties being broken by path.
+func sortLargestFirst(files []fileSize) {
+	slices.SortFunc(
+		files, func(a, b fileSize) int {
+			if c := cmp.Compare(b.size, a.size); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.path, b.path)
+		},
+	)
+}
+
+// largeFilesText renders the largest of the large files of the release.
+func (l ListItem) largeFilesText() string {
+	if l.largeFileCount == 0 {
+		return ""
+	}
+	files := make([]string, len(l.largeFiles))
+	for i, file := range l.largeFiles {
+		files[i] = fmt.Sprintf("%s (%s)", file.path, formatBytes(float64(file.size)))
+	}
+	text := "large files: " + strings.Join(files, ", ")
+	if hidden := l.largeFileCount - uint(len(l.largeFiles)); hidden > 0 {
+		text += fmt.Sprintf(" and %d more", hidden)
+	}
+	return text
+}
+
+// checkLargeFiles checks that the release to compare to has at most limit large files,
+// see `--fail-if-large-files`. A negative limit disables the check.
+func (d data) checkLargeFiles(limit int) error {
+	if limit < 0 {
+		return nil
+	}
+	for _, analysis := range d.analysis {
+		if analysis.releaseTag == d.secondRelease && analysis.largeFileCount > uint(limit) {
+			return fmt.Errorf(
+				"%s has %d files larger than %s, more than the %d allowed by --fail-if-large-files",
+				analysis.releaseTag, analysis.largeFileCount, formatBytes(float64(d.largeFileSize)), limit,
+			)
+		}
+	}
+	return nil
+}
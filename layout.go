@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statusBarHeight is the number of lines of the status bar.
+const statusBarHeight = 1
+
+// statusBarStateStyle is the style of the state name in the status bar.
+var statusBarStateStyle = svelteBg.Padding(0, 1)
+
+// now returns the current time of the clock of the model, the wall clock unless pinned,
+// so that the views render the same whenever they are rendered.
+func (m model) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock()
+}
+
+// elapsed returns the time elapsed since the pipeline started,
+// frozen once the summary is reached, or 0 if it didn't start.
+func (m model) elapsed() time.Duration {
+	if m.startedAt.IsZero() {
+		return 0
+	}
+	if !m.finishedAt.IsZero() {
+		return m.finishedAt.Sub(m.startedAt)
+	}
+	return m.now().Sub(m.startedAt)
+}
+
+// phaseProgress returns the progress of the active phase as a fraction,
+// or an empty string if the phase has no measurable progress.
+func (m model) phaseProgress() string {
+	switch m.state {
+	case StateChecking:
+		return fmt.Sprintf("%d/2", len(m.releaseChecks))
+	case StateDownloadExtract:
+		return fmt.Sprintf("%d/%d", m.downloadProgress, len(m.data.releases))
+	case StateAnalyzing:
+		return fmt.Sprintf("%d/%d", m.analyzedCount(), len(m.data.releases))
+	case StateSummary:
+		return fmt.Sprintf("%d releases", len(m.data.analysis))
+	default:
+		return ""
+	}
+}
+
+// truncate shortens a plain text to the given width, ending it with an ellipsis if needed.
+func truncate(text string, width int) string {
+	if lipgloss.Width(text) <= width {
+		return text
+	}
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// statusBar renders the one-line status bar: the current state, the repository,
+// the endpoints, the elapsed time and the progress of the active phase,
+// truncated to the width of the window.
+func (m model) statusBar() string {
+	state := m.state.String()
+	if m.err != nil {
+		state = "error"
+	}
+
+	var parts []string
+	if subject := m.data.ghRepo; subject != "" {
+		parts = append(parts, subject)
+	} else if m.data.localDir != "" {
+		parts = append(parts, m.data.localDir)
+	}
+	if m.data.firstRelease != "" || m.data.secondRelease != "" {
+		parts = append(parts, fmt.Sprintf("%s → %s", m.data.firstRelease, m.data.secondRelease))
+	}
+	if elapsed := m.elapsed(); elapsed > 0 {
+		parts = append(parts, elapsed.Round(time.Second).String())
+	}
+	if progress := m.phaseProgress(); progress != "" {
+		parts = append(parts, progress)
+	}
+
+	badge := statusBarStateStyle.Render(state)
+	text := " " + strings.Join(parts, " • ")
+	if m.width > 0 {
+		if lipgloss.Width(badge) > m.width {
+			return truncate(state, m.width)
+		}
+		text = truncate(text, m.width-lipgloss.Width(badge))
+	}
+	return badge + blurredStyle.Render(text)
+}
+
+// layout composes a view: its body fills the window, with the status bar
+// pinned to the bottom, above the help footer of the view if any.
+func (m model) layout(body, help string) string {
+	footer := m.statusBar()
+	if help != "" {
+		footer = lipgloss.JoinVertical(lipgloss.Left, footer, help)
+	}
+	if m.height > 0 {
+		bodyHeight := m.height - lipgloss.Height(footer)
+		if bodyHeight < 0 {
+			bodyHeight = 0
+		}
+		body = lipgloss.NewStyle().Height(bodyHeight).MaxHeight(bodyHeight).Render(body)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, body, footer)
+}
+
+// resizeSummary fits the summary lists within the window,
+// below the summary header and above the status bar.
+func (m *model) resizeSummary() {
+	if m.list == nil || m.width == 0 || m.height == 0 {
+		return
+	}
+	h, v := docStyle.GetFrameSize()
+	width := m.width - h
+	height := m.height - v - lipgloss.Height(m.summaryHeader(width)) - statusBarHeight
+	if m.noteInput != nil {
+		height-- // The note input is shown below the status bar
+	}
+	m.list.SetSize(width, height)
+	if m.files != nil {
+		m.files.SetSize(width, height)
+	}
+	if m.detail != nil {
+		m.detail.viewport.Width, m.detail.viewport.Height = width, height-detailChromeHeight
+		if m.detail.viewport.Height < 1 {
+			m.detail.viewport.Height = 1
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import "fmt"
+
+// lineEnding is the dominant line ending of a release.
+type lineEnding string
+
+const (
+	lineEndingNone lineEnding = ""     // No line at all, e.g. an empty or failed release
+	lineEndingLF   lineEnding = "LF"   // Mostly bare \n line endings
+	lineEndingCRLF lineEnding = "CRLF" // Mostly \r\n line endings
+)
+
+// dominantLineEnding returns the line ending of most of the lines of the release.
+// Ties go to LF, as it is what npm packages mostly ship.
+func (a AnalysisResult) dominantLineEnding() lineEnding {
+	switch {
+	case a.lfLines+a.crlfLines == 0:
+		return lineEndingNone
+	case a.crlfLines > a.lfLines:
+		return lineEndingCRLF
+	default:
+		return lineEndingLF
+	}
+}
+
+// measuredCRLFRatio returns the ratio of the lines ending with \r\n,
+// not measured if the analysis failed or if the release has no line.
+func (a AnalysisResult) measuredCRLFRatio() measure[float64] {
+	total := a.lfLines + a.crlfLines
+	if a.failed != "" || total == 0 {
+		return measure[float64]{}
+	}
+	return measured(float64(a.crlfLines) / float64(total))
+}
+
+// lineEndingFlip returns the dominant line endings of the previous and current releases
+// and whether they differ, as a flip usually comes from a build machine or
+// a .gitattributes change rather than from the code itself.
+func lineEndingFlip(previous, current AnalysisResult) (lineEnding, lineEnding, bool) {
+	if previous.failed != "" || current.failed != "" {
+		return lineEndingNone, lineEndingNone, false
+	}
+	from, to := previous.dominantLineEnding(), current.dominantLineEnding()
+	return from, to, from != lineEndingNone && to != lineEndingNone && from != to
+}
+
+// lineEndingsText renders the line ending composition of the release,
+// with the previous dominant line ending if it flipped.
+// Releases with LF line endings only show nothing, unless they flipped.
+func (l ListItem) lineEndingsText() string {
+	var from lineEnding
+	flipped := false
+	if l.previous != nil {
+		from, _, flipped = lineEndingFlip(l.previous.AnalysisResult, l.AnalysisResult)
+	}
+	ratio := l.measuredCRLFRatio()
+	if !ratio.measured || (l.crlfLines == 0 && !flipped) {
+		return ""
+	}
+	text := fmt.Sprintf("line endings: %.0f%% CRLF", ratio.value*100)
+	if flipped {
+		text += fmt.Sprintf(" (was %s)", from)
+	}
+	return text
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// LineKinds is the number of lines of a content by kind: lines with code,
+// lines with nothing but comments, and blank lines.
+type LineKinds struct {
+	Code    uint `json:"code"`
+	Comment uint `json:"comment"`
+	Blank   uint `json:"blank"`
+}
+
+func (k *LineKinds) add(other LineKinds) {
+	k.Code += other.Code
+	k.Comment += other.Comment
+	k.Blank += other.Blank
+}
+
+// commentSyntax is the comment syntax of a family of languages.
+type commentSyntax struct {
+	lines  []string    // Line comment openers
+	blocks [][2]string // Block comment openers and closers
+	quotes string      // String delimiters, whose content is never a comment
+}
+
+var (
+	cSyntax    = &commentSyntax{lines: []string{"//"}, blocks: [][2]string{{"/*", "*/"}}, quotes: "\"'`"}
+	cssSyntax  = &commentSyntax{blocks: [][2]string{{"/*", "*/"}}, quotes: "\"'"}
+	hashSyntax = &commentSyntax{lines: []string{"#"}, quotes: "\"'"}
+	// The markup languages don't delimit strings in their text
+	markupSyntax    = &commentSyntax{blocks: [][2]string{{"<!--", "-->"}}}
+	componentSyntax = &commentSyntax{lines: []string{"//"}, blocks: [][2]string{{"/*", "*/"}, {"<!--", "-->"}}}
+)
+
+// commentSyntaxes are the comment syntaxes of the file extensions, lowercase.
+// The files of the other extensions only have code and blank lines.
+var commentSyntaxes = map[string]*commentSyntax{
+	".js": cSyntax, ".cjs": cSyntax, ".mjs": cSyntax, ".jsx": cSyntax,
+	".ts": cSyntax, ".cts": cSyntax, ".mts": cSyntax, ".tsx": cSyntax,
+	// JSON has no comments, but the configuration files such as tsconfig.json often do
+	".json": cSyntax, ".jsonc": cSyntax, ".json5": cSyntax,
+	".scss": cSyntax, ".less": cSyntax, ".css": cssSyntax,
+	".html": markupSyntax, ".htm": markupSyntax, ".xml": markupSyntax, ".svg": markupSyntax,
+	".md": markupSyntax, ".markdown": markupSyntax,
+	".vue": componentSyntax, ".svelte": componentSyntax, ".astro": componentSyntax,
+	".go": cSyntax, ".c": cSyntax, ".h": cSyntax, ".cpp": cSyntax, ".rs": cSyntax, ".java": cSyntax,
+	".sh": hashSyntax, ".bash": hashSyntax, ".py": hashSyntax, ".rb": hashSyntax,
+	".yml": hashSyntax, ".yaml": hashSyntax, ".toml": hashSyntax,
+}
+
+// lineClassifier is an io.Writer counting the lines written to it by kind, following
+// the comment syntax of their file. A line is code if it has anything outside comments,
+// a comment if it only has comments, and blank otherwise, blank lines within a block comment included.
+// Like CountLines, only the lines ending with \n are counted. It only keeps the bytes
+// that may start a comment delimiter, whatever the size of the content.
+type lineClassifier struct {
+	syntax      *commentSyntax // Comment syntax of the file, nil if it has none
+	kinds       LineKinds
+	pending     []byte // Bytes that may start a comment opener
+	block       int    // Index of the open block comment, -1 if none
+	lineComment bool   // Whether the rest of the line is a comment
+	quote       byte   // Delimiter of the open string, 0 if none
+	escaped     bool   // Whether the previous byte of the string escapes the next one
+	code        bool   // Whether the current line has code
+	comment     bool   // Whether the current line has a comment
+}
+
+// newLineClassifier returns a classifier of the lines of a file, following the comment syntax of its extension.
+func newLineClassifier(path string) *lineClassifier {
+	return &lineClassifier{syntax: commentSyntaxes[strings.ToLower(filepath.Ext(path))], block: -1}
+}
+
+func (c *lineClassifier) Write(p []byte) (int, error) {
+	for _, b := range p {
+		c.classify(b)
+	}
+	return len(p), nil
+}
+
+func (c *lineClassifier) classify(b byte) {
+	if b == '\n' {
+		c.endLine()
+		return
+	}
+	switch {
+	case c.syntax == nil:
+		c.mark(b, &c.code)
+	case c.lineComment:
+		c.mark(b, &c.comment)
+	case c.block >= 0:
+		c.mark(b, &c.comment)
+		closer := c.syntax.blocks[c.block][1]
+		c.pending = append(c.pending, b)
+		if len(c.pending) > len(closer) {
+			c.pending = c.pending[1:]
+		}
+		if string(c.pending) == closer {
+			c.block, c.pending = -1, c.pending[:0]
+		}
+	case c.quote != 0:
+		c.mark(b, &c.code)
+		switch {
+		case c.escaped:
+			c.escaped = false
+		case b == '\\':
+			c.escaped = true
+		case b == c.quote:
+			c.quote = 0
+		}
+	default:
+		c.pending = append(c.pending, b)
+		c.matchOpener()
+	}
+}
+
+// matchOpener opens the comment the pending bytes start, keeps them while they may still start one,
+// or else flushes their first byte as code and matches the rest again.
+func (c *lineClassifier) matchOpener() {
+	for len(c.pending) > 0 {
+		prefix := false
+		for _, opener := range c.syntax.lines {
+			if string(c.pending) == opener {
+				c.lineComment, c.comment, c.pending = true, true, c.pending[:0]
+				return
+			}
+			prefix = prefix || strings.HasPrefix(opener, string(c.pending))
+		}
+		for i, block := range c.syntax.blocks {
+			if string(c.pending) == block[0] {
+				c.block, c.comment, c.pending = i, true, c.pending[:0]
+				return
+			}
+			prefix = prefix || strings.HasPrefix(block[0], string(c.pending))
+		}
+		if prefix {
+			return
+		}
+		b := c.pending[0]
+		c.pending = c.pending[:copy(c.pending, c.pending[1:])]
+		c.mark(b, &c.code)
+		if strings.IndexByte(c.syntax.quotes, b) != -1 {
+			c.quote = b
+			// The rest is within the string
+			rest := string(c.pending)
+			c.pending = c.pending[:0]
+			for i := 0; i < len(rest); i++ {
+				c.classify(rest[i])
+			}
+			return
+		}
+	}
+}
+
+// mark records that the current line has the kind of content, unless the byte is whitespace.
+func (c *lineClassifier) mark(b byte, kind *bool) {
+	switch b {
+	case ' ', '\t', '\r', '\f', '\v':
+	default:
+		*kind = true
+	}
+}
+
+// endLine counts the current line by kind, the pending bytes not opening any comment.
+func (c *lineClassifier) endLine() {
+	if c.block < 0 {
+		for _, b := range c.pending {
+			c.mark(b, &c.code)
+		}
+	}
+	// Delimiters don't span lines
+	c.pending = c.pending[:0]
+	switch {
+	case c.code:
+		c.kinds.Code++
+	case c.comment:
+		c.kinds.Comment++
+	default:
+		c.kinds.Blank++
+	}
+	c.code, c.comment, c.lineComment, c.quote, c.escaped = false, false, false, 0, false
+}
+
+// measuredCodeLines returns the lines with code of the release,
+// not measured if its lines weren't counted by kind or its analysis failed.
+func (a AnalysisResult) measuredCodeLines() measure[int] {
+	return a.measuredLineKind(a.lineKinds.Code)
+}
+
+// measuredLineKind returns the lines of a kind of the release,
+// not measured if its lines weren't counted by kind or its analysis failed.
+func (a AnalysisResult) measuredLineKind(lines uint) measure[int] {
+	if a.failed != "" || !a.kindsCounted {
+		return measure[int]{}
+	}
+	return measured(int(lines))
+}
+
+// lineChange returns the change of code lines from the previous release, and their unit,
+// or the change of lines if either release wasn't counted by kind, such as one read from an older baseline.
+func lineChange(previous, current AnalysisResult) (measure[int], string) {
+	if previous.kindsCounted && current.kindsCounted {
+		return current.measuredCodeLines().since(previous.measuredCodeLines()), "code lines"
+	}
+	return current.measuredLines().since(previous.measuredLines()), "lines"
+}
+
+// linesText renders the lines of the release, the code lines first if counted by kind.
+func (a AnalysisResult) linesText() string {
+	if !a.kindsCounted {
+		return fmt.Sprintf("%d lines", a.totalLines)
+	}
+	return fmt.Sprintf(
+		"%d code lines (%d lines, %d comment, %d blank)",
+		a.lineKinds.Code, a.totalLines, a.lineKinds.Comment, a.lineKinds.Blank,
+	)
+}
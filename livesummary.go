@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// liveSummaryMinimum is the number of completed analyses from which the summary list
+// is shown while the other releases are still being analyzed.
+const liveSummaryMinimum = 3
+
+// updateLiveSummary shows the analyses completed so far in the summary list while the others
+// are still running, each release at its chronological position, linked to its nearest
+// completed neighbors for the differences. The list keeps its selection and scroll as
+// results arrive, and is replaced by the final summary once all the releases are analyzed.
+func (m model) updateLiveSummary() (model, tea.Cmd) {
+	if m.headless || m.state != StateAnalyzing {
+		return m, nil
+	}
+	var analyses []AnalysisResult
+	var releases []Release
+	for i, analysis := range m.data.analysis {
+		if analysis.releaseTag != "" && i < len(m.data.releases) {
+			analyses = append(analyses, analysis)
+			releases = append(releases, m.data.releases[i])
+		}
+	}
+	if len(analyses) < liveSummaryMinimum || len(analyses) == len(m.data.analysis) {
+		return m, nil
+	}
+
+	if !m.liveSummary {
+		m.liveSummary = true
+		m.loadSummaryPreferences()
+		m.items = m.summaryItems(analyses, releases)
+		m.list = m.newSummaryList()
+		// The actions of the summary wait for the final one
+		m.list.AdditionalShortHelpKeys, m.list.AdditionalFullHelpKeys = nil, nil
+		m.resizeSummary()
+		return m, nil
+	}
+	m.items = m.summaryItems(analyses, releases)
+	m.list.Title = m.listTitle()
+	// The header appears once both endpoints are analyzed
+	m.resizeSummary()
+	return m, m.list.SetItems(m.sortedItems())
+}
+
+// liveHeader renders the lines shown above the live summary: the analysis settings,
+// then the difference between the endpoints once both are analyzed.
+func (m model) liveHeader(width int) string {
+	header := blurredStyle.MaxWidth(width).Render(m.data.analysisSettings().String())
+	from, to := m.releaseIndex(m.data.firstRelease), m.releaseIndex(m.data.secondRelease)
+	if from == -1 || to == -1 || m.data.analysis[from].releaseTag == "" || m.data.analysis[to].releaseTag == "" {
+		return header
+	}
+	diff := m.data.analysis[to].measuredLines().since(m.data.analysis[from].measuredLines())
+	return lipgloss.JoinVertical(
+		lipgloss.Left, header,
+		lipgloss.NewStyle().MaxWidth(width).Render(
+			fmt.Sprintf("%s → %s: %s", m.data.firstRelease, m.data.secondRelease, textForDiff(diff)),
+		),
+	)
+}
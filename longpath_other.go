@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// longPath returns the path unchanged, as only Windows limits the length of paths.
+func longPath(path string) string {
+	return path
+}
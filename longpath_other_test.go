@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import "testing"
+
+func TestLongPath(t *testing.T) {
+	for _, path := range []string{"/home/me/releases", "releases", "../releases", `\\?\C:\releases`} {
+		if long := longPath(path); long != path {
+			t.Errorf("longPath(%q) = %q, expected it unchanged", path, long)
+		}
+	}
+}
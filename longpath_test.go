@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"strings"
+	"testing"
+)
+
+// TestExtractDeepPaths checks that the files of a release nested deeper than MAX_PATH,
+// of 260 characters on Windows, are extracted and analyzed.
+func TestExtractDeepPaths(t *testing.T) {
+	name := path.Join("package", strings.Repeat("node_modules/nested-dependency/", 10), "index.js")
+	if len(name) <= 260 {
+		t.Fatalf("the path %s isn't longer than MAX_PATH", name)
+	}
+	tarball := testTarball(t, tarFile("package/package.json", `{"name": "pkg"}`), tarFile(name, "export default 1;\n"))
+
+	dir := t.TempDir()
+	if err := Untar(dir, bytes.NewReader(tarball)); err != nil {
+		t.Fatal(err)
+	}
+	msg := AnalyzeRelease(context.Background(), dir, "pkg@1.0.0", AnalysisSettings{}, 0)()
+	analysis, ok := msg.(analysisDoneMsg)
+	if !ok {
+		t.Fatalf("unexpected message %T", msg)
+	}
+	if analysis.failed != "" || len(analysis.warnings) > 0 {
+		t.Fatalf("the analysis failed: %s %v", analysis.failed, analysis.warnings)
+	}
+	if lines := analysis.files[name]; lines != 1 {
+		t.Errorf("got %d lines for %s, expected 1", lines, name)
+	}
+}
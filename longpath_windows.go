@@ -0,0 +1,29 @@
+//go:build windows
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// longPathPrefix makes Windows APIs accept paths longer than MAX_PATH.
+const longPathPrefix = `\\?\`
+
+// longPath returns the extended-length form of a path, so that deeply nested
+// files of a release can be created and walked despite the MAX_PATH limit.
+// The path is made absolute, as extended-length paths can't be relative.
+func longPath(path string) string {
+	if strings.HasPrefix(path, longPathPrefix) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		// UNC path: \\server\share -> \\?\UNC\server\share
+		return longPathPrefix + `UNC\` + strings.TrimPrefix(abs, `\\`)
+	}
+	return longPathPrefix + abs
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLongPath(t *testing.T) {
+	cwd, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		path, expected string
+	}{
+		{`C:\Users\me\releases`, `\\?\C:\Users\me\releases`},
+		{`C:\Users\me\releases\..\cache`, `\\?\C:\Users\me\cache`},
+		{`\\?\C:\Users\me\releases`, `\\?\C:\Users\me\releases`},
+		{`\\server\share\releases`, `\\?\UNC\server\share\releases`},
+		{`releases`, `\\?\` + filepath.Join(cwd, "releases")},
+	} {
+		if path := longPath(test.path); path != test.expected {
+			t.Errorf("longPath(%q) = %q, expected %q", test.path, path, test.expected)
+		}
+	}
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,6 +25,10 @@ import (
 // appVersion is the version of the application.
 const appVersion = "1.3.0"
 
+// localReleaseTag is the release tag used for the local directory
+// when comparing against a baseline.
+const localReleaseTag = "local"
+
 // State represents the application state.
 type State int
 
@@ -38,16 +48,206 @@ const (
 )
 
 var (
-	ghRepo        = flag.String("repo", "", "GitHub repository to compare releases from. Format: owner/repo")
-	ghToken       = flag.String("token", "", "GitHub token to use for API requests")
+	ghRepo     = flag.String("repo", "", "GitHub repository to compare releases from. Format: owner/repo")
+	ghToken    = flag.String("token", "", "GitHub token to use for API requests")
+	npmPackage = flag.String(
+		"package", "",
+		"npm package name of the releases, for repositories whose tags don't contain it, such as v1.2.3",
+	)
 	firstRelease  = flag.String("from", "", "Base release to compare")
 	secondRelease = flag.String("to", "", "Release to compare to")
-	ignoreRegex   = flag.String("ignore", "", "Regex to ignore releases names from the analysis")
+	ignoreRegex   = flag.String("ignore", "", "Pattern to ignore releases names from the analysis, see --ignore-mode")
+	ignoreMode    = flag.String("ignore-mode", string(IgnoreModeRegex), "How --ignore matches the releases names: regex, substring, or glob (path.Match pattern against the full tag)")
+	releaseOrder  = flag.String(
+		"order", string(OrderDate),
+		"How the releases are ordered to select the ones between --from and --to and to compare them: date (creation date) or semver (version)",
+	)
 	extractionDir = flag.String("output", "releases", "Directory to extract releases to")
 	remove        = flag.Bool(
 		"remove", false,
 		"Remove the directory containing the extracted releases once the processing is done",
 	)
+	includeTests = flag.Bool(
+		"include-tests", false,
+		"Include the test, example and documentation directories (test, tests, __tests__, examples, docs) in the analysis",
+	)
+	includeGenerated = flag.Bool(
+		"include-generated", false,
+		"Include the generated and minified files (source maps, .min. files and files of very long lines) in the lines of the releases",
+	)
+	topFiles = flag.Int(
+		"top-files", 0,
+		"Only analyze the N largest files of each release, for a quick approximation",
+	)
+	noExtract = flag.Bool(
+		"no-extract", false,
+		"Analyze the releases while downloading them, without extracting them to disk nor caching them",
+	)
+	baselineFlag = flag.String(
+		"baseline", "",
+		"Baseline file to compare against or to save the compared release to. Format: read=path.json or write=path.json",
+	)
+	localDir           = flag.String("local", "", "Local directory to analyze as the release to compare to, requires --baseline read=path.json")
+	onReleaseExtracted = flag.String(
+		"on-release-extracted", "",
+		"Command to run after each release is extracted, with {dir} and {tag} placeholders",
+	)
+	onComplete = flag.String(
+		"on-complete", "",
+		"Command to run once the comparison is done, with a {json} placeholder for the path of a JSON summary",
+	)
+	strictHooks = flag.Bool("strict-hooks", false, "Fail if the --on-complete command fails instead of warning")
+	logFile     = flag.String("log", "", "File to write the verbose log to, including the hooks output")
+	noColor     = flag.Bool("no-color", false, "Render the user interface and the headless output without colors nor styles")
+	noNotify    = flag.Bool(
+		"no-notify", false,
+		"Don't reflect the progress in the terminal title nor notify when the comparison is done",
+	)
+	headless = flag.Bool(
+		"headless", false,
+		"Run without the user interface, printing the progress to stderr and the comparison to stdout; requires --repo, --from and --to",
+	)
+	bench      = flag.Bool("bench", false, "Benchmark the extraction and analysis on synthetic releases at various concurrency levels, then exit")
+	benchFiles = flag.Int("bench-files", 500, "Number of files of each synthetic release of --bench")
+	benchSize  = flag.Int("bench-file-size", 4096, "Approximate size in bytes of each synthetic file of --bench")
+	langMap    = flag.String(
+		"lang-map", "",
+		"Language overrides of file extensions, an empty language excluding them. Format: .ext=Language,.ext2=Language2",
+	)
+	excludeFlag = flag.String(
+		"exclude", "",
+		"Glob patterns of the paths of the releases to leave out of the analysis, ** matching any directories. Format: dist/**,**/*.d.ts",
+	)
+	includeFlag = flag.String(
+		"include", "",
+		"Glob patterns of the only paths of the releases to analyze, ** matching any directories. Format: src/**,lib/**",
+	)
+	exportOrderFlag = flag.String(
+		"export-order", string(ExportChronological),
+		"Order of the releases in the exports: chronological (oldest first) or display (current order of the summary list)",
+	)
+	jsonPath = flag.String(
+		"json", "",
+		"File to export the analysis results and the deltas between releases to as JSON once the comparison is done, - for stdout",
+	)
+	csvPath = flag.String(
+		"csv", "",
+		"File to export a row per release to as CSV once the comparison is done: tag, publication date, files, lines, lines per language, tarball size and lines delta",
+	)
+	reportFlag = flag.String(
+		"report", "",
+		"Report of the comparison to generate once it is done, to share it: markdown (a summary and a table of the releases)",
+	)
+	reportOut  = flag.String("report-out", "-", "File to write the --report to, - for stdout")
+	importPath = flag.String(
+		"import", "",
+		"Comparison bundle to show the summary of, as exported with --export bundle=path, without downloading nor analyzing anything",
+	)
+	mergePaths = flag.String(
+		"merge", "",
+		"Comma-separated bundles of the shards of a comparison, exported from --shard runs, to show the summary of the whole comparison of",
+	)
+	shardFlag = flag.String(
+		"shard", "",
+		"Shard of the planned releases to download and analyze, as index/count such as 1/4 for the oldest quarter; requires --export bundle=path",
+	)
+	exportFlag = flag.String(
+		"export", "",
+		"Comma-separated files to export once the comparison is done, as format=path. Formats: mermaid, dot (dependency changes graph), bundle (whole comparison, for --import)",
+	)
+	ignoreWhitespace = flag.Bool(
+		"ignore-whitespace", false,
+		"Also count the lines once their trailing whitespace trimmed and their runs of blank lines collapsed, labeling the releases mostly reformatted",
+	)
+	formattingThreshold = flag.String(
+		"formatting-threshold", defaultFormattingThreshold,
+		"Normalized change of lines, relative to the raw change, below which a release is labeled mostly formatting with --ignore-whitespace, 0 to disable",
+	)
+	spikes = flag.Int(
+		"spikes", defaultSpikes,
+		"Number of the biggest jumps of lines and of gzipped size between consecutive releases listed above the summary, 0 to disable",
+	)
+	dominantFileThreshold = flag.String(
+		"dominant-file-threshold", defaultDominantFileThreshold,
+		"Share of the changed lines from the previous release above which a release is labeled mostly changed in its top file, 0 to disable",
+	)
+	otherThreshold = flag.String(
+		"other-threshold", defaultOtherThreshold,
+		"Share of the lines of no known language (Other) above which their top extensions are noticed, 0 to disable",
+	)
+	densityThreshold = flag.String(
+		"density-threshold", "25%",
+		"Change of lines per unpacked kB from the previous release above which a packaging change is suspected, 0 to disable",
+	)
+	largeFileSize = flag.String(
+		"large-file-size", "1MB",
+		"Size above which a file is counted as large, such as 1MB or 512KiB, 0 to disable",
+	)
+	failIfLargeFiles = flag.Int(
+		"fail-if-large-files", -1,
+		"Exit with an error if the release to compare to has more large files than this, see --large-file-size; negative to disable",
+	)
+	maxDisk = flag.String(
+		"max-disk", "0",
+		"Maximum bytes extracted from all the releases, after which the remaining releases are not downloaded, 0 to disable",
+	)
+	maxExtractSize = flag.String(
+		"max-extract-size", "2GB",
+		"Maximum bytes extracted from a single release, above which its extraction is aborted as a possible decompression bomb, 0 to disable",
+	)
+	analyzeTimeout = flag.Duration(
+		"analyze-timeout", 10*time.Minute,
+		"Maximum duration of the analysis of each release, after which it is reported as failed and the run continues, 0 to disable",
+	)
+	cachePolicyFlag = flag.String(
+		"cache-policy", string(CacheShasum),
+		"How extracted releases are validated before being reused: exists, shasum (matching the registry) or verify-files (also checking the extracted files)",
+	)
+	dirTemplate = flag.String(
+		"dir-template", defaultDirTemplate,
+		"Path of the extraction directory of each release under --output, with the {tag}, {package}, {version}, {owner} and {repo} variables",
+	)
+	concurrency = flag.Int(
+		"concurrency", defaultConcurrency,
+		"Number of releases downloaded or analyzed at once",
+	)
+	registry = flag.String(
+		"registry", defaultRegistry,
+		"URL of the npm registry the releases are downloaded from, such as a private mirror",
+	)
+	registryToken = flag.String(
+		"registry-token", "",
+		"Token of the npm registry, sent as basic credentials if of the form user:password and as a bearer token otherwise",
+	)
+	caCert = flag.String(
+		"ca-cert", "",
+		"PEM file of certificate authorities to trust for the npm registry, in addition to the system ones, such as a corporate CA",
+	)
+	insecureSkipTLSVerify = flag.Bool(
+		"insecure-skip-tls-verify", false,
+		"Do not verify the TLS certificates of the npm registry, as a last resort: prefer --ca-cert",
+	)
+	userAgent = flag.String(
+		"user-agent", "",
+		"User-Agent of the requests to GitHub and the npm registry, defaults to the name and version of the application",
+	)
+	noResume     = flag.Bool("no-resume", false, "Start a fresh run instead of resuming an interrupted run with the same inputs")
+	registryOnly = flag.Bool(
+		"registry-only", false,
+		"List the releases from the versions of --package published to the npm registry instead of the GitHub releases, without --repo",
+	)
+	skipCheck = flag.Bool(
+		"skip-check", false,
+		"Don't check that --from and --to exist before fetching the releases, the fetching failing anyway if either is missing",
+	)
+	verifySource = flag.Bool(
+		"verify-source", false,
+		"Compare the files of the endpoint releases to their tagged source on GitHub, and report the published files absent from it",
+	)
+	verifySourceAllow = flag.String(
+		"verify-source-allow", defaultSourceAllowlist,
+		"Comma-separated globs of the built artifacts expected to be absent from the tagged sources of --verify-source",
+	)
 	version = flag.Bool("version", false, "Print the version and exit")
 
 	docStyle    = lipgloss.NewStyle().Margin(1, 2)
@@ -63,22 +263,150 @@ var (
 	blurredStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 	successStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
 	errorStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	warningStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
 	noStyle           = lipgloss.NewStyle()
 )
 
+// summaryKeyMap is the set of additional key bindings of the summary.
+type summaryKeyMap struct {
+	chart       key.Binding
+	normalize   key.Binding
+	sort        key.Binding
+	reverseSort key.Binding
+	files       key.Binding
+	annotate    key.Binding
+	columns     key.Binding
+	months      key.Binding
+	pin         key.Binding
+	language    key.Binding
+	fromRelease key.Binding
+	toRelease   key.Binding
+	nextSpike   key.Binding
+	palette     key.Binding
+}
+
+var summaryKeys = summaryKeyMap{
+	chart: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "toggle chart"),
+	),
+	normalize: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "toggle normalized chart"),
+	),
+	sort: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "cycle sort metric"),
+	),
+	reverseSort: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "reverse sort"),
+	),
+	files: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "browse files"),
+	),
+	annotate: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "annotate"),
+	),
+	columns: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "choose columns"),
+	),
+	months: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "toggle group by month"),
+	),
+	pin: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "pin"),
+	),
+	language: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "filter by language change"),
+	),
+	fromRelease: key.NewBinding(
+		key.WithKeys("<"),
+		key.WithHelp("<", "jump to the base release"),
+	),
+	toRelease: key.NewBinding(
+		key.WithKeys(">"),
+		key.WithHelp(">", "jump to the compared release"),
+	),
+	nextSpike: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "jump to the next biggest jump"),
+	),
+	palette: key.NewBinding(
+		key.WithKeys("ctrl+p", ":"),
+		key.WithHelp(":", "command palette"),
+	),
+}
+
 type (
-	// fatalErr is a fatal error message.
-	fatalErr struct{}
+	// runMsg wraps a message produced by a command of a given pipeline run,
+	// so that messages from a cancelled or failed run can be discarded.
+	runMsg struct {
+		run uint
+		msg tea.Msg
+	}
+
+	// config is the configuration of the comparison, parsed from the flags once
+	// and kept as is when going back to the inputs, see edit.
+	config struct {
+		tokenSource           string            // Where the token was resolved from, empty if typed or none, see ResolveToken
+		ignoreMode            IgnoreMode        // How the ignore pattern matches the releases names
+		releaseOrder          ReleaseOrder      // How the releases are ordered
+		baselineMode          BaselineMode      // Whether a baseline is read, written, or not used
+		baselinePath          string            // Path to the baseline file
+		localDir              string            // Local directory to analyze as the release to compare to
+		langMap               map[string]string // Language overrides of file extensions
+		excludePaths          []string          // Glob patterns of the paths left out of the analysis
+		includePaths          []string          // Glob patterns of the only paths analyzed, if any
+		exportOrder           ExportOrder       // Order of the releases in the exports
+		exports               []ExportTarget    // Files to export once the comparison is done
+		report                ReportFormat      // Report of the comparison to generate once it is done
+		densityThreshold      float64           // Change of density suspected to be a packaging change, as a ratio
+		formattingThreshold   float64           // Normalized change of lines below which a release is mostly formatting, as a ratio of the raw one
+		dominantFileThreshold float64           // Share of the changed lines above which a release is mostly changed in its top file, as a ratio
+		spikeCount            int               // Number of the biggest jumps listed per metric, 0 to disable
+		otherThreshold        float64           // Share of the lines of no known language above which they are noticed, as a ratio
+		cachePolicy           CachePolicy       // How extracted releases are validated before being reused
+		extractLimits         ExtractLimits     // Limits of the extraction of each release
+		largeFileSize         int64             // Size above which a file is counted as large
+		dirTemplate           string            // Template of the extraction directories of the releases
+		sourceAllowlist       []string          // Globs of the built artifacts absent from the tagged sources
+		shard                 Shard             // Shard of the planned releases to process, see `--shard`
+		registryOnly          bool              // Whether the releases are the versions published to the npm registry, see `--registry-only`
+		skipCheck             bool              // Whether the endpoints are fetched without being checked first, see `--skip-check`
+	}
 
 	// data is the application data model.
 	data struct {
-		ghRepo        string           // GitHub repository to compare releases from. Format: owner/repo
-		ghToken       string           // GitHub token to use for API requests
-		firstRelease  string           // Base release to compare
-		secondRelease string           // Release to compare to
-		ignoreRegex   string           // Regex to ignore releases names from the analysis
-		releases      []Release        // GitHub releases
-		analysis      []AnalysisResult // Analysis results
+		config
+
+		ghRepo        string                // GitHub repository to compare releases from. Format: owner/repo
+		ghToken       string                // GitHub token to use for API requests
+		npmPackage    string                // npm package name of the releases, empty if the tags contain it
+		firstRelease  string                // Base release to compare
+		secondRelease string                // Release to compare to
+		ignoreRegex   string                // Pattern to ignore releases names from the analysis
+		baseline      *AnalysisResult       // Analysis result read from the baseline file
+		releases      []Release             // GitHub releases
+		planReport    PlanReport            // Report of the selection of the releases
+		analysis      []AnalysisResult      // Analysis results
+		anchors       []anchor              // Anchors the compared release is compared against
+		releaseDirs   map[string]string     // Extraction directory of each release, by tag
+		warnings      []string              // Warnings about the comparison as a whole
+		notes         map[string]string     // Notes of the user about the releases of the repository, by tag
+		unverified    map[string][]string   // Published files absent from the tagged source, by tag
+		provenance    map[string]Provenance // Provenance of the releases looked up so far, by tag
+		cadence       CadenceStats          // Release cadence and size velocity over the range
+		transitions   []string              // Changes of the engines and of the package manager across the range
+		imported      *AnalysisSettings     // Analysis settings of the comparison imported with --import, if any
+		importedFrom  string                // Bundles the comparison was imported from, with --import or --merge
+		planned       int                   // Number of planned releases, including the ones of the other shards
 	}
 
 	// model is the application internal state.
@@ -86,21 +414,77 @@ type (
 		data  data
 		state State
 
-		spinner spinner.Model
+		spinner     spinner.Model
+		progressBar progress.Model // Progress bar of the downloads
 
 		focusIndex int
-		inputs     []textinput.Model
+		inputs     []formInput
 		cursorMode cursor.Mode
 
-		existingReleasesCount uint
+		detectedRepo string        // Repository detected from the current directory
+		picker       releasePicker // Tags suggested under the release inputs
+		detectedFrom string        // Where the repository was detected from
+
+		releaseChecks      map[string]gitReleaseExistsMsg // Results of the existence checks of the endpoints, by tag
+		downloadWarnings   map[string][]string            // Warnings of the downloads, such as hook errors, by release
+		budgetSkipped      []string                       // Releases not downloaded, the disk budget being exhausted
+		downloadErrors     map[string]error               // Errors of the failed downloads, by release
+		releaseStatuses    map[string]releaseStatus       // Progress of each release through the download and the analysis
+		maxDisk            int64                          // Bytes all the extractions of a run may write, 0 for no limit
+		concurrency        int                            // Number of releases downloaded or analyzed at once
+		ctx                context.Context                // Context of the requests of the commands, cancelled on quit
+		cancel             context.CancelFunc             // Cancels ctx
+		confirmSameTarball bool                           // Whether the user must confirm comparing endpoints with the same tarball
+		headless           bool                           // Whether the pipeline runs without the user interface, see runHeadless
+		stdoutExports      []byte                         // Exports printed to stdout on exit, such as with `--json -`
+
+		tokenInput    *textinput.Model // Input for a new token, shown when the token expired
+		fetchProgress *fetchProgress   // Progress of the releases fetching to resume from
+		rateLimited   *rateLimitedMsg  // Rate limit interrupting the releases fetching, if any
+		waitingReset  bool             // Whether the fetching resumes once the rate limit resets
+		resumeAt      time.Time        // When the fetching resumes, while waiting for the reset
+
+		downloadProgress    uint
+		downloadCacheCount  uint
+		downloadResumeCount uint                    // Releases already analyzed by the interrupted run being resumed
+		downloadRepairCount uint                    // Releases downloaded again over an incomplete extraction
+		manifest            *RunManifest            // Manifest of the run, to resume it if interrupted
+		byteProgress        map[string]byteProgress // Download progress of the releases being downloaded, by tag
+
+		list          *list.Model
+		width, height int  // Size of the window, 0 until known
+		liveSummary   bool // Whether the list is the live summary of the analyses completed so far
 
-		downloadProgress   uint
-		downloadCacheCount uint
+		items           []list.Item      // Summary list items, in release order
+		sort            summarySort      // Order of the list
+		groupByMonth    bool             // Whether the list is grouped by month of publication
+		expandedMonths  map[string]bool  // Months whose releases are listed when grouped by month, by key
+		pinned          map[string]bool  // Whether the releases are pinned, by tag
+		spikeIndex      int              // Index of the next of the biggest jumps to jump to, see jumpToNextSpike
+		showChart       bool             // Whether the chart is shown instead of the list
+		files           *list.Model      // Files of the selected release, when browsing them
+		timelinePath    string           // Normalized path of the file whose timeline is shown
+		noteInput       *textinput.Model // Input of the note of the selected release, when annotating it
+		noteTag         string           // Tag of the release being annotated
+		columnChooser   *columnChooser   // Chooser of the description columns, when open
+		languagePicker  *languagePicker  // Picker of the language filter, when open
+		palette         *commandPalette  // Command palette, when open
+		detail          *releaseDetail   // Detail pane of the selected release, when open
+		languageFilter  *languageFilter  // Filter of the releases where a language changed, if any
+		preferences     UIPreferences    // Preferences of the user interface
+		normalizedChart bool             // Whether the chart is normalized to the base release
 
-		list                      *list.Model
-		wantedWidth, wantedHeight *int
+		run      uint  // Current pipeline run, incremented on each (re)started phase
+		err      error // Error that stopped the pipeline
+		errState State // State in which the error occurred
+		errFatal bool  // Whether the error can't be retried, e.g. a configuration error
 
-		err error
+		title string // Current terminal title
+
+		startedAt  time.Time // Time the pipeline started at, once the inputs are known
+		finishedAt time.Time // Time the summary was reached at
+		// Clock of the times shown, nil for the wall clock, pinned to render the views deterministically
+		clock func() time.Time
 	}
 )
 
@@ -117,61 +501,679 @@ func initialModel() model {
 		fmt.Println(filepath.Base(exe), appVersion)
 		os.Exit(0)
 	}
+	if *noColor || *headless {
+		plainOutput()
+	}
 
+	token, tokenSource := ResolveToken(*ghToken)
 	m := model{
 		data: data{
+			config: config{
+				tokenSource:  tokenSource,
+				localDir:     *localDir,
+				registryOnly: *registryOnly,
+				skipCheck:    *skipCheck,
+			},
 			ghRepo:        *ghRepo,
-			ghToken:       *ghToken,
+			ghToken:       token,
+			npmPackage:    *npmPackage,
 			firstRelease:  *firstRelease,
 			secondRelease: *secondRelease,
 			ignoreRegex:   *ignoreRegex,
 		},
 	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+
+	m.spinner = newSpinner()
+	m.progressBar = newProgressBar()
+
+	// Set up the verbose log
+	log.SetOutput(io.Discard)
+	if *logFile != "" {
+		file, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			m.failConfig(err)
+			return m
+		}
+		log.SetOutput(file)
+	}
+
+	if *topFiles < 0 {
+		m.failConfig(fmt.Errorf("invalid --top-files %d, expected a positive number", *topFiles))
+		return m
+	}
+
+	// Parse the language overrides
+	overrides, err := ParseLangMap(*langMap)
+	if err != nil {
+		m.failConfig(err)
+		return m
+	}
+	m.data.langMap = overrides
 
-	// Initialize spinner
+	// Parse the glob patterns of the paths to exclude or to only include
+	if m.data.excludePaths, err = ParsePathPatterns(*excludeFlag); err != nil {
+		m.failConfig(fmt.Errorf("invalid --exclude: %w", err))
+		return m
+	}
+	if m.data.includePaths, err = ParsePathPatterns(*includeFlag); err != nil {
+		m.failConfig(fmt.Errorf("invalid --include: %w", err))
+		return m
+	}
+
+	// Parse the ignore mode, and check the ignore pattern up front
+	m.data.ignoreMode, err = ParseIgnoreMode(*ignoreMode)
+	if err != nil {
+		m.failConfig(err)
+		return m
+	}
+	if _, err = compileIgnore(m.data.ignoreMode, *ignoreRegex); err != nil {
+		m.failConfig(err)
+		return m
+	}
+
+	if m.data.releaseOrder, err = ParseReleaseOrder(*releaseOrder); err != nil {
+		m.failConfig(err)
+		return m
+	}
+
+	// Parse the allowlist of built artifacts
+	m.data.sourceAllowlist, err = ParseSourceAllowlist(*verifySourceAllow)
+	if err != nil {
+		m.failConfig(err)
+		return m
+	}
+
+	// Parse the export order
+	order, err := ParseExportOrder(*exportOrderFlag)
+	if err != nil {
+		m.failConfig(err)
+		return m
+	}
+	m.data.exportOrder = order
+
+	// Validate the package name
+	if err = ValidatePackageName(*npmPackage); err != nil {
+		m.failConfig(fmt.Errorf("invalid --package: %w", err))
+		return m
+	}
+	if *registryOnly && *npmPackage == "" {
+		m.failConfig(fmt.Errorf("--registry-only needs the --package whose versions to compare"))
+		return m
+	}
+
+	// Validate the concurrency
+	if *concurrency < 1 {
+		m.failConfig(fmt.Errorf("invalid --concurrency %d, expected at least 1", *concurrency))
+		return m
+	}
+	m.concurrency = *concurrency
+
+	// Validate the registry
+	if err = ValidateRegistry(*registry); err != nil {
+		m.failConfig(fmt.Errorf("invalid --registry: %w", err))
+		return m
+	}
+	if err = ConfigureRegistryTLS(*caCert, *insecureSkipTLSVerify); err != nil {
+		m.failConfig(fmt.Errorf("invalid --ca-cert: %w", err))
+		return m
+	}
+
+	// Parse the formatting threshold
+	m.data.formattingThreshold, err = ParsePercent(*formattingThreshold)
+	if err != nil {
+		m.failConfig(fmt.Errorf("invalid --formatting-threshold: %w", err))
+		return m
+	}
+
+	// Parse the dominant file threshold
+	m.data.dominantFileThreshold, err = ParsePercent(*dominantFileThreshold)
+	if err != nil {
+		m.failConfig(fmt.Errorf("invalid --dominant-file-threshold: %w", err))
+		return m
+	}
+	if *spikes < 0 {
+		m.failConfig(fmt.Errorf("invalid --spikes %d, expected a positive number", *spikes))
+		return m
+	}
+	m.data.spikeCount = *spikes
+
+	// Parse the Other language threshold
+	m.data.otherThreshold, err = ParsePercent(*otherThreshold)
+	if err != nil {
+		m.failConfig(fmt.Errorf("invalid --other-threshold: %w", err))
+		return m
+	}
+
+	// Parse the density threshold
+	m.data.densityThreshold, err = ParsePercent(*densityThreshold)
+	if err != nil {
+		m.failConfig(fmt.Errorf("invalid --density-threshold: %w", err))
+		return m
+	}
+
+	// Parse the extraction limits
+	m.data.extractLimits = defaultExtractLimits
+	m.data.extractLimits.MaxTotalBytes, err = ParseSize(*maxExtractSize)
+	if err != nil {
+		m.failConfig(fmt.Errorf("invalid --max-extract-size: %w", err))
+		return m
+	}
+	if m.data.largeFileSize, err = ParseSize(*largeFileSize); err != nil {
+		m.failConfig(fmt.Errorf("invalid --large-file-size: %w", err))
+		return m
+	}
+	if m.maxDisk, err = ParseSize(*maxDisk); err != nil {
+		m.failConfig(fmt.Errorf("invalid --max-disk: %w", err))
+		return m
+	}
+
+	// Parse the cache policy
+	m.data.cachePolicy, err = ParseCachePolicy(*cachePolicyFlag)
+	if err != nil {
+		m.failConfig(err)
+		return m
+	}
+
+	// Parse the report format
+	m.data.report, err = ParseReportFormat(*reportFlag)
+	if err != nil {
+		m.failConfig(err)
+		return m
+	}
+
+	// Check the directory template
+	if err = ValidateDirTemplate(*dirTemplate); err != nil {
+		m.failConfig(err)
+		return m
+	}
+	m.data.dirTemplate = *dirTemplate
+
+	// Check that the directories don't overlap, for the analysis not to count its own files
+	if err = checkPathsDisjoint(configuredDirs(*extractionDir, m.data.localDir)); err != nil {
+		m.failConfig(err)
+		return m
+	}
+
+	// Parse the exports
+	m.data.exports, err = ParseExports(*exportFlag)
+	if err != nil {
+		m.failConfig(err)
+		return m
+	}
+
+	// Inject failures for development purposes
+	if err = setupFaults(); err != nil {
+		m.failConfig(err)
+		return m
+	}
+
+	// Run the self-benchmark and exit
+	if *bench {
+		if *benchFiles <= 0 || *benchSize <= 0 {
+			_, _ = fmt.Fprintln(os.Stderr, "Error: --bench-files and --bench-file-size must be positive")
+			os.Exit(1)
+		}
+		os.Exit(
+			runBenchCommand(
+				BenchOptions{
+					Releases:    8,
+					Files:       *benchFiles,
+					FileSize:    *benchSize,
+					Seed:        benchSeed,
+					Concurrency: benchConcurrencyLevels(),
+				},
+				m.data.analysisSettings(),
+			),
+		)
+	}
+
+	// Check the shard
+	if m.data.shard, err = ParseShard(*shardFlag); err != nil {
+		m.failConfig(err)
+		return m
+	}
+	if m.data.shard.Count > 0 && !slices.ContainsFunc(
+		m.data.exports, func(target ExportTarget) bool {
+			return target.Format == ExportBundle
+		},
+	) {
+		m.failConfig(fmt.Errorf("--shard requires --export bundle=path, to merge the shards with --merge"))
+		return m
+	}
+	if *importPath != "" && *mergePaths != "" {
+		m.failConfig(fmt.Errorf("--import and --merge are mutually exclusive"))
+		return m
+	}
+
+	// Show an exported comparison straight away
+	if *importPath != "" || *mergePaths != "" {
+		var bundle Bundle
+		if *importPath != "" {
+			bundle, err = ReadBundle(*importPath)
+			m.data.importedFrom = *importPath
+		} else {
+			paths := strings.Split(*mergePaths, ",")
+			bundle, err = MergeBundles(paths)
+			m.data.importedFrom = fmt.Sprintf("%d shards (%s)", len(paths), *mergePaths)
+		}
+		if err != nil {
+			m.failConfig(err)
+			return m
+		}
+		m.data.importBundle(bundle)
+		m.data.warnings = append(
+			packageRenameWarnings(m.data.analysis), otherLanguageWarnings(m.data.analysis, m.data.otherThreshold)...,
+		)
+		if warning := versionOrderWarning(m.data.planReport.Inversions, m.data.planReport.ResolvedTo); warning != "" {
+			m.data.warnings = append(m.data.warnings, warning)
+		}
+		m.state = StateSummary
+		return m.buildSummary()
+	}
+
+	// Handle the baseline
+	mode, path, err := ParseBaselineFlag(*baselineFlag)
+	if err != nil {
+		m.failConfig(err)
+		return m
+	}
+	m.data.baselineMode, m.data.baselinePath = mode, path
+	if mode == BaselineRead {
+		if m.data.localDir == "" {
+			m.failConfig(fmt.Errorf("--baseline read=%s requires --local to provide the release to compare to", path))
+			return m
+		}
+		baseline, err := ReadBaseline(path)
+		if err != nil {
+			m.failConfig(err)
+			return m
+		}
+		m.data.baseline = &baseline
+		m.data.firstRelease = baseline.releaseTag
+		m.data.secondRelease = localReleaseTag
+		// Nothing to ask for, everything is local
+		return m
+	} else if m.data.localDir != "" {
+		m.failConfig(fmt.Errorf("--local can only be used with --baseline read=path.json"))
+		return m
+	}
+
+	// Initialize text inputs, with the repository of the current directory if any
+	prefill := m.data
+	if *ghRepo == "" {
+		m.detectedRepo, m.detectedFrom = DetectRepository(".")
+		prefill.ghRepo = m.detectedRepo
+	}
+	m.inputs = newInputs(prefill)
+
+	return m
+}
+
+// newSpinner creates the spinner of the pipeline, showing its first frame until it ticks.
+func newSpinner() spinner.Model {
 	spin := spinner.New()
 	spin.Spinner = spinner.Dot
 	spin.Style = svelteText
-	m.spinner = spin
-
-	// Initialize text inputs
-	if m.data.ghRepo == "" {
-		input := textinput.New()
-		input.Placeholder = "GitHub repository (owner/repo)"
-		m.inputs = append(m.inputs, input)
+	return spin
+}
 
-		if m.data.ghToken == "" {
-			tokenInput := textinput.New()
-			tokenInput.Placeholder = "GitHub token (optional)"
-			tokenInput.EchoMode = textinput.EchoPassword
-			tokenInput.EchoCharacter = '•'
-			m.inputs = append(m.inputs, tokenInput)
+// newInputs creates a text input for every value that was not provided
+// through the flags, pre-filled with the values of prefill,
+// and focuses the first one.
+func newInputs(prefill data) []formInput {
+	var inputs []formInput
+	add := func(field inputField, input textinput.Model, placeholder, value string) {
+		input.Placeholder = placeholder
+		input.SetValue(value)
+		inputs = append(inputs, formInput{Model: input, field: field})
+	}
+	if *ghRepo == "" && !*registryOnly {
+		add(fieldRepository, textinput.New(), "GitHub repository (owner/repo)", prefill.ghRepo)
+		if prefill.tokenSource == "" {
+			add(fieldToken, newTokenInput(), "GitHub token (optional)", prefill.ghToken)
 		}
 	}
-	if m.data.firstRelease == "" {
-		input := textinput.New()
-		input.Placeholder = "Base release"
-		m.inputs = append(m.inputs, input)
+	if *firstRelease == "" {
+		add(fieldFirstRelease, textinput.New(), baseReleasePlaceholder, prefill.firstRelease)
+	}
+	if *secondRelease == "" {
+		add(fieldSecondRelease, textinput.New(), compareReleasePlaceholder, prefill.secondRelease)
 	}
-	if m.data.secondRelease == "" {
-		input := textinput.New()
-		input.Placeholder = "Release to compare to"
-		m.inputs = append(m.inputs, input)
+	if *npmPackage == "" {
+		add(fieldPackage, textinput.New(), "npm package name (optional, if the tags don't contain it)", prefill.npmPackage)
 	}
-	if m.data.ignoreRegex == "" {
-		input := textinput.New()
-		input.Placeholder = "Regex to ignore releases names (optional)"
-		m.inputs = append(m.inputs, input)
+	if *ignoreRegex == "" {
+		add(fieldIgnore, textinput.New(), prefill.ignoreMode.placeholder(), prefill.ignoreRegex)
 	}
 
 	// Focus the first input
-	if len(m.inputs) > 0 {
-		m.inputs[0].Focus()
-		m.inputs[0].Cursor.Style = svelteText
-		m.inputs[0].PromptStyle = svelteText
+	if len(inputs) > 0 {
+		inputs[0].Focus()
+		inputs[0].Cursor.Style = svelteText
+		inputs[0].PromptStyle = svelteText
 	}
 
-	return m
+	return inputs
+}
+
+// newTokenInput creates a masked text input for a GitHub token.
+func newTokenInput() textinput.Model {
+	input := textinput.New()
+	input.EchoMode = textinput.EchoPassword
+	input.EchoCharacter = '•'
+	return input
+}
+
+// fail stops the pipeline with an error that occurred in the current state.
+func (m *model) fail(err error) {
+	m.err = err
+	m.errState = m.state
+}
+
+// failConfig stops the pipeline with an error that can't be retried.
+func (m *model) failConfig(err error) {
+	m.fail(err)
+	m.errFatal = true
+}
+
+// flow returns the flow of states the pipeline goes through.
+func (m model) flow() pipelineFlow {
+	switch {
+	case m.data.baseline != nil:
+		return flowLocal
+	case m.data.registryOnly:
+		return flowRegistryOnly
+	case m.data.skipCheck:
+		return flowSkipCheck
+	default:
+		return flowStandard
+	}
+}
+
+// advance moves the pipeline to the state following the event in its flow,
+// and starts the corresponding phase.
+func (m model) advance(event pipelineEvent) (model, tea.Cmd) {
+	next, err := nextState(m.flow(), m.state, event)
+	if err != nil {
+		m.fail(err)
+		return m, nil
+	}
+	return m.startPhase(next)
+}
+
+// start starts the pipeline once the inputs are known, resuming
+// the interrupted run with the same inputs if any, unless --no-resume.
+func (m model) start() (model, tea.Cmd) {
+	packageName = m.data.npmPackage
+	if m.flow() == flowRegistryOnly {
+		// Bare versions are tagged like the releases of the registry
+		m.data.firstRelease = registryTag(m.data.npmPackage, m.data.firstRelease)
+		m.data.secondRelease = registryTag(m.data.npmPackage, m.data.secondRelease)
+	}
+	if m.flow() != flowLocal && !*noResume {
+		manifest, err := ReadRunManifest(m.data.runKey())
+		if err == nil {
+			m.manifest = manifest
+			m.data.releases = manifest.Releases
+			m.data.planReport = manifest.Report
+			m.startedAt, m.finishedAt = m.now(), time.Time{}
+			return m.advance(eventResumed)
+		}
+		if !os.IsNotExist(err) {
+			log.Printf("not resuming: %v", err)
+		}
+	}
+	m.manifest = nil
+	m.startedAt, m.finishedAt = m.now(), time.Time{}
+	return m.advance(eventStarted)
+}
+
+// checkpoint logs the error of a run manifest update, as failing
+// to checkpoint a run only prevents it from being resumed.
+func checkpoint(err error) {
+	if err != nil {
+		log.Printf("could not checkpoint the run: %v", err)
+	}
+}
+
+// withRun tags the message produced by the command with the given run.
+func withRun(run uint, command tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return runMsg{run, command()}
+	}
+}
+
+// startPhase moves to the given state, resetting any partial state
+// of that phase, and dispatches its commands under a new run.
+func (m model) startPhase(state State) (model, tea.Cmd) {
+	m.run++
+	m.state = state
+	m.err = nil
+
+	var commands []tea.Cmd
+	// The downloads and the analyses of the releases run a few at a time
+	pool := newWorkerPool(m.concurrency)
+	switch state {
+	case StateChecking:
+		m.releaseChecks = make(map[string]gitReleaseExistsMsg)
+		commands = append(
+			commands,
+			DoesGitHubReleaseExist(m.ctx, m.data.ghRepo, m.data.ghToken, m.data.firstRelease),
+			DoesGitHubReleaseExist(m.ctx, m.data.ghRepo, m.data.ghToken, m.data.secondRelease),
+		)
+	case StateFetching:
+		m.data.releases = nil
+		m.tokenInput = nil
+		if m.flow() == flowRegistryOnly {
+			commands = append(
+				commands,
+				GetRegistryReleases(
+					m.ctx,
+					m.data.npmPackage,
+					m.data.firstRelease,
+					m.data.secondRelease,
+					m.data.ignoreRegex,
+					m.data.ignoreMode,
+					m.data.releaseOrder,
+				),
+			)
+			break
+		}
+		commands = append(
+			commands,
+			GetGitHubReleases(
+				m.ctx,
+				m.data.ghRepo,
+				m.data.ghToken,
+				m.data.firstRelease,
+				m.data.secondRelease,
+				m.data.ignoreRegex,
+				m.data.ignoreMode,
+				m.data.releaseOrder,
+				m.fetchProgress,
+			),
+		)
+	case StateDownloadExtract:
+		m.downloadProgress, m.downloadCacheCount, m.downloadResumeCount, m.downloadRepairCount = 0, 0, 0, 0
+		m.byteProgress = make(map[string]byteProgress)
+		if !m.headless {
+			// Headless runs don't render the progress
+			commands = append(commands, listenProgress)
+		}
+		m.downloadWarnings = make(map[string][]string)
+		m.budgetSkipped = nil
+		m.downloadErrors = make(map[string]error)
+		m.releaseStatuses = make(map[string]releaseStatus, len(m.data.releases))
+		for _, release := range m.data.releases {
+			m.releaseStatuses[release.TagName] = releasePending
+		}
+		m.data.extractLimits.Disk = NewDiskBudget(m.maxDisk)
+		if key := m.data.runKey(); m.manifest == nil || m.manifest.Key != key {
+			m.manifest = newRunManifest(key, m.data.releases, m.data.planReport)
+			checkpoint(m.manifest.write())
+		}
+		if *noExtract {
+			m.data.analysis = make([]AnalysisResult, len(m.data.releases))
+		} else {
+			tags := make([]string, len(m.data.releases))
+			for i, release := range m.data.releases {
+				tags[i] = release.TagName
+			}
+			dirs, err := planReleaseDirs(*extractionDir, m.data.dirTemplate, m.data.ghRepo, tags)
+			if err != nil {
+				m.failConfig(err)
+				return m, nil
+			}
+			m.data.releaseDirs = dirs
+		}
+		for _, release := range m.data.releases {
+			tag := release.TagName
+			if analysis, ok := m.manifest.analysis(tag); ok {
+				// Already analyzed by the interrupted run
+				m.downloadResumeCount++
+				commands = append(
+					commands, func() tea.Msg {
+						msg := gitReleaseDownloadedMsg{release: tag, cached: true}
+						if *noExtract {
+							msg.analysis = &analysis
+						}
+						return msg
+					},
+				)
+				continue
+			}
+			if *noExtract {
+				commands = append(commands, pool.limit(StreamGitHubRelease(m.ctx, tag, m.data.analysisSettings(), *analyzeTimeout)))
+			} else {
+				commands = append(commands, pool.limit(DownloadGitHubRelease(
+					m.ctx, tag, m.data.releaseDirs[tag], *onReleaseExtracted, m.data.cachePolicy, m.data.extractLimits,
+				)))
+			}
+		}
+	case StateAnalyzing:
+		m.list, m.liveSummary = nil, false
+		if m.data.baseline != nil {
+			// Only the local directory needs to be analyzed
+			m.data.releases = []Release{{TagName: m.data.secondRelease}, {TagName: m.data.firstRelease}}
+			m.data.analysis = []AnalysisResult{{}, *m.data.baseline}
+			commands = append(commands, AnalyzeDirectory(m.ctx, m.data.localDir, m.data.secondRelease, m.data.analysisSettings(), *analyzeTimeout))
+			break
+		}
+		if *noExtract {
+			// The releases were already analyzed while streaming
+			for _, analysis := range m.data.analysis {
+				analysis := analysis
+				commands = append(
+					commands, func() tea.Msg {
+						return analysisDoneMsg(analysis)
+					},
+				)
+			}
+			m.data.analysis = make([]AnalysisResult, len(m.data.releases))
+			break
+		}
+		m.data.analysis = make([]AnalysisResult, len(m.data.releases))
+		for _, release := range m.data.releases {
+			if err := m.downloadErrors[release.TagName]; err != nil {
+				analysis := downloadFailure(release.TagName, err)
+				commands = append(
+					commands, func() tea.Msg {
+						return analysisDoneMsg(analysis)
+					},
+				)
+				continue
+			}
+			if slices.Contains(m.budgetSkipped, release.TagName) {
+				analysis := failedAnalysis(release.TagName, "not downloaded, the disk budget was reached")
+				commands = append(
+					commands, func() tea.Msg {
+						return analysisDoneMsg(analysis)
+					},
+				)
+				continue
+			}
+			if analysis, ok := m.manifest.analysis(release.TagName); ok {
+				// Already analyzed by the interrupted run
+				commands = append(
+					commands, func() tea.Msg {
+						return analysisDoneMsg(analysis)
+					},
+				)
+				continue
+			}
+			commands = append(commands, pool.limit(AnalyzeRelease(m.ctx, m.data.releaseDirs[release.TagName], release.TagName, m.data.analysisSettings(), *analyzeTimeout)))
+		}
+	}
+
+	for i, command := range commands {
+		commands[i] = withRun(m.run, command)
+	}
+	return m, tea.Batch(commands...)
+}
+
+// retry restarts the phase that failed with the same inputs.
+func (m model) retry() (model, tea.Cmd) {
+	if m.errState == StateInit {
+		return m.edit()
+	}
+	return m.startPhase(m.errState)
+}
+
+// edit goes back to the inputs, pre-filled with the previous values.
+// The configuration is kept, and the rest of the data is reset to the values of the flags.
+func (m model) edit() (model, tea.Cmd) {
+	prefill := m.data
+	m.data = data{
+		config:        prefill.config,
+		ghRepo:        *ghRepo,
+		npmPackage:    *npmPackage,
+		firstRelease:  *firstRelease,
+		secondRelease: *secondRelease,
+		ignoreRegex:   *ignoreRegex,
+	}
+	if prefill.tokenSource != "" {
+		// Only typed tokens are asked again
+		m.data.ghToken = prefill.ghToken
+	}
+	m.run++ // Discard the messages of the previous run
+	m.state = StateInit
+	m.err = nil
+	m.focusIndex = 0
+	m.inputs = newInputs(prefill)
+	m.releaseChecks = nil
+	m.downloadProgress, m.downloadCacheCount, m.downloadRepairCount = 0, 0, 0
+	m.manifest = nil
+	m.list, m.files, m.timelinePath, m.noteInput, m.columnChooser = nil, nil, "", nil, nil
+	m.liveSummary = false
+	m.languagePicker, m.languageFilter, m.palette, m.detail = nil, nil, nil, nil
+	return m, nil
+}
+
+// releaseIndex returns the index of the release in m.data.releases, or -1.
+func (m model) releaseIndex(releaseTag string) int {
+	for i, release := range m.data.releases {
+		if release.TagName == releaseTag {
+			return i
+		}
+	}
+	return -1
+}
+
+// analyzedCount returns the number of releases whose analysis is done.
+func (m model) analyzedCount() int {
+	count := 0
+	for _, analysis := range m.data.analysis {
+		if analysis.releaseTag != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// canEdit returns whether the inputs can be edited after an error.
+func (m model) canEdit() bool {
+	return !m.errFatal && m.data.baseline == nil
 }
 
 func (m model) Init() tea.Cmd {
@@ -184,21 +1186,143 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	previousState := m.state
+	updated, cmd := m.update(msg)
+	m = updated.(model)
+	if *noNotify || m.headless {
+		return m, cmd
+	}
+
+	// Reflect the progress in the terminal title
+	commands := []tea.Cmd{cmd}
+	if title := m.windowTitle(); title != m.title {
+		m.title = title
+		commands = append(commands, tea.SetWindowTitle(title))
+	}
+	if m.state == StateSummary && previousState != StateSummary {
+		commands = append(commands, Notify(m.completionMessage()))
+	}
+	return m, tea.Batch(commands...)
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	case fatalErr:
-		time.Sleep(250 * time.Millisecond) // Wait for the view to render
-		os.Exit(1)
+	case runMsg:
+		if msg.run != m.run || m.err != nil {
+			// Discard messages from a cancelled or failed run
+			return m, nil
+		}
+		return m.update(msg.msg)
 	case model:
-		if m.state == StateInit && len(m.inputs) == 0 {
-			m.state++ // Move to StateChecking
-			_, spinCmd := m.spinner.Update(msg)
-			return m, tea.Batch(
-				spinCmd,
-				DoesGitHubReleaseExist(m.data.ghRepo, m.data.ghToken, m.data.firstRelease),
-				DoesGitHubReleaseExist(m.data.ghRepo, m.data.ghToken, m.data.secondRelease),
-			)
+		if m.err != nil {
+			break
+		}
+		if m.state == StateInit && (m.data.baseline != nil || len(m.inputs) == 0) {
+			// Nothing to ask for
+			return m.start()
+		}
+		if m.state == StateInit {
+			// The repository may be given while a release input is focused
+			return m.suggestTags()
+		}
+	case tea.KeyMsg:
+		if m.err != nil {
+			switch msg.String() {
+			case "r":
+				if !m.errFatal {
+					return m.retry()
+				}
+			case "e":
+				if m.canEdit() {
+					return m.edit()
+				}
+			case "q", "esc", "ctrl+c":
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if m.tokenInput != nil {
+			switch msg.Type {
+			case tea.KeyEnter:
+				if m.tokenInput.Value() == "" {
+					return m, nil
+				}
+				// Resume fetching with the new token
+				m.data.ghToken, m.data.tokenSource = m.tokenInput.Value(), ""
+				resumed, cmd := m.startPhase(StateFetching)
+				resumed.fetchProgress = nil
+				return resumed, cmd
+			case tea.KeyEsc, tea.KeyCtrlC:
+				return m, tea.Quit
+			}
+			input, cmd := m.tokenInput.Update(msg)
+			m.tokenInput = &input
+			return m, cmd
+		}
+		if m.rateLimited != nil {
+			switch msg.String() {
+			case "w":
+				if m.waitingReset {
+					break
+				}
+				return m.waitForReset()
+			case "q", "esc", "ctrl+c":
+				// Let the next run resume the fetching
+				if err := SavePartialFetch(m.data.ghRepo, m.data.firstRelease, m.data.secondRelease, *m.fetchProgress); err != nil {
+					log.Printf("could not save the partial fetch: %v", err)
+				}
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if m.confirmSameTarball {
+			switch msg.String() {
+			case "c":
+				m.confirmSameTarball = false
+				return m.advance(eventFetched)
+			case "a", "q", "esc", "ctrl+c":
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if m.state == StateSummary && m.noteInput != nil {
+			return m.updateNote(msg)
+		}
+		if m.state == StateSummary && m.files != nil {
+			return m.updateFiles(msg)
+		}
+		if m.state == StateSummary && m.columnChooser != nil {
+			return m.updateColumns(msg)
+		}
+		if m.state == StateSummary && m.languagePicker != nil {
+			return m.updateLanguagePicker(msg)
+		}
+		if m.state == StateSummary && m.palette != nil {
+			return m.updatePalette(msg)
+		}
+		if m.state == StateSummary && m.detail != nil {
+			return m.updateDetail(msg)
+		}
+		if m.state == StateSummary && m.list.FilterState() != list.Filtering {
+			for _, action := range summaryActions() {
+				if key.Matches(msg, action.binding) && action.available(m) {
+					return action.run(m)
+				}
+			}
+			switch {
+			case msg.Type == tea.KeyEnter && !m.showChart:
+				if item, ok := m.list.SelectedItem().(MonthItem); ok {
+					if m.expandedMonths == nil {
+						m.expandedMonths = make(map[string]bool)
+					}
+					m.expandedMonths[item.key()] = !item.expanded
+					return m, m.list.SetItems(m.sortedItems())
+				}
+				if item, ok := m.list.SelectedItem().(ListItem); ok {
+					return m.openDetail(item), nil
+				}
+			}
 		}
-	case tea.KeyMsg:
 		switch typ := msg.Type; typ {
 		case tea.KeyCtrlC, tea.KeyEsc:
 			if m.list != nil && m.list.FilterState() == list.Filtering && typ != tea.KeyCtrlC {
@@ -224,51 +1348,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.state != StateInit {
 				break
 			}
+			// The arrows and enter pick a tag while suggested
+			if typ == tea.KeyUp && m.moveSuggestion(-1) || typ == tea.KeyDown && m.moveSuggestion(1) {
+				return m, nil
+			}
+			if typ == tea.KeyEnter && m.pickSuggestion() {
+				return m, nil
+			}
 			// Did the user press enter while the "submit" button was focused?
 			if typ == tea.KeyEnter && m.focusIndex == len(m.inputs) {
 				// Get back the info from the inputs
-				inputIndex := 0
-				if m.data.ghRepo == "" {
-					m.data.ghRepo = m.inputs[inputIndex].Value()
-					if m.data.ghRepo == "" || strings.Count(m.data.ghRepo, "/") != 1 {
-						// Invalid GitHub repository format
-						m.err = fmt.Errorf("invalid GitHub repository format. Format: owner/repo")
-						break
-					}
-					inputIndex++
-
-					if m.data.ghToken == "" {
-						m.data.ghToken = m.inputs[inputIndex].Value()
-						inputIndex++
-					}
-				}
-				if m.data.firstRelease == "" {
-					m.data.firstRelease = m.inputs[inputIndex].Value()
-					if m.data.firstRelease == "" {
-						// Invalid first release
-						m.err = fmt.Errorf("invalid base release")
-						break
-					}
-					inputIndex++
-				}
-				if m.data.secondRelease == "" {
-					m.data.secondRelease = m.inputs[inputIndex].Value()
-					if m.data.secondRelease == "" {
-						// Invalid second release
-						m.err = fmt.Errorf("invalid release to compare to")
-						break
-					}
-					inputIndex++
-				}
-				if m.data.ignoreRegex == "" {
-					m.data.ignoreRegex = m.inputs[inputIndex].Value()
+				if err := m.submitInputs(); err != nil {
+					m.fail(err)
+					break
 				}
 
-				m.state++ // Move to StateChecking
-				return m, tea.Batch(
-					DoesGitHubReleaseExist(m.data.ghRepo, m.data.ghToken, m.data.firstRelease),
-					DoesGitHubReleaseExist(m.data.ghRepo, m.data.ghToken, m.data.secondRelease),
-				)
+				return m.start()
 			}
 
 			// Cycle indexes
@@ -298,12 +1393,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.inputs[i].PromptStyle = noStyle
 				m.inputs[i].Cursor.Style = noStyle
 			}
+			m.picker.selected = -1
+			var suggest tea.Cmd
+			m, suggest = m.suggestTags()
 
-			return m, tea.Batch(commands...)
+			return m, tea.Batch(append(commands, suggest)...)
 		default:
 			if m.state != StateInit {
 				break
 			}
+			// The typed text filters the suggested tags
+			m.picker.selected = -1
 			return m, func() tea.Cmd {
 				// Update all inputs
 				commands := make([]tea.Cmd, len(m.inputs))
@@ -311,85 +1411,135 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Only text inputs with Focus() set will respond, so it's safe to simply
 				// update all of them here without any further logic.
 				for i := range m.inputs {
-					m.inputs[i], commands[i] = m.inputs[i].Update(msg)
+					m.inputs[i].Model, commands[i] = m.inputs[i].Update(msg)
 				}
 
 				return tea.Batch(commands...)
 			}()
 		}
 	case errMsg:
-		m.err = msg
-	case gitReleaseExistsMsg:
-		if msg.exists {
-			m.existingReleasesCount++
-			if m.existingReleasesCount == 2 {
-				m.state++ // Move to StateFetching
-				_, spinCmd := m.spinner.Update(msg)
-				return m, tea.Batch(
-					spinCmd,
-					GetGitHubReleases(
-						m.data.ghRepo,
-						m.data.ghToken,
-						m.data.firstRelease,
-						m.data.secondRelease,
-						m.data.ignoreRegex,
-					),
-				)
-			}
-		} else {
-			m.err = fmt.Errorf(
-				"%s does not exist, check that you input an existing GitHub tag"+
-					" (check at https://github.com/%s/tags)", msg.release, m.data.ghRepo,
-			)
+		m.fail(msg)
+	case tagsFetchedMsg:
+		return m.handleTags(msg), nil
+	case tokenExpiredMsg:
+		// Pause the pipeline until a new token is provided
+		input := newTokenInput()
+		input.Placeholder = "New GitHub token"
+		input.Focus()
+		input.PromptStyle = svelteText
+		input.Cursor.Style = svelteText
+		m.tokenInput = &input
+		m.fetchProgress = &msg.progress
+		return m, textinput.Blink
+	case rateLimitedMsg:
+		// Pause the pipeline until the user decides to wait for the reset
+		log.Print(errRateLimited{msg.reset})
+		m.rateLimited, m.waitingReset = &msg, false
+		m.fetchProgress = &msg.progress
+		if (errRateLimited{msg.reset}).resetWait() <= autoBackoffLimit {
+			// Short limits, such as the secondary ones, are waited without asking
+			return m.waitForReset()
 		}
+		return m, nil
+	case fetchResumeMsg:
+		m.rateLimited, m.waitingReset = nil, false
+		resumed, cmd := m.startPhase(StateFetching)
+		resumed.fetchProgress = nil
+		return resumed, cmd
+	case gitReleaseExistsMsg:
+		return m.handleReleaseCheck(msg)
 	case gitReleasesDownloadSuccessMsg:
-		m.data.releases = msg
-		m.state++ // Move to StateDownloadExtract
+		m.data.releases = m.data.shard.releases(msg.releases)
+		m.data.planned = len(msg.releases)
+		m.data.planReport = msg.report
+		if len(msg.releases) == 0 {
+			m.fail(fmt.Errorf("no releases found (%s), please check your inputs", msg.report))
+			break
+		}
 		if len(m.data.releases) == 0 {
-			m.err = fmt.Errorf("no releases found, please check your inputs")
+			m.failConfig(fmt.Errorf("the shard %s of the %d planned releases is empty, use fewer shards", m.data.shard, m.data.planned))
 			break
 		}
-		_, spinCmd := m.spinner.Update(msg)
-		commands := make([]tea.Cmd, len(m.data.releases)+1)
-		commands[0] = spinCmd
-		for i, release := range m.data.releases {
-			commands[i+1] = DownloadGitHubRelease(
-				release.TagName, *extractionDir,
-			)
+		if msg.report.SameTarball != "" {
+			// Let the user decide whether the comparison is worth it
+			m.confirmSameTarball = true
+			break
+		}
+		return m.advance(eventFetched)
+	case downloadProgressMsg:
+		if m.state != StateDownloadExtract {
+			break
 		}
-		return m, tea.Batch(commands...)
+		if !m.byteProgress[msg.release].done {
+			m.byteProgress[msg.release] = msg.progress
+		}
+		return m, withRun(m.run, listenProgress)
 	case gitReleaseDownloadedMsg:
 		m.downloadProgress++
+		// Cached and resumed releases complete without downloading anything
+		download := m.byteProgress[msg.release]
+		download.done = true
+		m.byteProgress[msg.release] = download
 		if msg.cached {
 			m.downloadCacheCount++
 		}
-		if m.downloadProgress == uint(len(m.data.releases)) {
-			m.state++ // Move to StateAnalyzing
-			_, spinCmd := m.spinner.Update(msg)
-			analysis := make([]tea.Cmd, len(m.data.releases)+1)
-			analysis[0] = spinCmd
-			for i, release := range m.data.releases {
-				analysis[i+1] = AnalyzeRelease(*extractionDir, release.TagName)
-			}
-			return m, tea.Batch(analysis...)
+		if msg.repaired {
+			m.downloadRepairCount++
 		}
-	case analysisDoneMsg:
-		// Initialize the analysis slice if it's empty
-		if len(m.data.analysis) == 0 {
-			m.data.analysis = make([]AnalysisResult, len(m.data.releases))
+		if msg.skipped {
+			m.budgetSkipped = append(m.budgetSkipped, msg.release)
 		}
-		// Get index of the release in m.data.releases
-		index := -1
-		for i, release := range m.data.releases {
-			if release.TagName == msg.releaseTag {
-				index = i
-				break
+		m.setStatus(msg.release, releaseDownloaded)
+		if msg.err != nil {
+			log.Printf("%s: download failed: %v", msg.release, msg.err)
+			m.setStatus(msg.release, releaseFailed)
+			m.downloadErrors[msg.release] = msg.err
+			if *noExtract {
+				failure := downloadFailure(msg.release, msg.err)
+				msg.analysis = &failure
+			}
+		}
+		if msg.analysis != nil {
+			if index := m.releaseIndex(msg.release); index != -1 {
+				m.data.analysis[index] = *msg.analysis
 			}
 		}
+		if msg.warning != "" {
+			log.Printf("%s: %s", msg.release, msg.warning)
+			m.downloadWarnings[msg.release] = append(m.downloadWarnings[msg.release], msg.warning)
+		}
+		if msg.hookErr != nil {
+			log.Printf("%s: %v", msg.release, msg.hookErr)
+			m.downloadWarnings[msg.release] = append(m.downloadWarnings[msg.release], msg.hookErr.Error())
+		}
+		if m.manifest != nil && !msg.skipped && msg.err == nil {
+			checkpoint(m.manifest.markDownloaded(msg.release))
+		}
+		if m.downloadProgress == uint(len(m.data.releases)) {
+			return m.advance(eventDownloaded)
+		}
+	case analysisDoneMsg:
+		index := m.releaseIndex(msg.releaseTag)
 		if index == -1 {
 			break
 		}
+		for _, warning := range m.downloadWarnings[msg.releaseTag] {
+			msg.addWarning(warning)
+		}
+		for _, warning := range msg.warnings {
+			log.Printf("%s: %s", msg.releaseTag, warning)
+		}
+		m.setStatus(msg.releaseTag, releaseAnalyzed)
+		if msg.failed != "" {
+			log.Printf("%s: analysis failed: %s", msg.releaseTag, msg.failed)
+			m.setStatus(msg.releaseTag, releaseFailed)
+		}
 		m.data.analysis[index] = msg // Insert the analysis result
+		if m.manifest != nil && msg.failed == "" {
+			checkpoint(m.manifest.markAnalyzed(msg))
+		}
+		var liveCmd tea.Cmd
+		m, liveCmd = m.updateLiveSummary()
 
 		areAllAnalysesDone := true
 		for _, analysis := range m.data.analysis {
@@ -399,55 +1549,192 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		if areAllAnalysesDone {
+			// Save the compared release as a baseline
+			if m.data.baselineMode == BaselineWrite {
+				if err := m.data.writeBaseline(); err != nil {
+					m.fail(err)
+					break
+				}
+			}
+
+			// Warn about comparisons spanning a package rename or of another package
+			m.data.warnings = append(packageRenameWarnings(m.data.analysis), packageMismatchWarnings(m.data.analysis)...)
+			m.data.warnings = append(m.data.warnings, otherLanguageWarnings(m.data.analysis, m.data.otherThreshold)...)
+			if warning := versionOrderWarning(m.data.planReport.Inversions, m.data.planReport.ResolvedTo); warning != "" {
+				m.data.warnings = append(m.data.warnings, warning)
+			}
+			if *insecureSkipTLSVerify {
+				m.data.warnings = append([]string{insecureTLSWarning}, m.data.warnings...)
+			}
+			if len(m.budgetSkipped) > 0 {
+				m.data.warnings = append(
+					[]string{
+						fmt.Sprintf(
+							"disk budget of %s reached after %d/%d releases, the other ones were not downloaded",
+							m.data.extractLimits.Disk, len(m.data.releases)-len(m.budgetSkipped), len(m.data.releases),
+						),
+					},
+					m.data.warnings...,
+				)
+			}
+			for _, warning := range m.data.warnings {
+				log.Print(warning)
+			}
+
+			// The run is complete, there is nothing left to resume
+			if m.manifest != nil {
+				checkpoint(m.manifest.remove())
+				m.manifest = nil
+			}
+
 			// Remove the directory containing the extracted releases
 			if *remove {
 				if err := os.RemoveAll(*extractionDir); err != nil {
-					m.err = err
+					m.fail(err)
 					break
 				}
 			}
 
-			// Populate the list
-			items := make([]ListItem, len(m.data.analysis))
-			for i, analysis := range m.data.analysis {
-				item := ListItem{AnalysisResult: analysis}
-				if i > 0 {
-					item.next = &items[i-1]
+			// Load the notes about the releases
+			if m.data.ghRepo != "" {
+				notes, err := LoadNotes(m.data.ghRepo)
+				if err != nil {
+					log.Printf("could not load the release notes: %v", err)
 				}
-				items[i] = item
+				m.data.notes = notes
 			}
-			for i := len(items) - 1; i >= 0; i-- {
-				if i < len(items)-1 {
-					items[i].previous = &items[i+1]
+
+			m = m.buildSummary()
+
+			// Export the analysis results as JSON, before the summary is shown
+			if *jsonPath != "" {
+				order := m.data.exportOrder
+				content, err := EncodeJSONExport(m.data, order, m.exportedAnalysis(order))
+				if err == nil && *jsonPath != stdoutPath {
+					err = os.WriteFile(*jsonPath, content, 0644)
+				}
+				if err != nil {
+					m.fail(fmt.Errorf("could not write the JSON export: %w", err))
+					break
+				}
+				if *jsonPath == stdoutPath {
+					m.stdoutExports = append(m.stdoutExports, content...)
 				}
-			}
-			listItems := make([]list.Item, len(items))
-			for i, item := range items {
-				listItems[i] = item
 			}
 
-			// Create the list
-			l := list.New(listItems, list.NewDefaultDelegate(), 0, 0)
-			l.Title = "Releases comparison"
-			l.Styles.Title = svelteBg.Padding(0, 1)
-			l.Styles.FilterPrompt = svelteText
-			l.Styles.FilterCursor = svelteText // FIXME: Those two styles don't seem to work
-			m.list = &l
-			if m.wantedWidth != nil && m.wantedHeight != nil {
-				m.list.SetSize(*m.wantedWidth, *m.wantedHeight)
+			// Generate the report, before the summary is shown
+			if m.data.report == ReportMarkdown {
+				content := []byte(RenderMarkdownReport(m.data, m.exportedAnalysis(ExportChronological)))
+				if *reportOut == stdoutPath {
+					m.stdoutExports = append(m.stdoutExports, content...)
+				} else if err := os.WriteFile(*reportOut, content, 0644); err != nil {
+					m.fail(fmt.Errorf("could not write the report: %w", err))
+					break
+				}
 			}
 
-			m.state++ // Move to StateSummary
+			next, err := nextState(m.flow(), m.state, eventAnalyzed)
+			if err != nil {
+				m.fail(err)
+				break
+			}
+			m.state = next
+			m.finishedAt = m.now()
+			var commands []tea.Cmd
+			if *onComplete != "" {
+				order := m.data.exportOrder
+				commands = append(commands, RunCompletionHook(*onComplete, m.data, order, m.exportedAnalysis(order)))
+			}
+			if *csvPath != "" {
+				commands = append(commands, WriteCSVExport(*csvPath, m.data, m.data.exportOrder, m.exportedAnalysis(m.data.exportOrder)))
+			}
+			if len(m.data.exports) > 0 {
+				commands = append(commands, WriteExports(m.data.exports, m.data, m.exportedAnalysis(ExportChronological)))
+			}
+			if *verifySource && m.flow().fromGitHub() {
+				// Compare the endpoints to their tagged source
+				for _, analysis := range m.data.analysis {
+					if analysis.releaseTag == m.data.planReport.ResolvedFrom || analysis.releaseTag == m.data.planReport.ResolvedTo {
+						commands = append(
+							commands,
+							VerifySource(m.ctx, m.data.ghRepo, m.data.ghToken, analysis, m.data.sourceAllowlist),
+						)
+					}
+				}
+			}
+			if m.flow() != flowLocal {
+				// Look up the provenance of the releases, a few at a time
+				pool := newWorkerPool(m.concurrency)
+				for _, analysis := range m.data.analysis {
+					if analysis.failed == "" {
+						commands = append(commands, pool.limit(LookupProvenance(m.ctx, analysis.releaseTag)))
+					}
+				}
+			}
+			return m, tea.Batch(commands...)
+		}
+		return m, liveCmd
+	case noteSavedMsg:
+		if msg.err != nil {
+			log.Print(msg.err)
+			return m, m.list.NewStatusMessage(warningStyle.Render("Could not save the note: " + msg.err.Error()))
+		}
+		if m.data.notes == nil {
+			m.data.notes = make(map[string]string)
+		}
+		m.data.notes[msg.tag] = msg.note
+		return m, m.updateItem(
+			msg.tag, func(item *ListItem) {
+				item.note = msg.note
+			},
+		)
+	case exportDoneMsg:
+		if msg.err != nil {
+			log.Print(msg.err)
+			return m, m.list.NewStatusMessage(errorStyle.Render(msg.err.Error()))
+		}
+		return m, nil
+	case preferencesSavedMsg:
+		if msg.err != nil {
+			log.Print(msg.err)
+			return m, m.list.NewStatusMessage(warningStyle.Render("Could not save the preferences: " + msg.err.Error()))
+		}
+		return m, nil
+	case provenanceMsg:
+		return m.handleProvenance(msg)
+	case sourceVerifiedMsg:
+		if msg.err != nil {
+			log.Print(msg.err)
+			return m, m.list.NewStatusMessage(warningStyle.Render("Could not verify the source: " + msg.err.Error()))
+		}
+		if m.data.unverified == nil {
+			m.data.unverified = make(map[string][]string)
+		}
+		m.data.unverified[msg.release] = msg.suspicious
+		for _, file := range msg.suspicious {
+			log.Printf("%s: %s is absent from the tagged source", msg.release, file)
+		}
+		return m, m.updateItem(
+			msg.release, func(item *ListItem) {
+				item.unverified = msg.suspicious
+			},
+		)
+	case hookDoneMsg:
+		if msg.err == nil {
+			break
+		}
+		log.Print(msg.err)
+		if *strictHooks {
+			m.failConfig(msg.err)
+			break
 		}
-	case tea.WindowSizeMsg:
-		h, v := docStyle.GetFrameSize()
 		if m.list != nil {
-			m.wantedWidth, m.wantedHeight = nil, nil
-			m.list.SetSize(msg.Width-h, msg.Height-v)
-		} else {
-			wantedWidth, wantedHeight := msg.Width-h, msg.Height-v
-			m.wantedWidth, m.wantedHeight = &wantedWidth, &wantedHeight
+			return m, m.list.NewStatusMessage(warningStyle.Render(msg.err.Error()))
 		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.progressBar.Width = progressBarWidth(m.width)
+		m.resizeSummary()
 	default:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -460,20 +1747,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
-	if m.err != nil {
-		return m, func() tea.Msg {
-			return fatalErr{}
-		}
-	}
 	return m, nil
 }
 
 func (m model) View() string {
 	if m.err != nil {
-		return errorStyle.Render(fmt.Sprintf("Error: %v\n", m.err))
+		var hints []string
+		if !m.errFatal {
+			hints = append(hints, "r to retry")
+		}
+		if m.canEdit() {
+			hints = append(hints, "e to edit the inputs")
+		}
+		hints = append(hints, "q to quit")
+		return m.layout(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)), blurredStyle.Render(strings.Join(hints, " • ")))
 	}
 
 	var builder strings.Builder
+	var help string
 
 	switch m.state {
 	case StateInit:
@@ -483,68 +1774,590 @@ func (m model) View() string {
 				builder.WriteRune('\n')
 			}
 			builder.WriteString(m.inputs[i].View())
+			if m.inputs[i].field == fieldRepository && m.detectedRepo != "" && m.inputs[i].Value() == m.detectedRepo {
+				builder.WriteString(blurredStyle.Render(fmt.Sprintf(" (detected from %s)", m.detectedFrom)))
+			}
+			if i == m.focusIndex {
+				builder.WriteString(m.pickerView())
+			}
+		}
+
+		if m.data.tokenSource != "" {
+			builder.WriteString(
+				blurredStyle.Render(
+					fmt.Sprintf("\n\nUsing the GitHub token from %s (%s)", m.data.tokenSource, maskToken(m.data.ghToken)),
+				),
+			)
 		}
 
 		button := "[ Submit ]"
 		if m.focusIndex == len(m.inputs) {
 			button = svelteText.Render(button)
 		}
-		_, err := fmt.Fprintf(&builder, "\n\n%s\n\n", button)
+		_, err := fmt.Fprintf(&builder, "\n\n%s\n", button)
 		if err != nil {
 			return ""
 		}
 
-		builder.WriteString(blurredStyle.Render("cursor mode is "))
-		builder.WriteString(blurredSvelteText.Render(m.cursorMode.String()))
-		builder.WriteString(blurredStyle.Render(fmt.Sprintf(" (%s to change style)", tea.KeyCtrlR.String())))
+		if len(m.suggestions()) > 0 {
+			help = blurredStyle.Render("↑/↓ to pick a recent tag • enter to select it • ")
+		}
+		help += blurredStyle.Render("cursor mode is ") +
+			blurredSvelteText.Render(m.cursorMode.String()) +
+			blurredStyle.Render(fmt.Sprintf(" (%s to change style)", tea.KeyCtrlR.String()))
 	case StateChecking:
-		if m.existingReleasesCount < 2 {
+		if len(m.releaseChecks) < 2 {
 			builder.WriteString(fmt.Sprintf("\n   %s Checking if releases exist...\n", m.spinner.View()))
+			if quota := githubQuota.String(); quota != "" {
+				builder.WriteString(blurredStyle.Render("     "+quota) + "\n")
+			}
 		}
 	case StateFetching:
-		if m.data.releases == nil {
+		if m.tokenInput != nil {
+			builder.WriteString(
+				warningStyle.Render(
+					fmt.Sprintf(
+						"\n   The GitHub token expired after fetching %d releases, please provide a new one to resume\n\n",
+						len(m.fetchProgress.releases),
+					),
+				),
+			)
+			builder.WriteString("   " + m.tokenInput.View() + "\n")
+			help = blurredStyle.Render("   enter to resume • esc to quit")
+		} else if m.rateLimited != nil {
+			builder.WriteString(
+				warningStyle.Render(
+					fmt.Sprintf(
+						"\n   %s after fetching %d releases (page %d)\n",
+						errRateLimited{m.rateLimited.reset}, len(m.fetchProgress.releases), m.fetchProgress.page,
+					),
+				),
+			)
+			quit := fmt.Sprintf(
+				"q to quit, the fetched releases being resumed by a run within %.0f minutes", partialFetchWindow.Minutes(),
+			)
+			if m.waitingReset {
+				builder.WriteString(
+					fmt.Sprintf(
+						"\n   %s Waiting for the rate limit to reset, resuming in %s...\n",
+						m.spinner.View(), m.resumeAt.Sub(m.now()).Round(time.Second),
+					),
+				)
+				help = blurredStyle.Render("   " + quit)
+			} else {
+				help = blurredStyle.Render("   w to wait for the reset and resume • " + quit)
+			}
+		} else if m.data.releases == nil {
 			builder.WriteString(fmt.Sprintf("\n   %s Fetching releases...\n", m.spinner.View()))
+			if quota := githubQuota.String(); quota != "" {
+				builder.WriteString(blurredStyle.Render("     "+quota) + "\n")
+			}
+		} else if m.confirmSameTarball {
+			builder.WriteString(
+				warningStyle.Render(
+					fmt.Sprintf(
+						"\n   Both endpoints resolve to %s — nothing to compare",
+						m.data.planReport.SameTarball,
+					),
+				),
+			)
+			help = blurredStyle.Render(
+				fmt.Sprintf(
+					"   %d releases in range • c to continue anyway • a to abort",
+					len(m.data.releases),
+				),
+			)
 		}
 	case StateDownloadExtract:
+		action := "extracting"
+		if *noExtract {
+			action = "analyzing"
+		}
 		builder.WriteString(
 			fmt.Sprintf(
-				"\n   %s Downloading and extracting releases (%d/%d",
+				"\n   %s Downloading and %s releases (%d/%d",
 				m.spinner.View(),
+				action,
 				m.downloadProgress,
 				len(m.data.releases),
 			),
 		)
-		if m.downloadCacheCount > 0 {
-			builder.WriteString(fmt.Sprintf(" - %d cached", m.downloadCacheCount))
+		if m.downloadResumeCount > 0 {
+			builder.WriteString(fmt.Sprintf(" - %d resumed", m.downloadResumeCount))
+		}
+		if cached := m.downloadCacheCount - m.downloadResumeCount; cached > 0 {
+			builder.WriteString(fmt.Sprintf(" - %d cached", cached))
+		}
+		if m.downloadRepairCount > 0 {
+			builder.WriteString(fmt.Sprintf(" - %d incomplete re-downloaded", m.downloadRepairCount))
 		}
+		builder.WriteString(m.failedText())
 		builder.WriteString(")...\n")
-		builder.WriteString(
-			blurredStyle.Render(
-				fmt.Sprintf("     Downloaded versions are available in the `%s/` directory", *extractionDir),
-			),
-		)
+		builder.WriteString(m.downloadProgressView())
+		if !*noExtract {
+			builder.WriteString(
+				blurredStyle.Render(
+					fmt.Sprintf("     Downloaded versions are available in the `%s/` directory", *extractionDir),
+				),
+			)
+		}
 	case StateAnalyzing:
+		if m.liveSummary {
+			builder.WriteString(
+				docStyle.Render(lipgloss.JoinVertical(lipgloss.Left, m.summaryHeader(m.list.Width()), m.list.View())),
+			)
+			break
+		}
 		builder.WriteString(
 			fmt.Sprintf(
-				"\n   %s Analyzing releases (%d/%d)...\n",
+				"\n   %s Analyzing releases (%d/%d%s)...\n",
 				m.spinner.View(),
-				len(m.data.analysis),
+				m.analyzedCount(),
 				len(m.data.releases),
+				m.failedText(),
 			),
 		)
 	case StateSummary:
-		builder.WriteString(docStyle.Render(m.list.View()))
+		content := m.list.View()
+		switch {
+		case m.files != nil && m.timelinePath != "":
+			content = renderFileTimeline(m.timelinePath, fileTimeline(m.data.chronological(), m.timelinePath))
+		case m.files != nil:
+			content = m.files.View()
+		case m.columnChooser != nil:
+			content = m.columnChooser.view(m.preferences.Columns)
+		case m.languagePicker != nil:
+			content = m.languagePicker.view()
+		case m.palette != nil:
+			content = m.palette.view()
+		case m.detail != nil:
+			content = m.detail.view()
+		case m.showChart:
+			content = m.chartView()
+		}
+		builder.WriteString(
+			docStyle.Render(
+				lipgloss.JoinVertical(
+					lipgloss.Left,
+					m.summaryHeader(m.list.Width()),
+					content,
+				),
+			),
+		)
+		if m.noteInput != nil {
+			help = "  " + m.noteInput.View()
+		}
+	}
+
+	return m.layout(builder.String(), help)
+}
+
+// updateItem updates the summary list item of a release.
+func (m *model) updateItem(tag string, update func(item *ListItem)) tea.Cmd {
+	for i, item := range m.items {
+		if item, ok := item.(ListItem); ok && item.releaseTag == tag {
+			update(&item)
+			m.items[i] = item
+		}
+	}
+	return m.list.SetItems(m.sortedItems())
+}
+
+// updateLanguagePicker handles a key while choosing the language filter:
+// enter applies the filter of the highlighted language, x clears it, and esc closes the picker.
+func (m model) updateLanguagePicker(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q", "L":
+		m.languagePicker = nil
+	case "up", "k":
+		if m.languagePicker.cursor > 0 {
+			m.languagePicker.cursor--
+		}
+	case "down", "j":
+		if m.languagePicker.cursor < len(m.languagePicker.languages)-1 {
+			m.languagePicker.cursor++
+		}
+	case "+", "=":
+		if m.languagePicker.threshold < len(languageFilterThresholds)-1 {
+			m.languagePicker.threshold++
+		}
+	case "-":
+		if m.languagePicker.threshold > 0 {
+			m.languagePicker.threshold--
+		}
+	case "enter":
+		if filter, ok := m.languagePicker.filter(); ok {
+			m.languageFilter = &filter
+		}
+		m.languagePicker = nil
+		m.list.Title = m.listTitle()
+		return m, m.list.SetItems(m.sortedItems())
+	case "x":
+		m.languageFilter, m.languagePicker = nil, nil
+		m.list.Title = m.listTitle()
+		return m, m.list.SetItems(m.sortedItems())
+	}
+	return m, nil
+}
+
+// updateNote handles a key while annotating a release:
+// enter saves the note, an empty note removing it, and esc cancels.
+func (m model) updateNote(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.noteInput = nil
+		m.resizeSummary()
+		return m, nil
+	case tea.KeyEnter:
+		repo, tag, note := m.data.ghRepo, m.noteTag, strings.TrimSpace(m.noteInput.Value())
+		m.noteInput = nil
+		m.resizeSummary()
+		return m, func() tea.Msg {
+			return noteSavedMsg{tag, note, SaveNote(repo, tag, note)}
+		}
+	}
+	input, cmd := m.noteInput.Update(msg)
+	m.noteInput = &input
+	return m, cmd
+}
+
+// updateFiles handles a key while browsing the files of a release:
+// enter shows the timeline of the selected file, and esc goes back.
+func (m model) updateFiles(msg tea.KeyMsg) (model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	if m.timelinePath != "" {
+		if msg.Type == tea.KeyEsc || msg.Type == tea.KeyBackspace {
+			m.timelinePath = ""
+		}
+		return m, nil
+	}
+	if m.files.FilterState() != list.Filtering {
+		switch msg.Type {
+		case tea.KeyEsc:
+			if m.files.FilterState() == list.Unfiltered {
+				m.files = nil
+				return m, nil
+			}
+		case tea.KeyEnter:
+			if item, ok := m.files.SelectedItem().(FileItem); ok {
+				m.timelinePath = item.path
+			}
+			return m, nil
+		}
+	}
+	files, cmd := m.files.Update(msg)
+	m.files = &files
+	return m, cmd
+}
+
+// updateColumns handles a key while choosing the description columns:
+// space toggles the highlighted column, re-rendering the list and saving
+// the preferences, and esc closes the chooser.
+func (m model) updateColumns(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "enter", "C", "q":
+		m.columnChooser = nil
+	case "up", "k":
+		if m.columnChooser.cursor > 0 {
+			m.columnChooser.cursor--
+		}
+	case "down", "j":
+		if m.columnChooser.cursor < len(descriptionColumns)-1 {
+			m.columnChooser.cursor++
+		}
+	case " ", "x":
+		name := descriptionColumns[m.columnChooser.cursor].name
+		m.preferences.Columns = toggleColumn(m.preferences.Columns, name)
+		for i, item := range m.items {
+			if item, ok := item.(ListItem); ok {
+				item.columns = m.preferences.Columns
+				m.items[i] = item
+			}
+		}
+		return m, tea.Batch(m.list.SetItems(m.sortedItems()), SavePreferences(m.preferences))
+	}
+	return m, nil
+}
+
+// buildSummary populates the summary list from the analysis results,
+// with the preferences and the pins of the user, and resolves the
+// anchors, the cadence and the runtime transitions shown above it.
+func (m model) buildSummary() model {
+	// The live summary, if shown, keeps its preferences and selection
+	selected := ""
+	if m.liveSummary {
+		if item, ok := m.list.SelectedItem().(ListItem); ok {
+			selected = item.releaseTag
+		}
+		m.liveSummary = false
+	} else {
+		m.loadSummaryPreferences()
+	}
+
+	// Populate the list
+	m.items = m.summaryItems(m.data.analysis, m.data.releases)
+
+	// Resolve the anchors of the compared release
+	if len(m.data.releases) == len(m.data.analysis) {
+		m.data.anchors = m.data.resolveAnchors()
+		m.data.cadence = cadenceStats(m.data.cadencePoints())
+	}
+	m.data.transitions = m.data.runtimeTransitions()
+
+	// Create the list
+	m.list = m.newSummaryList()
+	m.resizeSummary()
+	if selected != "" {
+		for i, item := range m.list.Items() {
+			if item, ok := item.(ListItem); ok && item.releaseTag == selected {
+				m.list.Select(i)
+			}
+		}
+	}
+	return m
+}
+
+// loadSummaryPreferences loads the description columns chosen by the user and the pinned releases.
+func (m *model) loadSummaryPreferences() {
+	preferences, err := LoadPreferences()
+	if err != nil {
+		log.Printf("could not load the preferences: %v", err)
 	}
+	m.preferences = preferences
+	m.pinned = make(map[string]bool)
+	for _, tag := range m.preferences.Pins[m.data.ghRepo] {
+		m.pinned[tag] = true
+	}
+}
+
+// summaryItems returns the summary list items of the analysis results and their releases,
+// both ordered from the newest to the oldest, each item linked to its neighbors.
+func (m model) summaryItems(analyses []AnalysisResult, releases []Release) []list.Item {
+	items := make([]ListItem, len(analyses))
+	for i, analysis := range analyses {
+		item := ListItem{
+			AnalysisResult: analysis,
+			note:           m.data.notes[analysis.releaseTag],
+			unverified:     m.data.unverified[analysis.releaseTag],
+			columns:        m.preferences.Columns,
+			pinned:         m.pinned[analysis.releaseTag],
+			endpoint:       m.data.endpointOf(analysis.releaseTag),
+		}
+		if i < len(releases) {
+			item.reactions = releases[i].Reactions
+		}
+		if i > 0 {
+			item.next = &items[i-1]
+		}
+		items[i] = item
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		if i < len(items)-1 {
+			items[i].previous = &items[i+1]
+			items[i].packagingChange = packagingChangeSuspected(
+				items[i+1].AnalysisResult, items[i].AnalysisResult, m.data.densityThreshold,
+			)
+			items[i].mostlyFormatting = formattingChange(
+				items[i+1].AnalysisResult, items[i].AnalysisResult, m.data.formattingThreshold,
+			)
+			items[i].dominantFile = dominantFile(
+				items[i+1].AnalysisResult, items[i].AnalysisResult, m.data.dominantFileThreshold,
+			)
+		}
+	}
+	listItems := make([]list.Item, len(items))
+	for i, item := range items {
+		listItems[i] = item
+	}
+	return listItems
+}
+
+// newSummaryList creates the summary list of the items.
+func (m model) newSummaryList() *list.Model {
+	l := list.New(m.sortedItems(), list.NewDefaultDelegate(), 0, 0)
+	l.Title = m.listTitle()
+	l.Styles.Title = svelteBg.Padding(0, 1)
+	l.Styles.FilterPrompt = svelteText
+	l.Styles.FilterCursor = svelteText // FIXME: Those two styles don't seem to work
+	l.AdditionalShortHelpKeys = summaryBindings
+	l.AdditionalFullHelpKeys = summaryBindings
+	l.Filter = pinnedFilter(m.pinned, m.data.firstRelease, m.data.secondRelease)
+	return &l
+}
+
+// summaryHeader renders the lines shown above the summary list:
+// the analysis settings, the release cadence, the warnings about
+// the comparison, the changes of the engines and of the package manager,
+// the anchors panel if any anchor was resolved, then the biggest jumps between releases.
+func (m model) summaryHeader(width int) string {
+	if m.liveSummary {
+		return m.liveHeader(width)
+	}
+	header := lipgloss.JoinVertical(
+		lipgloss.Left,
+		blurredStyle.MaxWidth(width).Render(m.data.settingsLine()),
+		blurredStyle.MaxWidth(width).Render(m.data.cadence.String()),
+	)
+	for _, warning := range m.data.warnings {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, warningStyle.Width(width).Render("⚠ "+warning))
+	}
+	for _, transition := range m.data.transitions {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, svelteText.Width(width).Render("⚙ "+transition))
+	}
+	if panel := m.data.anchorsPanel(); panel != "" {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, lipgloss.NewStyle().MaxWidth(width).Render(panel))
+	}
+	if panel := spikesPanel(m.data.biggestJumps(m.data.spikeCount)); panel != "" {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, lipgloss.NewStyle().Width(width).Render(panel))
+	}
+	return header
+}
+
+// sortedItems returns the summary list items in the current sort order, or grouped by month,
+// the pinned releases first.
+func (m model) sortedItems() []list.Item {
+	if m.groupByMonth {
+		return m.monthItems()
+	}
+	return pinFirst(m.sort.sort(m.visibleItems()))
+}
+
+// togglePin pins the release, or unpins it, and saves the pins of the repository.
+func (m model) togglePin(tag string) (model, tea.Cmd) {
+	// The filter of the list reads the map, so it is updated in place
+	if m.pinned[tag] {
+		delete(m.pinned, tag)
+	} else {
+		m.pinned[tag] = true
+	}
+	pinned := m.pinned[tag]
+	cmd := m.updateItem(
+		tag, func(item *ListItem) {
+			item.pinned = pinned
+		},
+	)
+	if m.data.ghRepo == "" {
+		return m, cmd
+	}
+	pins := make(map[string][]string, len(m.preferences.Pins)+1)
+	for repo, tags := range m.preferences.Pins {
+		pins[repo] = tags
+	}
+	if tags := pinnedTags(m.pinned); len(tags) > 0 {
+		pins[m.data.ghRepo] = tags
+	} else {
+		delete(pins, m.data.ghRepo)
+	}
+	m.preferences.Pins = pins
+	return m, tea.Batch(cmd, SavePreferences(m.preferences))
+}
+
+// chartView renders the chart of the analysis results, sized like the list.
+func (m model) chartView() string {
+	results := m.data.chronological()
+
+	title := "Releases growth"
+	if m.normalizedChart {
+		title += " (normalized to " + results[0].releaseTag + ")"
+	}
+	if *topFiles > 0 {
+		title += fmt.Sprintf(" ≈ approximate, top %d files", *topFiles)
+	}
+	help := fmt.Sprintf(
+		"%s %s • %s %s",
+		summaryKeys.chart.Help().Key, summaryKeys.chart.Help().Desc,
+		summaryKeys.normalize.Help().Key, summaryKeys.normalize.Help().Desc,
+	)
+	chartHeight := m.list.Height() - 4 // Title, help and their margins
 
-	return builder.String()
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		svelteBg.Padding(0, 1).Render(title),
+		"",
+		RenderChart(
+			results, chartSeriesList, spikeTags(m.data.biggestJumps(m.data.spikeCount)),
+			m.normalizedChart, m.list.Width(), chartHeight,
+		),
+		"",
+		blurredStyle.Render(help),
+	)
 }
 
 var _ tea.Model = (*model)(nil)
 
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	initial := initialModel()
+	if *headless {
+		os.Exit(runHeadless(initial, os.Stderr))
+	}
+
+	p := tea.NewProgram(initial, tea.WithAltScreen())
+	saveTerminalTitle()
+	finalModel, err := p.Run()
+	restoreTerminalTitle()
+	if m, ok := finalModel.(model); ok {
+		// Abort the requests in flight, leaving no partial extraction behind
+		m.cancel()
+		waitDownloads(downloadsCleanupTimeout)
+	}
+	if err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, "Error running program:", err)
 		os.Exit(1)
 	}
+
+	if m, ok := finalModel.(model); ok {
+		if m.err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "Error:", m.err)
+			os.Exit(1)
+		}
+
+		// Print the warnings and the JSON export once the alt screen is gone
+		printDiagnostics(m)
+		_, _ = os.Stdout.Write(m.stdoutExports)
+		if err := m.data.checkLargeFiles(*failIfLargeFiles); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(exitLargeFiles)
+		}
+	}
+}
+
+// printDiagnostics prints the warnings about the comparison, the failed analyses,
+// the files absent from the tagged source and the warnings of each release to stderr.
+func printDiagnostics(m model) {
+	for _, warning := range m.data.warnings {
+		_, _ = fmt.Fprintln(os.Stderr, "Warning:", warning)
+	}
+	for _, analysis := range m.data.analysis {
+		if analysis.failed != "" {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: analysis failed: %s\n", analysis.releaseTag, analysis.failed)
+		}
+	}
+	for _, analysis := range m.data.analysis {
+		if files := m.data.unverified[analysis.releaseTag]; len(files) > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %d file(s) absent from the tagged source\n", analysis.releaseTag, len(files))
+			for _, file := range files {
+				_, _ = fmt.Fprintln(os.Stderr, "  "+file)
+			}
+		}
+	}
+	for _, analysis := range m.data.analysis {
+		if analysis.warningsCount == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %d warning(s)\n", analysis.releaseTag, analysis.warningsCount)
+		for _, warning := range analysis.warnings {
+			_, _ = fmt.Fprintln(os.Stderr, "  "+warning)
+		}
+		if hidden := analysis.warningsCount - uint(len(analysis.warnings)); hidden > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "  ... and %d more\n", hidden)
+		}
+	}
 }
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runManifestVersion is the version of the run manifest format.
+// Manifests of other versions are ignored, and the run starts over.
+const runManifestVersion = 1
+
+// runsDirName is the name of the directory of the run manifests,
+// within the extraction directory.
+const runsDirName = ".runs"
+
+// ReleaseStatus is the progress of a planned release within a run.
+type ReleaseStatus string
+
+const (
+	// ReleasePending means the release is neither downloaded nor analyzed.
+	ReleasePending ReleaseStatus = "pending"
+	// ReleaseDownloaded means the release is downloaded but not analyzed.
+	ReleaseDownloaded ReleaseStatus = "downloaded"
+	// ReleaseAnalyzed means the release is analyzed.
+	ReleaseAnalyzed ReleaseStatus = "analyzed"
+)
+
+// RunManifest records the planned releases of a run and their progress,
+// so that an interrupted run can be resumed by a later invocation
+// with identical inputs.
+type RunManifest struct {
+	Version  int                  `json:"version"`  // Version of the manifest format
+	Key      string               `json:"key"`      // Hash of the effective inputs and settings of the run
+	Releases []Release            `json:"releases"` // Planned releases, from the newest to the oldest
+	Report   PlanReport           `json:"report"`   // Report of the selection of the releases
+	Entries  map[string]*RunEntry `json:"entries"`  // Progress of the planned releases, by tag
+}
+
+// RunEntry is the progress of a planned release.
+type RunEntry struct {
+	Status   ReleaseStatus `json:"status"`             // Progress of the release
+	Analysis *Baseline     `json:"analysis,omitempty"` // Analysis result, once analyzed
+}
+
+// runKey returns the hash of the effective inputs and settings of a run.
+func (d data) runKey() string {
+	hash := sha256.Sum256(
+		[]byte(
+			strings.Join(
+				[]string{
+					appVersion,
+					d.ghRepo,
+					d.firstRelease,
+					d.secondRelease,
+					d.npmPackage,
+					d.analysisSettings().String(),
+				}, "\n",
+			),
+		),
+	)
+	return hex.EncodeToString(hash[:])
+}
+
+// runManifestPath returns the path of the run manifest of a run key.
+func runManifestPath(key string) string {
+	return filepath.Join(*extractionDir, runsDirName, key[:16]+".json")
+}
+
+// newRunManifest creates the run manifest of planned releases, all pending.
+func newRunManifest(key string, releases []Release, report PlanReport) *RunManifest {
+	manifest := &RunManifest{
+		Version:  runManifestVersion,
+		Key:      key,
+		Releases: releases,
+		Report:   report,
+		Entries:  make(map[string]*RunEntry, len(releases)),
+	}
+	for _, release := range releases {
+		manifest.Entries[release.TagName] = &RunEntry{Status: ReleasePending}
+	}
+	return manifest
+}
+
+// ReadRunManifest reads the run manifest of a run key.
+func ReadRunManifest(key string) (*RunManifest, error) {
+	path := runManifestPath(key)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest RunManifest
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid run manifest %s: %w", path, err)
+	}
+	if manifest.Version != runManifestVersion || manifest.Key != key {
+		return nil, fmt.Errorf("run manifest %s doesn't match the current run", path)
+	}
+	return &manifest, nil
+}
+
+// write atomically writes the run manifest, so that it is never left half-written.
+func (r *RunManifest) write() error {
+	content, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	path := runManifestPath(r.Key)
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return writeFileAtomically(path, content)
+}
+
+// remove removes the run manifest once the run is complete.
+func (r *RunManifest) remove() error {
+	if err := os.Remove(runManifestPath(r.Key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// status returns the progress of a planned release.
+func (r *RunManifest) status(tag string) ReleaseStatus {
+	if entry, ok := r.Entries[tag]; ok {
+		return entry.Status
+	}
+	return ReleasePending
+}
+
+// analysis returns the analysis result of an analyzed release.
+func (r *RunManifest) analysis(tag string) (AnalysisResult, bool) {
+	entry, ok := r.Entries[tag]
+	if !ok || entry.Status != ReleaseAnalyzed || entry.Analysis == nil {
+		return AnalysisResult{}, false
+	}
+	return entry.Analysis.analysisResult(), true
+}
+
+// markDownloaded records that a release is downloaded, unless it is already analyzed.
+func (r *RunManifest) markDownloaded(tag string) error {
+	entry, ok := r.Entries[tag]
+	if !ok || entry.Status == ReleaseAnalyzed {
+		return nil
+	}
+	entry.Status = ReleaseDownloaded
+	return r.write()
+}
+
+// markAnalyzed records the analysis result of a release.
+func (r *RunManifest) markAnalyzed(analysis AnalysisResult) error {
+	entry, ok := r.Entries[analysis.releaseTag]
+	if !ok {
+		return nil
+	}
+	baseline := newBaseline(analysis)
+	entry.Status, entry.Analysis = ReleaseAnalyzed, &baseline
+	return r.write()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// notMeasuredText is the rendering of a metric that was not measured.
+const notMeasuredText = "n/a"
+
+// measure is a metric of a release that may not have been measured, such as
+// the lines of a release whose analysis failed, or the tarball size of a release
+// extracted by a version of the application that did not record it.
+// A metric that was not measured is rendered as "n/a" and exported as null,
+// rather than as a misleading 0.
+type measure[T int | int64 | float64] struct {
+	value    T
+	measured bool
+}
+
+// measured returns the measure of a metric that was measured.
+func measured[T int | int64 | float64](value T) measure[T] {
+	return measure[T]{value: value, measured: true}
+}
+
+// since returns the difference from the previous measure, not measured if either is not.
+func (m measure[T]) since(previous measure[T]) measure[T] {
+	if !m.measured || !previous.measured {
+		return measure[T]{}
+	}
+	return measured(m.value - previous.value)
+}
+
+// format renders the value with the format, or "n/a" if it was not measured.
+func (m measure[T]) format(format string) string {
+	if !m.measured {
+		return notMeasuredText
+	}
+	return fmt.Sprintf(format, m.value)
+}
+
+func (m measure[T]) MarshalJSON() ([]byte, error) {
+	if !m.measured {
+		return []byte("null"), nil
+	}
+	return json.Marshal(m.value)
+}
+
+// measuredLines returns the number of lines of the release, not measured if its analysis failed.
+func (a AnalysisResult) measuredLines() measure[int] {
+	if a.failed != "" {
+		return measure[int]{}
+	}
+	return measured(int(a.totalLines))
+}
+
+// measuredFiles returns the number of files of the release, not measured if its analysis failed.
+func (a AnalysisResult) measuredFiles() measure[int] {
+	if a.failed != "" {
+		return measure[int]{}
+	}
+	return measured(int(a.totalFiles))
+}
+
+// measuredTarSize returns the size of the gzipped tarball of the release,
+// not measured if it is unknown.
+func (a AnalysisResult) measuredTarSize() measure[int64] {
+	if a.tarSize == 0 {
+		return measure[int64]{}
+	}
+	return measured(int64(a.tarSize))
+}
+
+// measuredDirSize returns the unpacked size of the release,
+// not measured if it is unknown or its analysis failed.
+func (a AnalysisResult) measuredDirSize() measure[int64] {
+	if a.failed != "" || a.totalDirSize <= 0 {
+		return measure[int64]{}
+	}
+	return measured(a.totalDirSize)
+}
+
+// measuredDensity returns the number of lines per unpacked kilobyte of the release,
+// not measured if its size is unknown or its analysis failed.
+func (a AnalysisResult) measuredDensity() measure[float64] {
+	density, ok := a.density()
+	if a.failed != "" || !ok {
+		return measure[float64]{}
+	}
+	return measured(density)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMeasureSince(t *testing.T) {
+	ok := testAnalysis("v1.0.0", 1000)
+	failed := failedAnalysis("v1.1.0", "download failed: 404 Not Found")
+	bigger := testAnalysis("v2.0.0", 1500)
+	for _, test := range []struct {
+		name              string
+		previous, current measure[int]
+		diff              string
+		json              string
+	}{
+		{"in both", ok.measuredLines(), bigger.measuredLines(), "+500", "500"},
+		{"decreasing", bigger.measuredLines(), ok.measuredLines(), "-500", "-500"},
+		{"unchanged", ok.measuredLines(), ok.measuredLines(), "+0", "0"},
+		{"only before", ok.measuredLines(), failed.measuredLines(), notMeasuredText, "null"},
+		{"only after", failed.measuredLines(), bigger.measuredLines(), notMeasuredText, "null"},
+		{"in neither", failed.measuredLines(), failed.measuredLines(), notMeasuredText, "null"},
+		{"zero values", measured(0), measure[int]{}, notMeasuredText, "null"},
+	} {
+		diff := test.current.since(test.previous)
+		if formatted := diff.format("%+d"); formatted != test.diff {
+			t.Errorf("%s: formatted %q, expected %q", test.name, formatted, test.diff)
+		}
+		encoded, err := json.Marshal(diff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != test.json {
+			t.Errorf("%s: encoded %s, expected %s", test.name, encoded, test.json)
+		}
+	}
+}
+
+func TestMeasureFormat(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		formatted string
+		expected  string
+	}{
+		{"int", measured(42).format("%d lines"), "42 lines"},
+		{"zero", measured(0).format("%d"), "0"},
+		{"int64", measured(int64(1) << 40).format("%d"), "1099511627776"},
+		{"float", measured(1.25).format("%.1f"), "1.2"},
+		{"not measured", measure[float64]{}.format("%.1f"), notMeasuredText},
+		{"failed lines", failedAnalysis("v1.0.0", "timed out").measuredLines().format("%d"), notMeasuredText},
+		{"failed files", failedAnalysis("v1.0.0", "timed out").measuredFiles().format("%d"), notMeasuredText},
+		{"unknown tarball size", AnalysisResult{}.measuredTarSize().format("%d"), notMeasuredText},
+		// The tarball size is known even if the analysis failed
+		{"failed tarball size", AnalysisResult{failed: "timed out", tarSize: 10}.measuredTarSize().format("%d"), "10"},
+		{"failed directory size", AnalysisResult{failed: "timed out", totalDirSize: 10}.measuredDirSize().format("%d"), notMeasuredText},
+	} {
+		if test.formatted != test.expected {
+			t.Errorf("%s: formatted %q, expected %q", test.name, test.formatted, test.expected)
+		}
+	}
+}
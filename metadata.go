@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// metadataFileName is the name of the metadata file written
+// at the root of each extracted release directory.
+const metadataFileName = "metadata.json"
+
+// ExtractionMetadata documents an extracted release for external tooling.
+// Its presence marks an extraction as complete, and is what the cache relies on.
+type ExtractionMetadata struct {
+	Tag          string           `json:"tag"`             // GitHub release tag
+	Package      string           `json:"package"`         // Resolved npm package name
+	Version      string           `json:"version"`         // Resolved npm package version
+	RegistryURL  string           `json:"registry_url"`    // URL the tarball was downloaded from
+	Shasum       string           `json:"shasum"`          // SHA-1 checksum of the tarball, as reported by npm
+	TarSize      uint64           `json:"tar_size"`        // Size of the tarball in bytes
+	DownloadedAt time.Time        `json:"downloaded_at"`   // Time the tarball was downloaded at
+	ToolVersion  string           `json:"tool_version"`    // Version of the program that extracted the release
+	Files        map[string]int64 `json:"files,omitempty"` // Sizes of the extracted files by path
+}
+
+// WriteExtractionMetadata atomically writes the metadata file of an extracted release.
+func WriteExtractionMetadata(releaseDir string, metadata ExtractionMetadata) error {
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that the metadata file is either complete or missing
+	return writeFileAtomically(filepath.Join(releaseDir, metadataFileName), content)
+}
+
+// invalidFor returns why the metadata doesn't mark a complete extraction of the release,
+// empty if it does: a metadata file written for another release, such as one sharing
+// its directory through --dir-template, or without the size of the extracted tarball.
+func (m ExtractionMetadata) invalidFor(release string) string {
+	switch {
+	case m.Tag != release:
+		return fmt.Sprintf("extraction metadata of %s instead", m.Tag)
+	case m.TarSize == 0:
+		return "no tarball size in the extraction metadata"
+	default:
+		return ""
+	}
+}
+
+// ReadExtractionMetadata reads the metadata file of an extracted release.
+func ReadExtractionMetadata(releaseDir string) (ExtractionMetadata, error) {
+	var metadata ExtractionMetadata
+	content, err := os.ReadFile(filepath.Join(releaseDir, metadataFileName))
+	if err != nil {
+		return metadata, err
+	}
+	err = json.Unmarshal(content, &metadata)
+	return metadata, err
+}
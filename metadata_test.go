@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// metadataSchema is the metadata file of an extraction as read by external tooling:
+// a change of the struct that alters it breaks them, and must be made on purpose.
+const metadataSchema = `{
+  "tag": "pkg@1.2.3",
+  "package": "pkg",
+  "version": "1.2.3",
+  "registry_url": "https://registry.npmjs.org/pkg/-/pkg-1.2.3.tgz",
+  "shasum": "0123456789abcdef0123456789abcdef01234567",
+  "tar_size": 1234,
+  "downloaded_at": "2024-06-01T12:00:00Z",
+  "tool_version": "1.0.0",
+  "files": {
+    "package/index.js": 100,
+    "package/package.json": 20
+  }
+}`
+
+// testMetadata is the metadata of metadataSchema.
+func testMetadata() ExtractionMetadata {
+	return ExtractionMetadata{
+		Tag:          "pkg@1.2.3",
+		Package:      "pkg",
+		Version:      "1.2.3",
+		RegistryURL:  "https://registry.npmjs.org/pkg/-/pkg-1.2.3.tgz",
+		Shasum:       "0123456789abcdef0123456789abcdef01234567",
+		TarSize:      1234,
+		DownloadedAt: time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC),
+		ToolVersion:  "1.0.0",
+		Files:        map[string]int64{"package/index.js": 100, "package/package.json": 20},
+	}
+}
+
+func TestExtractionMetadataSchema(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteExtractionMetadata(dir, testMetadata()); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, metadataFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != metadataSchema {
+		t.Errorf("the metadata file changed:\n%s\nexpected:\n%s", content, metadataSchema)
+	}
+}
+
+func TestReadExtractionMetadata(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, metadataFileName), []byte(metadataSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := ReadExtractionMetadata(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(metadata, testMetadata()) {
+		t.Errorf("got %+v, expected %+v", metadata, testMetadata())
+	}
+	if reason := metadata.invalidFor("pkg@1.2.3"); reason != "" {
+		t.Errorf("the metadata is invalid for its release: %s", reason)
+	}
+	if reason := metadata.invalidFor("pkg@1.2.4"); reason == "" {
+		t.Error("the metadata is valid for another release")
+	}
+}
+
+func TestExtractionMetadataWithoutFiles(t *testing.T) {
+	dir := t.TempDir()
+	metadata := testMetadata()
+	metadata.Files = nil
+	if err := WriteExtractionMetadata(dir, metadata); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, metadataFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), `"files"`) {
+		t.Errorf("the metadata file lists the files without any:\n%s", content)
+	}
+}
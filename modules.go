@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// packageJSONName is the name of the npm package manifest, whose `type` field
+// sets the module system of the `.js` files of its directory and subdirectories.
+const packageJSONName = "package.json"
+
+// isPackageJSON returns whether the slash-separated path is a package manifest.
+func isPackageJSON(file string) bool {
+	return path.Base(file) == packageJSONName
+}
+
+// packageManifest holds the fields of a package manifest used by the analysis.
+type packageManifest struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Dependencies   map[string]string `json:"dependencies"`
+	Engines        json.RawMessage   `json:"engines"` // Legacy manifests may have an array of engines
+	PackageManager string            `json:"packageManager"`
+}
+
+// engines returns the supported versions of the engines of the manifest, by engine,
+// ignoring the legacy array form.
+func (m packageManifest) engines() map[string]string {
+	var engines map[string]string
+	if err := json.Unmarshal(m.Engines, &engines); err != nil {
+		return nil
+	}
+	return engines
+}
+
+// readPackageManifest reads the fields of a package manifest used by the analysis.
+func readPackageManifest(content []byte) (packageManifest, error) {
+	var manifest packageManifest
+	err := json.Unmarshal(content, &manifest)
+	return manifest, err
+}
+
+// nearestPackageType returns the `type` field of the package manifest the nearest
+// to the slash-separated file path, types holding the `type` fields of the manifests
+// by directory. Manifests without a `type` field are nearest too, and resolve to "".
+func nearestPackageType(types map[string]string, file string) string {
+	dir := path.Dir(file)
+	for {
+		if packageType, ok := types[dir]; ok {
+			return packageType
+		}
+		if dir == "." || dir == "/" {
+			return ""
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// addPackageJSON records the `type` field of the package manifest at the
+// slash-separated path, to resolve the module system of the `.js` files,
+// and the package name, dependencies, engines and package manager
+// if it is the root manifest of the release.
+// Invalid manifests are reported as warnings.
+func (a *AnalysisResult) addPackageJSON(file string, content []byte) {
+	manifest, err := readPackageManifest(content)
+	if err != nil {
+		a.addWarning(fmt.Sprintf("%s: %v", file, err))
+	}
+	if a.packageTypes == nil {
+		a.packageTypes = make(map[string]string)
+	}
+	a.packageTypes[path.Dir(file)] = manifest.Type
+	if normalizeReleasePath(file) == packageJSONName {
+		a.packageName = manifest.Name
+		a.dependencies = manifest.Dependencies
+		a.engines = manifest.engines()
+		a.packageManager = manifest.PackageManager
+	}
+}
+
+// readPackageJSON reads a package manifest from the reader, records its `type` field,
+// and returns a reader over its content to count its lines.
+func (a *AnalysisResult) readPackageJSON(file string, reader io.Reader) (io.Reader, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	a.addPackageJSON(file, content)
+	return strings.NewReader(string(content)), nil
+}
+
+// addModule classifies the module system of an analyzed JavaScript file
+// from its extension. The module system of `.js` files is resolved once
+// every package manifest is known, by resolveModules.
+func (a *AnalysisResult) addModule(file, extension string) {
+	switch strings.ToLower(extension) {
+	case ".mjs":
+		a.esmFiles++
+	case ".cjs":
+		a.cjsFiles++
+	case ".js":
+		a.bareJSFiles = append(a.bareJSFiles, file)
+	}
+}
+
+// resolveModules resolves the module system of the `.js` files
+// from the `type` field of their nearest package manifest.
+func (a *AnalysisResult) resolveModules() {
+	for _, file := range a.bareJSFiles {
+		if nearestPackageType(a.packageTypes, file) == "module" {
+			a.esmFiles++
+		} else {
+			a.cjsFiles++
+		}
+	}
+	a.bareJSFiles, a.packageTypes = nil, nil
+}
+
+// moduleShare returns the share of ESM files among the JavaScript files,
+// and false if there is no JavaScript file.
+func (a AnalysisResult) moduleShare() (float64, bool) {
+	if a.esmFiles+a.cjsFiles == 0 {
+		return 0, false
+	}
+	return float64(a.esmFiles) / float64(a.esmFiles+a.cjsFiles), true
+}
+
+// modules describes the ESM and CJS files of the release,
+// along with the trend of the ESM share since the previous release.
+func (l ListItem) modules() string {
+	share, ok := l.moduleShare()
+	if !ok {
+		return ""
+	}
+	description := fmt.Sprintf("ESM %d / CJS %d (%.0f%% ESM)", l.esmFiles, l.cjsFiles, share*100)
+	if l.previous != nil {
+		if previous, ok := l.previous.moduleShare(); ok {
+			switch {
+			case share > previous:
+				description += " ↑"
+			case share < previous:
+				description += " ↓"
+			}
+		}
+	}
+	return description
+}
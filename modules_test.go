@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path"
+	"testing"
+)
+
+func TestNearestPackageType(t *testing.T) {
+	types := map[string]string{
+		"package":            "module",
+		"package/lib/legacy": "commonjs",
+		"package/lib/plain":  "",
+		"/abs/package":       "module",
+	}
+	for _, test := range []struct {
+		file        string
+		packageType string
+	}{
+		// At the root of a package
+		{"package/index.js", "module"},
+		{"package/lib/legacy/index.js", "commonjs"},
+		// Nested under a package
+		{"package/lib/a.js", "module"},
+		{"package/lib/legacy/deep/nested/a.js", "commonjs"},
+		// A manifest without a `type` field is the nearest one
+		{"package/lib/plain/a.js", ""},
+		{"package/lib/plain/deep/a.js", ""},
+		// A directory only sharing a prefix with a package isn't within it
+		{"package/lib/legacy-compat/a.js", "module"},
+		{"packages/a.js", ""},
+		// No package.json above
+		{"index.js", ""},
+		{"other/index.js", ""},
+		{"/abs/other/index.js", ""},
+		{"/abs/package/dist/index.js", "module"},
+	} {
+		if packageType := nearestPackageType(types, test.file); packageType != test.packageType {
+			t.Errorf("%s: got %q, expected %q", test.file, packageType, test.packageType)
+		}
+	}
+	if packageType := nearestPackageType(nil, "package/index.js"); packageType != "" {
+		t.Errorf("got %q without any manifest", packageType)
+	}
+}
+
+func TestResolveModules(t *testing.T) {
+	result := newAnalysisResult("pkg@1.0.0")
+	result.addPackageJSON("package/package.json", []byte(`{"name": "pkg", "type": "module"}`))
+	result.addPackageJSON("package/lib/legacy/package.json", []byte(`{"type": "commonjs"}`))
+	for _, file := range []string{"package/index.js", "package/lib/a.js", "package/lib/legacy/index.js", "package/a.mjs", "package/b.cjs"} {
+		result.addModule(file, path.Ext(file))
+	}
+	// Extensions are compared case-insensitively
+	result.addModule("package/c.MJS", ".MJS")
+	result.resolveModules()
+	if result.esmFiles != 4 || result.cjsFiles != 2 {
+		t.Errorf("got %d ESM and %d CJS files, expected 4 and 2", result.esmFiles, result.cjsFiles)
+	}
+	if result.packageName != "pkg" {
+		t.Errorf("got the package name %q from the root manifest, expected pkg", result.packageName)
+	}
+}
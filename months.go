@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// datedAnalysis is the analysis result of a release with its publication date.
+type datedAnalysis struct {
+	date     time.Time // Publication date of the release, zero if unknown
+	analysis AnalysisResult
+}
+
+// monthGroup is the releases published during a calendar month.
+type monthGroup struct {
+	month    time.Time        // First instant of the month, zero for the releases without a date
+	releases []AnalysisResult // Releases of the month, from the oldest to the newest
+	netDiff  measure[int]     // Change of lines from the last release before the month to the last one of the month
+	unit     string           // Unit of the change of lines, see lineChange
+}
+
+// key returns the identifier of the month, such as "2024-03".
+func (g monthGroup) key() string {
+	if g.month.IsZero() {
+		return "undated"
+	}
+	return g.month.Format("2006-01")
+}
+
+// groupByMonth buckets releases by month of publication, from the newest month to the oldest,
+// each month once whatever the order of the releases, such as by version with `--order semver`.
+// The releases of a month are ordered from the oldest to the newest by date. Months without any release
+// are absent, and releases without a date are grouped last, in their given order. The net difference
+// of a month is counted from the last release of the previous group, or from
+// the first release of the month for the oldest one.
+func groupByMonth(releases []datedAnalysis) []monthGroup {
+	// Stable, for the releases of the same date to keep their order
+	releases = slices.Clone(releases)
+	slices.SortStableFunc(
+		releases, func(a, b datedAnalysis) int {
+			return a.date.Compare(b.date)
+		},
+	)
+	var groups []monthGroup
+	var undated []AnalysisResult
+	for _, release := range releases {
+		if release.date.IsZero() {
+			undated = append(undated, release.analysis)
+			continue
+		}
+		year, month, _ := release.date.UTC().Date()
+		start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		if len(groups) == 0 || !groups[len(groups)-1].month.Equal(start) {
+			groups = append(groups, monthGroup{month: start})
+		}
+		group := &groups[len(groups)-1]
+		group.releases = append(group.releases, release.analysis)
+	}
+
+	for i := range groups {
+		base := groups[i].releases[0]
+		if i > 0 {
+			previous := groups[i-1].releases
+			base = previous[len(previous)-1]
+		}
+		last := groups[i].releases[len(groups[i].releases)-1]
+		groups[i].netDiff, groups[i].unit = lineChange(base, last)
+	}
+
+	// From the newest month to the oldest, like the releases of the summary
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+	if len(undated) > 0 {
+		groups = append(groups, monthGroup{releases: undated})
+	}
+	return groups
+}
+
+// MonthItem is a month of releases in the summary list grouped by month.
+type MonthItem struct {
+	monthGroup
+	expanded bool // Whether the releases of the month are listed below it
+}
+
+func (m MonthItem) Title() string {
+	arrow := "▸"
+	if m.expanded {
+		arrow = "▾"
+	}
+	title := fmt.Sprintf("%s %s · %d release", arrow, m.key(), len(m.releases))
+	if len(m.releases) > 1 {
+		title += "s"
+	}
+	if m.month.IsZero() {
+		return title
+	}
+	return title + "  " + textForUnitDiff(m.netDiff, m.unit)
+}
+
+func (m MonthItem) Description() string {
+	last := m.releases[len(m.releases)-1]
+	if last.failed != "" {
+		return fmt.Sprintf("latest %s • not measured, the analysis failed", last.releaseTag)
+	}
+	return fmt.Sprintf("latest %s • %d files • %d lines", last.releaseTag, last.totalFiles, last.totalLines)
+}
+
+func (m MonthItem) FilterValue() string {
+	return m.key()
+}
+
+var _ list.DefaultItem = (*MonthItem)(nil)
+
+// datedAnalysis returns the analysis results with their publication dates,
+// from the oldest to the newest.
+func (d data) datedAnalysis() []datedAnalysis {
+	return d.withDates(d.chronological())
+}
+
+// withDates returns the analysis results with their publication dates, in the same order.
+func (d data) withDates(analysis []AnalysisResult) []datedAnalysis {
+	dates := make(map[string]time.Time, len(d.releases))
+	for _, release := range d.releases {
+		dates[release.TagName] = releaseDate(release)
+	}
+	dated := make([]datedAnalysis, len(analysis))
+	for i, result := range analysis {
+		dated[i] = datedAnalysis{dates[result.releaseTag], result}
+	}
+	return dated
+}
+
+// monthItems returns the summary list items grouped by month, each expanded
+// month being followed by the items of its releases, from the newest to the oldest,
+// the pinned ones first.
+func (m model) monthItems() []list.Item {
+	items := make(map[string]ListItem, len(m.items))
+	for _, item := range m.visibleItems() {
+		if item, ok := item.(ListItem); ok {
+			items[item.releaseTag] = item
+		}
+	}
+	var grouped []list.Item
+	for _, group := range groupByMonth(m.data.datedAnalysis()) {
+		expanded := m.expandedMonths[group.key()]
+		grouped = append(grouped, MonthItem{group, expanded})
+		if !expanded {
+			continue
+		}
+		var releases []list.Item
+		for i := len(group.releases) - 1; i >= 0; i-- {
+			// Releases not matching the language filter are hidden
+			if item, ok := items[group.releases[i].releaseTag]; ok {
+				releases = append(releases, item)
+			}
+		}
+		grouped = append(grouped, pinFirst(releases)...)
+	}
+	return grouped
+}
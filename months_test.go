@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGroupByMonthSemverOrder(t *testing.T) {
+	// The releases of backportedReleases, ordered from the lowest version to the highest
+	var releases []datedAnalysis
+	for _, tag := range []string{"v4.2.19", "v4.2.20", "v5.0.0-rc.1", "v5.0.0", "v5.1.0"} {
+		for _, release := range backportedReleases() {
+			if release.TagName == tag {
+				releases = append(releases, datedAnalysis{releaseDate(release), testAnalysis(tag, 1000)})
+			}
+		}
+	}
+	releases = append(releases, datedAnalysis{time.Time{}, testAnalysis("local", 1000)})
+
+	groups := groupByMonth(releases)
+	var keys []string
+	for _, group := range groups {
+		keys = append(keys, group.key())
+	}
+	if want := []string{"2024-03", "2024-02", "2024-01", "undated"}; !slices.Equal(keys, want) {
+		t.Fatalf("months %v, want %v", keys, want)
+	}
+	var january []string
+	for _, analysis := range groups[2].releases {
+		january = append(january, analysis.releaseTag)
+	}
+	if want := []string{"v5.0.0-rc.1", "v5.0.0", "v4.2.19"}; !slices.Equal(january, want) {
+		t.Errorf("releases of 2024-01 %v, want %v, by date", january, want)
+	}
+}
+
+func TestGroupByMonthNetDiff(t *testing.T) {
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	releases := []datedAnalysis{
+		{day(time.January, 1), testAnalysis("v1.0.0", 1000)},
+		{day(time.January, 20), testAnalysis("v1.1.0", 1100)},
+		{day(time.March, 3), testAnalysis("v2.0.0", 1500)},
+	}
+	groups := groupByMonth(releases)
+	if len(groups) != 2 {
+		t.Fatalf("%d months, want 2: %+v", len(groups), groups)
+	}
+	// Code lines, the releases being counted by kind
+	if march := groups[0]; march.netDiff != measured(320) || march.unit != "code lines" {
+		t.Errorf("net change of 2024-03: %v %s, want +320 code lines from v1.1.0", march.netDiff, march.unit)
+	}
+	if january := groups[1]; january.netDiff != measured(80) {
+		t.Errorf("net change of 2024-01: %v, want +80 code lines from v1.0.0", january.netDiff)
+	}
+}
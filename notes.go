@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const (
+	// appDirName is the name of the directory of the application in the user data directory.
+	appDirName = "npm-stats-comparator"
+	// notesFileName is the name of the file of the release notes in the application directory.
+	notesFileName = "notes.json"
+	// notesLockTimeout is the maximum duration to wait for another run to release the notes.
+	notesLockTimeout = 5 * time.Second
+	// notesLockStale is the age after which a lock left by a crashed run is ignored.
+	notesLockStale = 30 * time.Second
+)
+
+// errNotesLocked is returned when the notes are locked by another run for too long.
+var errNotesLocked = errors.New("the release notes are locked by another run")
+
+// noteSavedMsg is a message that carries the result of saving the note of a release.
+type noteSavedMsg struct {
+	tag  string
+	note string
+	err  error
+}
+
+// NotesStore holds the notes of the releases by repository and tag,
+// persisted in the user data directory.
+type NotesStore map[string]map[string]string
+
+// userDataDir returns the directory of the application data of the user:
+// $XDG_DATA_HOME or ~/.local/share on Unix, and the user config directory elsewhere.
+func userDataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appDirName), nil
+	}
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "share", appDirName), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName), nil
+}
+
+// notesPath returns the path of the notes file.
+func notesPath() (string, error) {
+	dir, err := userDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, notesFileName), nil
+}
+
+// LoadNotes loads the notes of the releases of a repository, by tag.
+// A missing notes file means there is no note yet.
+func LoadNotes(repo string) (map[string]string, error) {
+	path, err := notesPath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := readNotesStore(path)
+	if err != nil {
+		return nil, err
+	}
+	notes := store[repo]
+	if notes == nil {
+		notes = make(map[string]string)
+	}
+	return notes, nil
+}
+
+// SaveNote saves the note of a release of a repository, an empty note removing it.
+// The notes file is locked while it is read, updated and atomically rewritten,
+// so that concurrent runs don't lose each other's notes.
+func SaveNote(repo, tag, note string) error {
+	path, err := notesPath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := readNotesStore(path)
+	if err != nil {
+		return err
+	}
+	if note == "" {
+		delete(store[repo], tag)
+		if len(store[repo]) == 0 {
+			delete(store, repo)
+		}
+	} else {
+		if store[repo] == nil {
+			store[repo] = make(map[string]string)
+		}
+		store[repo][tag] = note
+	}
+
+	content, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, content)
+}
+
+// readNotesStore reads the notes file, returning an empty store if it doesn't exist.
+func readNotesStore(path string) (NotesStore, error) {
+	store := make(NotesStore)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, &store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// lockFile acquires an exclusive lock by creating the lock file, waiting for
+// another holder to release it, and returns the function releasing it.
+// Locks older than notesLockStale are considered left by a crashed run.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(notesLockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_ = file.Close()
+			return func() {
+				_ = os.Remove(path)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > notesLockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errNotesLocked
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
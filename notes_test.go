@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestSaveNote(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if notes, err := LoadNotes("owner/repo"); err != nil || len(notes) != 0 {
+		t.Fatalf("LoadNotes without a notes file = %v, %v, expected no note", notes, err)
+	}
+	for _, note := range []struct{ repo, tag, note string }{
+		{"owner/repo", "v1.0.0", "switched bundler to rollup 4"},
+		{"owner/repo", "v1.1.0", "accidental publish"},
+		{"owner/other", "v1.0.0", "first release"},
+		{"owner/repo", "v1.1.0", ""},
+	} {
+		if err := SaveNote(note.repo, note.tag, note.note); err != nil {
+			t.Fatal(err)
+		}
+	}
+	notes, err := LoadNotes("owner/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes["v1.0.0"] != "switched bundler to rollup 4" {
+		t.Errorf("notes of owner/repo = %v, expected only the note of v1.0.0", notes)
+	}
+	if err := SaveNote("owner/other", "v1.0.0", ""); err != nil {
+		t.Fatal(err)
+	}
+	path, err := notesPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := readNotesStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store["owner/other"]; ok {
+		t.Errorf("owner/other kept in the store without notes: %v", store)
+	}
+}
+
+func TestSaveNoteConcurrently(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	const runs = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			repo := "owner/repo"
+			if i%2 == 1 {
+				repo = "owner/other"
+			}
+			errs <- SaveNote(repo, fmt.Sprintf("v1.%d.0", i), fmt.Sprintf("note %d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, err := notesPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var store NotesStore
+	if err := json.Unmarshal(content, &store); err != nil {
+		t.Fatalf("the notes file doesn't parse: %v\n%s", err, content)
+	}
+	for i := 0; i < runs; i++ {
+		repo := "owner/repo"
+		if i%2 == 1 {
+			repo = "owner/other"
+		}
+		notes, err := LoadNotes(repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag := fmt.Sprintf("v1.%d.0", i)
+		if expected := fmt.Sprintf("note %d", i); notes[tag] != expected {
+			t.Errorf("note of %s %s = %q, expected %q", repo, tag, notes[tag], expected)
+		}
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("the lock file is left after the saves: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// pushTitleSequence saves the current terminal title on the terminal title stack.
+	pushTitleSequence = "\x1b[22;0t"
+	// popTitleSequence restores the terminal title saved on the terminal title stack.
+	popTitleSequence = "\x1b[23;0t"
+)
+
+// isTerminal returns whether the file is attached to a terminal.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// saveTerminalTitle saves the terminal title so that it can be restored on exit.
+func saveTerminalTitle() {
+	if !*noNotify && isTerminal(os.Stdout) {
+		_, _ = fmt.Fprint(os.Stdout, pushTitleSequence)
+	}
+}
+
+// restoreTerminalTitle restores the terminal title saved by saveTerminalTitle.
+func restoreTerminalTitle() {
+	if !*noNotify && isTerminal(os.Stdout) {
+		_, _ = fmt.Fprint(os.Stdout, popTitleSequence)
+	}
+}
+
+// Notify rings the terminal bell and sends a desktop notification
+// through the OSC 9 escape sequence, for terminals supporting it.
+func Notify(message string) tea.Cmd {
+	return func() tea.Msg {
+		if isTerminal(os.Stderr) {
+			_, _ = fmt.Fprintf(os.Stderr, "\a\x1b]9;%s\x07", message)
+		}
+		return nil
+	}
+}
+
+// windowTitle returns the terminal title reflecting the progress of the model.
+func (m model) windowTitle() string {
+	const prefix = "npm-stats"
+	if m.err != nil {
+		return prefix + " failed"
+	}
+	switch m.state {
+	case StateChecking:
+		return prefix + " checking"
+	case StateFetching:
+		return prefix + " fetching"
+	case StateDownloadExtract:
+		return fmt.Sprintf("%s %d/%d", prefix, m.downloadProgress, len(m.data.releases))
+	case StateAnalyzing:
+		return fmt.Sprintf("%s analyzing %d/%d", prefix, m.analyzedCount(), len(m.data.releases))
+	case StateSummary:
+		return prefix + " done"
+	default:
+		return prefix
+	}
+}
+
+// completionMessage returns the notification message sent once the comparison is done,
+// with the change of lines between the oldest and the newest release if it was measured.
+func (m model) completionMessage() string {
+	subject := m.data.ghRepo
+	if subject == "" {
+		subject = m.data.secondRelease
+	}
+	message := fmt.Sprintf("Comparison of %s finished", subject)
+	if len(m.data.analysis) > 1 {
+		newest, oldest := m.data.analysis[0], m.data.analysis[len(m.data.analysis)-1]
+		if diff := newest.measuredLines().since(oldest.measuredLines()); diff.measured {
+			message += fmt.Sprintf(": %s lines", formatLinesDiff(diff.value))
+		}
+	}
+	return message
+}
+
+// formatLinesDiff formats a signed number of lines in a short form, e.g. +102k.
+func formatLinesDiff(diff int) string {
+	sign := "+"
+	if diff < 0 {
+		sign = "-"
+		diff = -diff
+	}
+	switch {
+	case diff >= 1_000_000:
+		return fmt.Sprintf("%s%.1fM", sign, float64(diff)/1_000_000)
+	case diff >= 1_000:
+		return fmt.Sprintf("%s%dk", sign, diff/1_000)
+	default:
+		return fmt.Sprintf("%s%d", sign, diff)
+	}
+}
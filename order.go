@@ -0,0 +1,136 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// ReleaseOrder is how the releases are ordered, from the newest to the oldest,
+// to select the ones between the endpoints and chain the comparisons.
+type ReleaseOrder string
+
+const (
+	// OrderDate orders the releases by creation date.
+	OrderDate ReleaseOrder = "date"
+	// OrderSemver orders the releases by semantic version, see compareReleaseVersions.
+	OrderSemver ReleaseOrder = "semver"
+)
+
+// ParseReleaseOrder parses the value of the `--order` flag.
+func ParseReleaseOrder(value string) (ReleaseOrder, error) {
+	switch order := ReleaseOrder(value); order {
+	case OrderDate, OrderSemver:
+		return order, nil
+	default:
+		return "", fmt.Errorf("invalid release order %q, expected %s or %s", value, OrderDate, OrderSemver)
+	}
+}
+
+// compareReleaseDates compares two releases by creation date, the newest first.
+func compareReleaseDates(a, b Release) int {
+	return cmp.Compare(b.CreatedAt.Unix(), a.CreatedAt.Unix())
+}
+
+// compareReleaseVersions compares two releases by semantic version, the highest first.
+// The releases without a semantic version come after the other ones,
+// and the releases of the same version or without one are compared by creation date.
+func compareReleaseVersions(a, b Release) int {
+	aVersion, aOK := releaseSemver(a.TagName)
+	bVersion, bOK := releaseSemver(b.TagName)
+	switch {
+	case aOK && !bOK:
+		return -1
+	case !aOK && bOK:
+		return 1
+	case aOK && bVersion.less(aVersion):
+		return -1
+	case aOK && aVersion.less(bVersion):
+		return 1
+	}
+	return compareReleaseDates(a, b)
+}
+
+// comparator returns the comparison of the releases in the order, the newest first.
+func (o ReleaseOrder) comparator() func(a, b Release) int {
+	if o == OrderSemver {
+		return compareReleaseVersions
+	}
+	return compareReleaseDates
+}
+
+// versionInversion is a release published after a release of a higher semantic version,
+// such as a backport to an older release line.
+type versionInversion struct {
+	Release string // Tag of the release published later
+	After   string // Tag of the release of a higher version published before it
+}
+
+// versionInversions returns the releases, ordered from the newest to the oldest by date,
+// that have a lower semantic version than the release published just before them,
+// if both have one and are of the same npm package.
+func versionInversions(releases []Release) []versionInversion {
+	var inversions []versionInversion
+	for i := 0; i+1 < len(releases); i++ {
+		newer, older := releases[i].TagName, releases[i+1].TagName
+		if tagPackage(newer) != tagPackage(older) {
+			continue
+		}
+		newerVersion, newerOK := releaseSemver(newer)
+		olderVersion, olderOK := releaseSemver(older)
+		if newerOK && olderOK && newerVersion.less(olderVersion) {
+			inversions = append(inversions, versionInversion{newer, older})
+		}
+	}
+	return inversions
+}
+
+// versionOrderWarning returns a warning about the releases published out of the order of their versions,
+// whose comparisons interleave release lines, suggesting to order them by version or to ignore
+// the release line of the first inversion other than the one of the `to` release.
+// It returns an empty string without inversions.
+func versionOrderWarning(inversions []versionInversion, to string) string {
+	if len(inversions) == 0 {
+		return ""
+	}
+	first := inversions[0]
+	warning := fmt.Sprintf(
+		"releases are published out of the order of their versions (%d inversions by date, such as %s after %s), "+
+			"interleaving release lines in the comparisons: consider --order %s",
+		len(inversions), first.Release, first.After, OrderSemver,
+	)
+	toVersion, ok := releaseSemver(to)
+	if !ok {
+		return warning
+	}
+	for _, inversion := range inversions {
+		for _, tag := range []string{inversion.Release, inversion.After} {
+			if version, ok := releaseSemver(tag); ok && version.major != toVersion.major {
+				return warning + fmt.Sprintf(
+					", or isolate the release line of %s with --ignore-mode %s --ignore '%s%d.*'",
+					to, IgnoreModeGlob, tagVersionPrefix(tag), version.major,
+				)
+			}
+		}
+	}
+	return warning
+}
+
+// tagPackage returns the npm package of a `package@version` release tag, or an empty string for a plain version tag.
+func tagPackage(tag string) string {
+	if name, version := npmPackageVersion(tag); version != "" {
+		return name
+	}
+	return ""
+}
+
+// tagVersionPrefix returns what precedes the version in a release tag, such as `v` or `package@`.
+func tagVersionPrefix(tag string) string {
+	if _, version := npmPackageVersion(tag); version != "" {
+		return strings.TrimSuffix(tag, version)
+	}
+	if strings.HasPrefix(tag, "v") {
+		return "v"
+	}
+	return ""
+}
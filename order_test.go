@@ -0,0 +1,114 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// backportedReleases are the releases of a repository backporting patches to its previous major line,
+// ordered from the newest to the oldest by date.
+func backportedReleases() []Release {
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	return []Release{
+		testRelease("v4.2.20", day(time.March, 5)),
+		testRelease("v5.1.0", day(time.February, 1)),
+		testRelease("v4.2.19", day(time.January, 20)),
+		testRelease("v5.0.0", day(time.January, 10)),
+		testRelease("v5.0.0-rc.1", day(time.January, 2)),
+		testRelease("nightly", day(time.January, 1)),
+	}
+}
+
+func tagNames(releases []Release) []string {
+	tags := make([]string, len(releases))
+	for i, release := range releases {
+		tags[i] = release.TagName
+	}
+	return tags
+}
+
+func TestParseReleaseOrder(t *testing.T) {
+	for _, value := range []string{"date", "semver"} {
+		if order, err := ParseReleaseOrder(value); err != nil || string(order) != value {
+			t.Errorf("ParseReleaseOrder(%q) = %q, %v", value, order, err)
+		}
+	}
+	if _, err := ParseReleaseOrder("version"); err == nil {
+		t.Error("ParseReleaseOrder accepted an unknown order")
+	}
+}
+
+func TestReleaseOrderComparator(t *testing.T) {
+	for _, test := range []struct {
+		order ReleaseOrder
+		want  []string
+	}{
+		{OrderDate, []string{"v4.2.20", "v5.1.0", "v4.2.19", "v5.0.0", "v5.0.0-rc.1", "nightly"}},
+		// The releases without a semantic version come last
+		{OrderSemver, []string{"v5.1.0", "v5.0.0", "v5.0.0-rc.1", "v4.2.20", "v4.2.19", "nightly"}},
+	} {
+		releases := backportedReleases()
+		slices.Reverse(releases)
+		slices.SortStableFunc(releases, test.order.comparator())
+		if got := tagNames(releases); !slices.Equal(got, test.want) {
+			t.Errorf("releases ordered by %s: %v, want %v", test.order, got, test.want)
+		}
+	}
+}
+
+func TestCompareReleaseVersionsSameVersion(t *testing.T) {
+	// A retag of the same version, ordered by date
+	older := testRelease("v1.0.0", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	newer := testRelease("1.0.0", time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC))
+	newer.CreatedAt = *newer.PublishedAt
+	if compareReleaseVersions(newer, older) >= 0 || compareReleaseVersions(older, newer) <= 0 {
+		t.Error("the releases of the same version aren't ordered by date, the newest first")
+	}
+}
+
+func TestVersionInversions(t *testing.T) {
+	got := versionInversions(backportedReleases())
+	want := []versionInversion{{"v4.2.20", "v5.1.0"}, {"v4.2.19", "v5.0.0"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("versionInversions = %v, want %v", got, want)
+	}
+
+	// The packages of a monorepo have unrelated versions
+	monorepo := []Release{
+		testRelease("a@1.0.0", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)),
+		testRelease("b@2.0.0", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	if got := versionInversions(monorepo); len(got) > 0 {
+		t.Errorf("versionInversions of different packages = %v, want none", got)
+	}
+
+	// Releases published in the order of their versions
+	ordered := backportedReleases()[1:2]
+	ordered = append(ordered, backportedReleases()[3:]...)
+	if got := versionInversions(ordered); len(got) > 0 {
+		t.Errorf("versionInversions of ordered releases = %v, want none", got)
+	}
+}
+
+func TestVersionOrderWarning(t *testing.T) {
+	if warning := versionOrderWarning(nil, "v5.1.0"); warning != "" {
+		t.Errorf("warning without inversions: %q", warning)
+	}
+
+	inversions := versionInversions(backportedReleases())
+	warning := versionOrderWarning(inversions, "v5.1.0")
+	for _, want := range []string{"2 inversions", "v4.2.20 after v5.1.0", "--order semver", "--ignore-mode glob --ignore 'v4.*'"} {
+		if !strings.Contains(warning, want) {
+			t.Errorf("warning %q doesn't mention %q", warning, want)
+		}
+	}
+
+	// Without a semantic version to compare to, no release line can be isolated
+	if warning := versionOrderWarning(inversions, "nightly"); strings.Contains(warning, "--ignore") {
+		t.Errorf("warning for a to release without version suggests an ignore pattern: %q", warning)
+	}
+}
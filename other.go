@@ -0,0 +1,93 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// otherLanguage is the language of the files of no known language.
+const otherLanguage = "Other"
+
+// defaultOtherThreshold is the default of `--other-threshold`.
+const defaultOtherThreshold = "10%"
+
+// otherExtensionsShown is the number of unknown extensions listed in the notice about the Other language.
+const otherExtensionsShown = 3
+
+// otherShare returns the ratio of the lines of the release in the Other language,
+// and false if it has no lines or its analysis failed.
+func (a AnalysisResult) otherShare() (float64, bool) {
+	if a.failed != "" || a.totalLines == 0 {
+		return 0, false
+	}
+	return float64(a.linesByLanguage[otherLanguage]) / float64(a.totalLines), true
+}
+
+// topOtherExtensions returns the extensions with the most lines in the Other language, from the most to the least,
+// rendered with their lines, such as `.foo (1200 lines)`.
+func (a AnalysisResult) topOtherExtensions(count int) []string {
+	extensions := make([]string, 0, len(a.otherByExt))
+	for extension := range a.otherByExt {
+		extensions = append(extensions, extension)
+	}
+	slices.SortFunc(
+		extensions, func(x, y string) int {
+			if c := cmp.Compare(a.otherByExt[y], a.otherByExt[x]); c != 0 {
+				return c
+			}
+			return strings.Compare(x, y)
+		},
+	)
+	if len(extensions) > count {
+		extensions = extensions[:count]
+	}
+	for i, extension := range extensions {
+		name := extension
+		if name == "" {
+			name = "(no extension)"
+		}
+		extensions[i] = fmt.Sprintf("%s (%d lines)", name, a.otherByExt[extension])
+	}
+	return extensions
+}
+
+// otherLanguageWarnings returns a notice about the newest release whose lines in the Other language,
+// of no known language, are more than the threshold of its lines, or grew by more than half of the threshold
+// from the previous release, listing its top unknown extensions. The results are ordered from the newest
+// to the oldest, and the unknown extensions of each release are logged. A threshold of 0 disables it.
+func otherLanguageWarnings(results []AnalysisResult, threshold float64) []string {
+	for _, result := range results {
+		if len(result.otherByExt) > 0 {
+			log.Printf("%s: lines of no known language by extension: %v", result.releaseTag, result.otherByExt)
+		}
+	}
+	if threshold <= 0 {
+		return nil
+	}
+	for i, result := range results {
+		share, ok := result.otherShare()
+		if !ok || len(result.otherByExt) == 0 {
+			continue
+		}
+		growth := ""
+		if i+1 < len(results) {
+			if previous, ok := results[i+1].otherShare(); ok && share-previous > threshold/2 {
+				growth = fmt.Sprintf(", up from %.0f%% at %s", previous*100, results[i+1].releaseTag)
+			}
+		}
+		if share <= threshold && growth == "" {
+			continue
+		}
+		return []string{
+			fmt.Sprintf(
+				"%.0f%% of the lines of %s are of no known language (%s)%s, mostly %s: consider --lang-map or report them upstream",
+				share*100, result.releaseTag, otherLanguage, growth,
+				strings.Join(result.topOtherExtensions(otherExtensionsShown), ", "),
+			),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// packageName is the npm package name of the releases, see `--package`.
+// When empty, the package name is read from the tags, such as `@sveltejs/kit@1.0.0`.
+// It is set once the inputs are validated, before any command runs.
+var packageName string
+
+// npmPackageNameRegex matches a valid npm package name, scoped or not.
+var npmPackageNameRegex = regexp.MustCompile(`^(?:@[a-z0-9-~][a-z0-9-._~]*/)?[a-z0-9-~][a-z0-9-._~]*$`)
+
+// tagVersionRegex matches the trailing semantic version of a tag, such as `v1.2.3` or `release-1.2.3-beta.1`.
+var tagVersionRegex = regexp.MustCompile(`(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`)
+
+// ValidatePackageName checks the value of the `--package` flag, empty meaning none.
+func ValidatePackageName(name string) error {
+	if name != "" && !npmPackageNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid npm package name %q, such as svelte or @sveltejs/kit", name)
+	}
+	return nil
+}
+
+// checkPackageName returns the analysis of a release downloaded from the npm registry,
+// reported as failed if its root manifest names another package than expected, such as
+// for a wrong `--package`. The expected name is resolved for each release, from `--package`
+// or its tag, so releases tagged under the former name of a renamed package still match.
+// Releases without a named root manifest are left unchecked.
+func checkPackageName(result AnalysisResult) AnalysisResult {
+	expected, _ := npmPackageVersion(result.releaseTag)
+	if result.failed != "" || result.packageName == "" || result.packageName == expected {
+		return result
+	}
+	failed := failedAnalysis(result.releaseTag, fmt.Sprintf("expected %s, got %s", expected, result.packageName))
+	failed.packageName, failed.expectedPackage = result.packageName, expected
+	return failed
+}
+
+// tagVersion returns the version of a tag named independently of the package,
+// e.g. "v1.2.3" -> "1.2.3", or an empty string if the tag has no version.
+func tagVersion(tag string) string {
+	return tagVersionRegex.FindString(tag)
+}
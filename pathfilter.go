@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ParsePathPatterns parses the comma-separated glob patterns of `--exclude` or `--include`,
+// such as `dist/**,**/*.d.ts`. Each segment of a pattern is a path.Match pattern,
+// and a `**` segment matches any number of directories.
+func ParsePathPatterns(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			return nil, fmt.Errorf("empty glob in %q", value)
+		}
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+			}
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// matchGlob returns whether the slash-separated path matches the glob pattern of ParsePathPatterns.
+func matchGlob(pattern, filePath string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(filePath, "/"))
+}
+
+func matchSegments(patterns, segments []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			// Try the rest of the pattern from every remaining depth
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(patterns[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(patterns[0], segments[0]); !matched {
+			return false
+		}
+		patterns, segments = patterns[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// matchAnyGlob returns whether the slash-separated path matches any of the glob patterns.
+func matchAnyGlob(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludesPath returns whether the file at the slash-separated path, relative to the root of the release,
+// is left out of the analysis by `--exclude` or, if any, for not matching `--include`.
+func (s AnalysisSettings) excludesPath(filePath string) bool {
+	if matchAnyGlob(s.Exclude, filePath) {
+		return true
+	}
+	return len(s.Include) > 0 && !matchAnyGlob(s.Include, filePath)
+}
+
+// excludesDir returns whether every file of the directory at the slash-separated path,
+// relative to the root of the release, is excluded by `--exclude`, for its walk to be skipped:
+// the directory matches a pattern, or the part of a pattern before a trailing `/**`.
+// The directories without any file matching `--include` are still walked.
+func (s AnalysisSettings) excludesDir(dirPath string) bool {
+	for _, pattern := range s.Exclude {
+		if matchGlob(pattern, dirPath) {
+			return true
+		}
+		if parent, found := strings.CutSuffix(pattern, "/**"); found && matchGlob(parent, dirPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseRelativePath returns the slash-separated path of a file of a release relative to its root:
+// the path within the directory of its content, usually `package/`, for a tarball or an extracted release,
+// or the path itself for a local directory. It returns false for the directory of the content itself.
+func releaseRelativePath(filePath string, inContentDir bool) (string, bool) {
+	if !inContentDir {
+		return filePath, true
+	}
+	_, rest, found := strings.Cut(filePath, "/")
+	return rest, found
+}
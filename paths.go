@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// resolvePath returns the absolute path with its symlinks resolved.
+// The missing part of a path that doesn't exist yet, such as an output
+// directory created later, is joined to its nearest existing ancestor.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			// Nothing of the path exists, not even its root
+			return filepath.Join(append([]string{abs}, missing...)...), nil
+		}
+		missing = append([]string{filepath.Base(abs)}, missing...)
+		abs = parent
+	}
+}
+
+// isWithin returns whether the path is the directory or one of its descendants,
+// both paths being absolute and clean.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// configuredDirs returns the directories that must not overlap, by the name shown in the errors:
+// the output directory and the local directory if any.
+// The run manifests and the fetch cache are kept in the output directory, see runsDirName,
+// and planReleaseDirs keeps the extraction directories out of it.
+// The user data directory only holds small files nothing analyzes, so it may overlap them.
+func configuredDirs(output, local string) map[string]string {
+	dirs := map[string]string{"--output": output}
+	if local != "" {
+		dirs["--local"] = local
+	}
+	return dirs
+}
+
+// checkPathsDisjoint checks that none of the named directories is another one,
+// or nested inside another one, once their symlinks are resolved.
+// An output directory inside an analyzed directory would have its
+// extractions counted by the analysis, and the other way around.
+func checkPathsDisjoint(paths map[string]string) error {
+	names := make([]string, 0, len(paths))
+	resolved := make(map[string]string, len(paths))
+	for name, path := range paths {
+		names = append(names, name)
+		abs, err := resolvePath(path)
+		if err != nil {
+			return fmt.Errorf("could not resolve %s %s: %w", name, path, err)
+		}
+		resolved[name] = abs
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		path := resolved[name]
+		for _, other := range names {
+			otherPath := resolved[other]
+			if name == other || !isWithin(path, otherPath) {
+				continue
+			}
+			if path == otherPath {
+				return fmt.Errorf(
+					"%s %s and %s %s are the same directory (%s), the analysis would count its own files",
+					name, paths[name], other, paths[other], path,
+				)
+			}
+			return fmt.Errorf(
+				"%s %s is inside %s %s (%s is in %s), the analysis would count its own files; use directories outside of each other",
+				name, paths[name], other, paths[other], path, otherPath,
+			)
+		}
+	}
+	return nil
+}
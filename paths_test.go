@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIsWithin(t *testing.T) {
+	type within struct {
+		path, dir string
+		expected  bool
+	}
+	tests := []within{
+		{"/a/b", "/a/b", true},
+		{"/a/b/c", "/a/b", true},
+		{"/a/b/c/d", "/a/b", true},
+		{"/a/b", "/", true},
+		{"/a", "/a/b", false},
+		{"/a/bc", "/a/b", false},
+		{"/a/b", "/a/bc", false},
+		{"/a/b..", "/a/b", false},
+		{"/a/..b", "/a", true},
+		{"/x/b", "/a/b", false},
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests,
+			within{`C:\a\b`, `C:\a\b`, true},
+			within{`C:\a\b\c`, `C:\a\b`, true},
+			within{`C:\a\bc`, `C:\a\b`, false},
+			within{`C:\a\b`, `C:\a\bc`, false},
+			within{`C:\a\b`, `D:\a\b`, false},
+			within{`C:\a\b\c`, `C:\`, true},
+		)
+	} else {
+		// The backslash is part of a name outside of Windows
+		tests = append(tests,
+			within{`/a/b\c`, "/a/b", false},
+			within{`/a/b\c`, `/a/b\`, false},
+			within{`/a/b\/c`, `/a/b\`, true},
+		)
+	}
+	for _, test := range tests {
+		path, dir := filepath.FromSlash(test.path), filepath.FromSlash(test.dir)
+		if got := isWithin(path, dir); got != test.expected {
+			t.Errorf("isWithin(%q, %q) = %t, expected %t", path, dir, got, test.expected)
+		}
+	}
+}
+
+func TestCheckPathsDisjoint(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/b", "a/bc", "d"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(filepath.Join(root, "a"), link); err != nil {
+		t.Logf("no symlink case: %v", err)
+		link = ""
+	}
+	dir := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+	for _, test := range []struct {
+		name          string
+		output, local string
+		err           string
+	}{
+		{"no local directory", dir("a/b"), "", ""},
+		{"siblings", dir("a/b"), dir("d"), ""},
+		{"prefix but not child", dir("a/bc"), dir("a/b"), ""},
+		{"child prefix", dir("a/b"), dir("a/bc"), ""},
+		{"missing sibling", dir("a/b-releases/new"), dir("a/b"), ""},
+		{"same", dir("a/b"), dir("a/b"), "are the same directory"},
+		{"same once cleaned", dir("a/b/../b/"), dir("a/b"), "are the same directory"},
+		{"output inside", dir("a/b/releases"), dir("a"), "--output " + dir("a/b/releases") + " is inside --local"},
+		{"local inside", dir("a"), dir("a/b"), "--local " + dir("a/b") + " is inside --output"},
+		{"symlinked", filepath.Join(link, "b", "releases"), dir("a/b"), "is inside --local"},
+	} {
+		if test.name == "symlinked" && link == "" {
+			continue
+		}
+		err := checkPathsDisjoint(configuredDirs(test.output, test.local))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		case test.err != "" && err == nil:
+			t.Errorf("%s: expected an error containing %q", test.name, test.err)
+		case err != nil && !strings.Contains(err.Error(), test.err):
+			t.Errorf("%s: error %q, expected it to contain %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestConfiguredDirs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if dirs := configuredDirs("releases", ""); len(dirs) != 1 || dirs["--output"] != "releases" {
+		t.Errorf("configuredDirs without --local = %v, expected only --output", dirs)
+	}
+	dirs := configuredDirs("releases", "src")
+	if len(dirs) != 2 || dirs["--output"] != "releases" || dirs["--local"] != "src" {
+		t.Errorf("configuredDirs with --local = %v, expected --output and --local", dirs)
+	}
+	// The data directory of the notes isn't checked, so the output may be kept in it
+	data, err := userDataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPathsDisjoint(configuredDirs(filepath.Join(data, "releases"), t.TempDir())); err != nil {
+		t.Errorf("output inside the data directory: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Placeholders of the release inputs, which suggest the tags of the repository.
+const (
+	baseReleasePlaceholder    = "Base release"
+	compareReleasePlaceholder = "Release to compare to"
+)
+
+// pickerTagsCount is the number of recent tags fetched for the suggestions, a single page of the API.
+// Older tags can still be typed.
+const pickerTagsCount = 100
+
+// pickerRows is the maximum number of suggestions shown under a release input.
+const pickerRows = 6
+
+// tagsFetchedMsg is a message that carries the recent tags of a repository, newest first.
+// Failures are only logged, the release inputs then being typed without suggestions.
+type tagsFetchedMsg struct {
+	repo string
+	tags []string
+	err  error
+}
+
+// releasePicker suggests the recent tags of the repository under the focused release input,
+// filtered by its text, to pick one with the arrow keys.
+type releasePicker struct {
+	repo     string   // Repository the tags are fetched or being fetched for
+	tags     []string // Recent tags of the repository, newest first
+	selected int      // Index of the highlighted suggestion, -1 if none
+}
+
+// FetchTags fetches the recent tags of a GitHub repository.
+func FetchTags(ctx context.Context, ownerRepo, token string) tea.Cmd {
+	return func() tea.Msg {
+		tags, err := fetchTags(ctx, ownerRepo, token)
+		return tagsFetchedMsg{repo: ownerRepo, tags: tags, err: err}
+	}
+}
+
+func fetchTags(ctx context.Context, ownerRepo, token string) ([]string, error) {
+	request, err := newRequest(
+		ctx, http.MethodGet,
+		fmt.Sprintf(
+			"https://api.github.com/repos/%s/tags?per_page=%d", strings.TrimSuffix(ownerRepo, ".git"), pickerTagsCount,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/vnd.github+json")
+	if token != "" {
+		request.Header.Add("Authorization", fmt.Sprintf("token %s", token))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+	githubQuota.record(response)
+	if err = rateLimitError(response); err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch the tags of %s: %s", ownerRepo, response.Status)
+	}
+
+	var tags []struct {
+		Name string `json:"name"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&tags); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names, nil
+}
+
+// typedRepository returns the repository and the token given or typed so far,
+// or empty strings if the repository isn't valid yet.
+func (m model) typedRepository() (repo, token string) {
+	repo, token = m.data.ghRepo, m.data.ghToken
+	if typed, ok := m.inputValue(fieldRepository); ok {
+		repo = strings.TrimSpace(typed)
+	}
+	if typed, ok := m.inputValue(fieldToken); ok && token == "" {
+		token = typed
+	}
+	if strings.Count(repo, "/") != 1 {
+		return "", ""
+	}
+	return repo, token
+}
+
+// focusedReleaseInput returns the focused input if it is a release input.
+func (m model) focusedReleaseInput() (*textinput.Model, bool) {
+	if m.focusIndex >= len(m.inputs) || !m.inputs[m.focusIndex].isRelease() {
+		return nil, false
+	}
+	return &m.inputs[m.focusIndex].Model, true
+}
+
+// suggestTags fetches the tags of the repository once a release input is focused,
+// unless they were already fetched for it.
+func (m model) suggestTags() (model, tea.Cmd) {
+	if _, ok := m.focusedReleaseInput(); !ok {
+		return m, nil
+	}
+	repo, token := m.typedRepository()
+	if repo == "" || repo == m.picker.repo {
+		return m, nil
+	}
+	m.picker = releasePicker{repo: repo, selected: -1}
+	return m, withRun(m.run, FetchTags(m.ctx, repo, token))
+}
+
+// handleTags records the fetched tags, unless the repository changed since.
+func (m model) handleTags(msg tagsFetchedMsg) model {
+	if msg.repo != m.picker.repo {
+		return m
+	}
+	if msg.err != nil {
+		log.Printf("could not suggest the tags of %s: %v", msg.repo, msg.err)
+		return m
+	}
+	m.picker.tags = msg.tags
+	return m
+}
+
+// suggestions returns the tags containing the text of the focused release input,
+// ignoring the case, except the tag typed exactly.
+func (m model) suggestions() []string {
+	input, ok := m.focusedReleaseInput()
+	if !ok {
+		return nil
+	}
+	value := strings.ToLower(strings.TrimSpace(input.Value()))
+	var matches []string
+	for _, tag := range m.picker.tags {
+		if tag != input.Value() && strings.Contains(strings.ToLower(tag), value) {
+			matches = append(matches, tag)
+		}
+	}
+	return matches
+}
+
+// moveSuggestion highlights the previous or next suggestion, returning false without any suggestion
+// or when moving up without a highlighted one, so that the arrows still move the focus.
+// Moving up from the first suggestion highlights none.
+func (m *model) moveSuggestion(delta int) bool {
+	suggestions := m.suggestions()
+	if len(suggestions) == 0 || delta < 0 && m.picker.selected < 0 {
+		return false
+	}
+	m.picker.selected += delta
+	if m.picker.selected >= len(suggestions) {
+		m.picker.selected = len(suggestions) - 1
+	} else if m.picker.selected < -1 {
+		m.picker.selected = -1
+	}
+	return true
+}
+
+// pickSuggestion fills the focused release input with the highlighted suggestion,
+// returning false if none is highlighted.
+func (m *model) pickSuggestion() bool {
+	suggestions := m.suggestions()
+	if m.picker.selected < 0 || m.picker.selected >= len(suggestions) {
+		return false
+	}
+	input, _ := m.focusedReleaseInput()
+	input.SetValue(suggestions[m.picker.selected])
+	input.CursorEnd()
+	m.picker.selected = -1
+	return true
+}
+
+// pickerView renders the suggestions under the focused release input, scrolled to the highlighted one.
+func (m model) pickerView() string {
+	suggestions := m.suggestions()
+	if len(suggestions) == 0 {
+		return ""
+	}
+	start := 0
+	if m.picker.selected >= pickerRows {
+		start = m.picker.selected - pickerRows + 1
+	}
+	end := start + pickerRows
+	if end > len(suggestions) {
+		end = len(suggestions)
+	}
+	var builder strings.Builder
+	for i := start; i < end; i++ {
+		if i == m.picker.selected {
+			builder.WriteString("\n" + svelteText.Render("  › "+suggestions[i]))
+		} else {
+			builder.WriteString("\n" + blurredStyle.Render("    "+suggestions[i]))
+		}
+	}
+	if hidden := len(suggestions) - end; hidden > 0 {
+		builder.WriteString("\n" + blurredStyle.Render(fmt.Sprintf("    … %d more", hidden)))
+	}
+	return builder.String()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// pinFirst moves the pinned releases before the others, keeping their order otherwise.
+func pinFirst(items []list.Item) []list.Item {
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(
+		sorted, func(a, b list.Item) int {
+			return pinRank(a) - pinRank(b)
+		},
+	)
+	return sorted
+}
+
+// pinRank returns 0 for a pinned release, and 1 for any other item.
+func pinRank(item list.Item) int {
+	if item, ok := item.(ListItem); ok && item.pinned {
+		return 0
+	}
+	return 1
+}
+
+// pinnedFilter returns the filter of the summary list: the default fuzzy filter,
+// the pinned releases and the endpoints being shown even when they don't match.
+// The pinned tags are read when filtering, so the map must be updated in place.
+func pinnedFilter(pinned map[string]bool, endpoints ...string) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		ranks := list.DefaultFilter(term, targets)
+		matched := make(map[int]bool, len(ranks))
+		for _, rank := range ranks {
+			matched[rank.Index] = true
+		}
+		var pinnedRanks []list.Rank
+		for i, target := range targets {
+			if (pinned[target] || slices.Contains(endpoints, target)) && !matched[i] {
+				pinnedRanks = append(pinnedRanks, list.Rank{Index: i})
+			}
+		}
+		return append(pinnedRanks, ranks...)
+	}
+}
+
+// pinnedTags returns the pinned tags, sorted.
+func pinnedTags(pinned map[string]bool) []string {
+	tags := make([]string, 0, len(pinned))
+	for tag, isPinned := range pinned {
+		if isPinned {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// PlanOptions gathers every option used to select the releases to analyze.
+type PlanOptions struct {
+	From   string                // Base release
+	To     string                // Release to compare to
+	Ignore func(tag string) bool // Whether a release is ignored by its tag, except the endpoints
+	Order  ReleaseOrder          // Order of the releases, by date if empty
+}
+
+// PlanReport describes how the releases to analyze were selected.
+type PlanReport struct {
+	Fetched        int    // Number of fetched releases
+	Duplicates     int    // Number of fetched releases dropped as duplicates of another tag
+	OutOfRange     int    // Number of releases outside the from/to range
+	IgnoredByRegex int    // Number of releases in range ignored by the ignore pattern
+	Kept           int    // Number of releases to analyze
+	ResolvedFrom   string // Tag of the oldest endpoint
+	ResolvedTo     string // Tag of the newest endpoint
+	Swapped        bool   // Whether the from release is newer than the to release
+	SameTarball    string // Package@version both endpoints resolve to, if they are the same
+	// Kept releases published after a release of a higher version, when ordered by date
+	Inversions []versionInversion
+}
+
+// String returns a one-line description of the report.
+func (r PlanReport) String() string {
+	description := fmt.Sprintf(
+		"%d fetched, %d out of range, %d ignored by pattern, %d kept",
+		r.Fetched, r.OutOfRange, r.IgnoredByRegex, r.Kept,
+	)
+	if r.Duplicates > 0 {
+		description += fmt.Sprintf(", %d duplicates dropped", r.Duplicates)
+	}
+	return description
+}
+
+// dedupeReleases returns the releases with a single record per tag, in fetch order.
+// GitHub may return a release twice when releases are created during the pagination,
+// shifting the pages; the last record fetched is kept, as it is the most recent one.
+func dedupeReleases(all []Release) ([]Release, int) {
+	last := make(map[string]int, len(all)) // Index of the last record of each tag
+	for i, release := range all {
+		last[release.TagName] = i
+	}
+	unique := make([]Release, 0, len(last))
+	for i, release := range all {
+		if last[release.TagName] == i {
+			unique = append(unique, release)
+		}
+	}
+	return unique, len(all) - len(unique)
+}
+
+// planReleases selects the releases to analyze among all the fetched releases.
+// It returns the releases between the from and to endpoints, both included,
+// ordered from the newest to the oldest, along with a report of the selection.
+// The endpoints are always kept, even if they match the ignore pattern.
+// It is a pure function: it doesn't modify all nor perform any I/O.
+func planReleases(all []Release, opts PlanOptions) ([]Release, PlanReport, error) {
+	report := PlanReport{Fetched: len(all)}
+	if opts.From == opts.To {
+		return nil, report, fmt.Errorf("the base release and the release to compare to are both %s", opts.From)
+	}
+
+	// Sort releases from the newest to the oldest, once per tag as the
+	// downloads and the analysis results are keyed by tag
+	sorted, duplicates := dedupeReleases(all)
+	report.Duplicates = duplicates
+	slices.SortStableFunc(sorted, opts.Order.comparator())
+
+	// Resolve the endpoints
+	fromIndex := slices.IndexFunc(
+		sorted, func(release Release) bool {
+			return release.TagName == opts.From
+		},
+	)
+	if fromIndex == -1 {
+		return nil, report, fmt.Errorf("release %s not found", opts.From)
+	}
+	toIndex := slices.IndexFunc(
+		sorted, func(release Release) bool {
+			return release.TagName == opts.To
+		},
+	)
+	if toIndex == -1 {
+		return nil, report, fmt.Errorf("release %s not found", opts.To)
+	}
+	newest, oldest := toIndex, fromIndex
+	if newest > oldest {
+		newest, oldest = oldest, newest
+		report.Swapped = true
+	}
+	report.ResolvedFrom, report.ResolvedTo = sorted[oldest].TagName, sorted[newest].TagName
+	report.OutOfRange = len(sorted) - (oldest - newest + 1)
+
+	// Detect endpoints resolving to the same npm tarball, e.g. a retag
+	fromName, fromVersion := npmPackageVersion(opts.From)
+	toName, toVersion := npmPackageVersion(opts.To)
+	if fromVersion != "" && fromName == toName && fromVersion == toVersion {
+		report.SameTarball = fromName + "@" + fromVersion
+	} else if url := npmTarballURL(opts.From); url == npmTarballURL(opts.To) {
+		report.SameTarball = url
+	}
+
+	// Filter the releases in range
+	plan := make([]Release, 0, oldest-newest+1)
+	for i := newest; i <= oldest; i++ {
+		release := sorted[i]
+		isEndpoint := i == newest || i == oldest
+		if !isEndpoint && opts.Ignore != nil && opts.Ignore(release.TagName) {
+			report.IgnoredByRegex++
+			continue
+		}
+		plan = append(plan, release)
+	}
+	report.Kept = len(plan)
+	if opts.Order != OrderSemver {
+		report.Inversions = versionInversions(plan)
+	}
+
+	return plan, report, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// plannerReleases are fetched releases, published a day apart in the order of the tags,
+// the newest first as GitHub returns them.
+func plannerReleases(tags ...string) []Release {
+	releases := make([]Release, len(tags))
+	for i, tag := range tags {
+		releases[i] = testRelease(tag, time.Date(2024, time.January, len(tags)-i, 0, 0, 0, 0, time.UTC))
+	}
+	return releases
+}
+
+func TestPlanReleases(t *testing.T) {
+	ignoreBeta, err := compileIgnore(IgnoreModeSubstring, "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withDuplicate := plannerReleases("v1.3.0", "v1.2.0", "v1.1.0", "v1.0.0")
+	// A release created during the pagination shifts the pages, returning v1.1.0 again
+	duplicate := withDuplicate[2]
+	duplicate.Name = new(string)
+	*duplicate.Name = "refetched"
+	withDuplicate = append(withDuplicate, duplicate)
+
+	for _, test := range []struct {
+		name    string
+		all     []Release
+		opts    PlanOptions
+		want    []string // Tags of the plan
+		report  PlanReport
+		wantErr string
+	}{
+		{
+			name:   "range in the middle",
+			all:    plannerReleases("v1.4.0", "v1.3.0", "v1.2.0", "v1.1.0", "v1.0.0"),
+			opts:   PlanOptions{From: "v1.1.0", To: "v1.3.0"},
+			want:   []string{"v1.3.0", "v1.2.0", "v1.1.0"},
+			report: PlanReport{Fetched: 5, OutOfRange: 2, Kept: 3, ResolvedFrom: "v1.1.0", ResolvedTo: "v1.3.0"},
+		},
+		{
+			name: "swapped endpoints",
+			all:  plannerReleases("v1.2.0", "v1.1.0", "v1.0.0"),
+			opts: PlanOptions{From: "v1.2.0", To: "v1.0.0"},
+			want: []string{"v1.2.0", "v1.1.0", "v1.0.0"},
+			report: PlanReport{
+				Fetched: 3, Kept: 3, ResolvedFrom: "v1.0.0", ResolvedTo: "v1.2.0", Swapped: true,
+			},
+		},
+		{
+			name: "ignored releases in range",
+			all:  plannerReleases("v2.0.0", "v2.0.0-beta.2", "v2.0.0-beta.1", "v1.0.0", "v1.0.0-beta.1"),
+			opts: PlanOptions{From: "v1.0.0", To: "v2.0.0", Ignore: ignoreBeta},
+			want: []string{"v2.0.0", "v1.0.0"},
+			report: PlanReport{
+				Fetched: 5, OutOfRange: 1, IgnoredByRegex: 2, Kept: 2, ResolvedFrom: "v1.0.0", ResolvedTo: "v2.0.0",
+			},
+		},
+		{
+			name: "ignored endpoints kept",
+			all:  plannerReleases("v2.0.0-beta.2", "v2.0.0-beta.1", "v1.0.0"),
+			opts: PlanOptions{From: "v2.0.0-beta.1", To: "v2.0.0-beta.2", Ignore: ignoreBeta},
+			want: []string{"v2.0.0-beta.2", "v2.0.0-beta.1"},
+			report: PlanReport{
+				Fetched: 3, OutOfRange: 1, Kept: 2, ResolvedFrom: "v2.0.0-beta.1", ResolvedTo: "v2.0.0-beta.2",
+			},
+		},
+		{
+			name: "duplicates dropped",
+			all:  withDuplicate,
+			opts: PlanOptions{From: "v1.0.0", To: "v1.3.0"},
+			want: []string{"v1.3.0", "v1.2.0", "v1.1.0", "v1.0.0"},
+			report: PlanReport{
+				Fetched: 5, Duplicates: 1, Kept: 4, ResolvedFrom: "v1.0.0", ResolvedTo: "v1.3.0",
+			},
+		},
+		{
+			name: "backports by date",
+			all:  backportedReleases(),
+			opts: PlanOptions{From: "v5.0.0", To: "v4.2.20"},
+			want: []string{"v4.2.20", "v5.1.0", "v4.2.19", "v5.0.0"},
+			report: PlanReport{
+				Fetched: 6, OutOfRange: 2, Kept: 4, ResolvedFrom: "v5.0.0", ResolvedTo: "v4.2.20",
+				Inversions: []versionInversion{{"v4.2.20", "v5.1.0"}, {"v4.2.19", "v5.0.0"}},
+			},
+		},
+		{
+			name: "backports by version",
+			all:  backportedReleases(),
+			opts: PlanOptions{From: "v5.0.0", To: "v5.1.0", Order: OrderSemver},
+			want: []string{"v5.1.0", "v5.0.0"},
+			report: PlanReport{
+				Fetched: 6, OutOfRange: 4, Kept: 2, ResolvedFrom: "v5.0.0", ResolvedTo: "v5.1.0",
+			},
+		},
+		{
+			name: "ignored backports by version",
+			all:  backportedReleases(),
+			opts: PlanOptions{From: "v4.2.19", To: "v5.1.0", Order: OrderSemver, Ignore: ignoreBeta},
+			want: []string{"v5.1.0", "v5.0.0", "v5.0.0-rc.1", "v4.2.20", "v4.2.19"},
+			report: PlanReport{
+				Fetched: 6, OutOfRange: 1, Kept: 5, ResolvedFrom: "v4.2.19", ResolvedTo: "v5.1.0",
+			},
+		},
+		{
+			name:    "same endpoints",
+			all:     plannerReleases("v1.1.0", "v1.0.0"),
+			opts:    PlanOptions{From: "v1.0.0", To: "v1.0.0"},
+			report:  PlanReport{Fetched: 2},
+			wantErr: "both v1.0.0",
+		},
+		{
+			name:    "base release not found",
+			all:     plannerReleases("v1.1.0", "v1.0.0"),
+			opts:    PlanOptions{From: "v0.9.0", To: "v1.1.0"},
+			report:  PlanReport{Fetched: 2},
+			wantErr: "release v0.9.0 not found",
+		},
+		{
+			name:    "release to compare to not found",
+			all:     plannerReleases("v1.1.0", "v1.0.0"),
+			opts:    PlanOptions{From: "v1.0.0", To: "v2.0.0"},
+			report:  PlanReport{Fetched: 2},
+			wantErr: "release v2.0.0 not found",
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				all := slices.Clone(test.all)
+				plan, report, err := planReleases(all, test.opts)
+				if test.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+						t.Errorf("error %v, want %q", err, test.wantErr)
+					}
+				} else if err != nil {
+					t.Fatalf("planReleases: %v", err)
+				}
+				if got := tagNames(plan); !slices.Equal(got, test.want) {
+					t.Errorf("plan %v, want %v", got, test.want)
+				}
+				if !reflect.DeepEqual(report, test.report) {
+					t.Errorf("report %+v, want %+v", report, test.report)
+				}
+				if !reflect.DeepEqual(all, test.all) {
+					t.Error("planReleases modified the fetched releases")
+				}
+			},
+		)
+	}
+}
+
+func TestPlanReleasesKeepsLastDuplicate(t *testing.T) {
+	all := plannerReleases("v1.1.0", "v1.0.0")
+	refetched := all[0]
+	refetched.Body = new(string)
+	*refetched.Body = "edited notes"
+	all = append(all, refetched)
+	plan, _, err := planReleases(all, PlanOptions{From: "v1.0.0", To: "v1.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan[0].Body == nil || *plan[0].Body != "edited notes" {
+		t.Errorf("the plan kept the first record of v1.1.0, want the last one fetched")
+	}
+}
+
+func TestPlanReleasesSameTarball(t *testing.T) {
+	previous := packageName
+	packageName = "pkg"
+	t.Cleanup(
+		func() {
+			packageName = previous
+		},
+	)
+	// A retag of the same version
+	_, report, err := planReleases(plannerReleases("1.0.0", "v1.0.0"), PlanOptions{From: "v1.0.0", To: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SameTarball != "pkg@1.0.0" {
+		t.Errorf("SameTarball %q, want pkg@1.0.0", report.SameTarball)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// defaultConcurrency is the number of releases downloaded or analyzed at once, see `--concurrency`.
+const defaultConcurrency = 6
+
+// workerPool bounds the number of commands running at once, such as the downloads of a range
+// of hundreds of releases, to be polite to the npm registry and to spare memory and file descriptors.
+type workerPool chan struct{}
+
+// newWorkerPool returns a pool running at most size commands at once, at least one.
+func newWorkerPool(size int) workerPool {
+	if size < 1 {
+		size = 1
+	}
+	return make(workerPool, size)
+}
+
+// limit returns the command waiting for a slot of the pool before running,
+// producing the same message as the command.
+func (p workerPool) limit(cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		p <- struct{}{}
+		defer func() {
+			<-p
+		}()
+		return cmd()
+	}
+}
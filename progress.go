@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressInterval is the minimum duration between two progress reports of a download.
+const progressInterval = 100 * time.Millisecond
+
+const (
+	// maxProgressRows is the number of downloads in flight shown with their own progress bar.
+	maxProgressRows = 6
+	// maxProgressBarWidth is the width of the progress bars on wide terminals.
+	maxProgressBarWidth = 40
+	// minProgressBarWidth is the width of the progress bars on narrow terminals.
+	minProgressBarWidth = 10
+)
+
+// byteProgress is the download progress of a release.
+type byteProgress struct {
+	received int64
+	total    int64 // Size announced by the Content-Length header, -1 if unknown
+	done     bool
+}
+
+// fraction returns the completion of the download,
+// and false if the size of a download in flight is unknown.
+func (p byteProgress) fraction() (float64, bool) {
+	switch {
+	case p.done:
+		return 1, true
+	case p.total <= 0:
+		return 0, false
+	case p.received >= p.total:
+		// The Content-Length header may be wrong
+		return 1, true
+	default:
+		return float64(p.received) / float64(p.total), true
+	}
+}
+
+// downloadProgressMsg is a message that carries the download progress of a release.
+type downloadProgressMsg struct {
+	release  string
+	progress byteProgress
+}
+
+// progressUpdates carries the download progress reports to the program.
+// Reports are dropped rather than blocking the downloads when it is full,
+// as only the latest progress of each release matters.
+var progressUpdates = make(chan downloadProgressMsg, 64)
+
+// listenProgress waits for the next download progress report.
+func listenProgress() tea.Msg {
+	return <-progressUpdates
+}
+
+// reportProgress reports the download progress of a release without blocking.
+func reportProgress(release string, progress byteProgress) {
+	select {
+	case progressUpdates <- downloadProgressMsg{release, progress}:
+	default:
+	}
+}
+
+// progressReader is a reader reporting the bytes read from it, at most every progressInterval.
+type progressReader struct {
+	reader       io.Reader
+	release      string
+	progress     byteProgress
+	lastReported time.Time
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.progress.received += int64(n)
+	if err == io.EOF {
+		r.progress.done = true
+	}
+	if r.progress.done || time.Since(r.lastReported) >= progressInterval {
+		r.lastReported = time.Now()
+		reportProgress(r.release, r.progress)
+	}
+	return n, err
+}
+
+// progressSummary aggregates the download progress of several releases.
+type progressSummary struct {
+	received      int64 // Bytes received for all the releases
+	knownReceived int64 // Bytes received for the releases of known size
+	knownTotal    int64 // Total size of the releases of known size
+	unknown       int   // Number of releases of unknown size
+}
+
+// summarizeProgress aggregates the download progress of the releases.
+// Releases of unknown size only count in the received bytes, while
+// the completion is computed over the releases of known size.
+func summarizeProgress(progress map[string]byteProgress) progressSummary {
+	var summary progressSummary
+	for _, release := range progress {
+		summary.received += release.received
+		if release.total < 0 {
+			summary.unknown++
+			continue
+		}
+		summary.knownReceived += release.received
+		summary.knownTotal += release.total
+	}
+	return summary
+}
+
+// fraction returns the completion of the releases of known size,
+// and false if no release has a known size.
+func (s progressSummary) fraction() (float64, bool) {
+	if s.knownTotal <= 0 {
+		return 0, false
+	}
+	fraction := float64(s.knownReceived) / float64(s.knownTotal)
+	if fraction > 1 {
+		// The Content-Length header may be wrong
+		fraction = 1
+	}
+	return fraction, true
+}
+
+// String renders the aggregated progress, e.g. "3.2 MiB of at least 10.0 MiB (32%, +2 unknown)".
+func (s progressSummary) String() string {
+	fraction, known := s.fraction()
+	switch {
+	case !known && s.unknown == 0:
+		return formatBytes(float64(s.received)) + " received"
+	case !known:
+		return fmt.Sprintf("%s received (%d of unknown size)", formatBytes(float64(s.received)), s.unknown)
+	case s.unknown > 0:
+		// The releases of unknown size make the total a lower bound
+		return fmt.Sprintf(
+			"%s of at least %s (%.0f%%, +%d unknown)",
+			formatBytes(float64(s.received)), formatBytes(float64(s.knownTotal)), fraction*100, s.unknown,
+		)
+	default:
+		return fmt.Sprintf(
+			"%s of %s (%.0f%%)", formatBytes(float64(s.received)), formatBytes(float64(s.knownTotal)), fraction*100,
+		)
+	}
+}
+
+// newProgressBar returns the progress bar of the downloads.
+// Its percentage is rendered separately, to align the bars of the releases.
+func newProgressBar() progress.Model {
+	return progress.New(
+		progress.WithSolidFill(string(svelteColor)),
+		progress.WithoutPercentage(),
+		progress.WithWidth(maxProgressBarWidth),
+	)
+}
+
+// progressBarWidth returns the width of the progress bars for the width of the terminal,
+// leaving room for the tags and the sizes next to them.
+func progressBarWidth(termWidth int) int {
+	width := termWidth - 50
+	if termWidth == 0 || width > maxProgressBarWidth {
+		return maxProgressBarWidth
+	}
+	if width < minProgressBarWidth {
+		return minProgressBarWidth
+	}
+	return width
+}
+
+// releasesFraction returns the completion of the downloads of all the releases,
+// each release weighing the same whatever its size: cached and finished releases are complete,
+// and releases not started yet or of unknown size count as not started.
+func releasesFraction(count int, downloads map[string]byteProgress) float64 {
+	if count == 0 {
+		return 0
+	}
+	var completed float64
+	for _, download := range downloads {
+		if fraction, ok := download.fraction(); ok {
+			completed += fraction
+		}
+	}
+	return completed / float64(count)
+}
+
+// downloadProgressView renders an aggregate progress bar of the downloads,
+// followed by a progress bar for each download in flight,
+// or a spinner and a byte counter for the downloads of unknown size.
+func (m model) downloadProgressView() string {
+	var sb strings.Builder
+	fraction := releasesFraction(len(m.data.releases), m.byteProgress)
+	sb.WriteString(
+		fmt.Sprintf(
+			"     %s %3.0f%% • %s\n",
+			m.progressBar.ViewAs(fraction), fraction*100, summarizeProgress(m.byteProgress),
+		),
+	)
+
+	var inFlight []string
+	tagWidth := 0
+	for _, release := range m.data.releases {
+		if download, ok := m.byteProgress[release.TagName]; ok && !download.done {
+			inFlight = append(inFlight, release.TagName)
+			if len(inFlight) <= maxProgressRows && len(release.TagName) > tagWidth {
+				tagWidth = len(release.TagName)
+			}
+		}
+	}
+	for i, tag := range inFlight {
+		if i == maxProgressRows {
+			sb.WriteString(blurredStyle.Render(fmt.Sprintf("     +%d more downloading", len(inFlight)-i)) + "\n")
+			break
+		}
+		download := m.byteProgress[tag]
+		received := formatBytes(float64(download.received))
+		if fraction, ok := download.fraction(); ok {
+			sb.WriteString(
+				fmt.Sprintf(
+					"     %-*s %s %s / %s\n",
+					tagWidth, tag, m.progressBar.ViewAs(fraction), received, formatBytes(float64(download.total)),
+				),
+			)
+		} else {
+			sb.WriteString(fmt.Sprintf("     %-*s %s %s\n", tagWidth, tag, m.spinner.View(), received))
+		}
+	}
+	return sb.String()
+}
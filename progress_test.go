@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDownloadProgress(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		downloads map[string]byteProgress
+		summary   string
+		fraction  float64 // Of the 4 releases, each weighing the same
+	}{
+		{"none", map[string]byteProgress{}, "0 B received", 0},
+		{
+			"all unknown", map[string]byteProgress{
+				"v1.0.0": {received: 1024, total: -1},
+				"v1.1.0": {received: 2048, total: -1, done: true},
+			},
+			"3.0 KiB received (2 of unknown size)", 0.25,
+		},
+		{
+			"mixed", map[string]byteProgress{
+				"v1.0.0": {received: 1024, total: -1},
+				"v1.1.0": {received: 1024, total: 4096},
+				"v2.0.0": {received: 4096, total: 4096, done: true},
+			},
+			"6.0 KiB of at least 8.0 KiB (62%, +1 unknown)", 0.3125,
+		},
+		{
+			"all known", map[string]byteProgress{
+				"v1.0.0": {received: 2048, total: 4096},
+				"v1.1.0": {received: 4096, total: 4096, done: true},
+				"v2.0.0": {received: 0, total: 8192},
+			},
+			"6.0 KiB of 16.0 KiB (38%)", 0.375,
+		},
+		{
+			// The Content-Length header announced less than what was received
+			"wrong total", map[string]byteProgress{
+				"v1.0.0": {received: 8192, total: 4096},
+			},
+			"8.0 KiB of 4.0 KiB (100%)", 0.25,
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if summary := summarizeProgress(test.downloads).String(); summary != test.summary {
+					t.Errorf("summarized as %q, expected %q", summary, test.summary)
+				}
+				if fraction := releasesFraction(4, test.downloads); math.Abs(fraction-test.fraction) > 1e-9 {
+					t.Errorf("got a completion of %v, expected %v", fraction, test.fraction)
+				}
+
+				// The downloads of unknown size are rendered with a spinner instead of a bar
+				m := viewModel(StateDownloadExtract)
+				m.data.releases = plannerReleases("v2.0.0", "v1.1.0", "v1.0.0", "v0.9.0")
+				m.byteProgress = test.downloads
+				view := renderView(t, m)
+				if strings.Contains(view, "NaN") || strings.Contains(view, "Inf") {
+					t.Errorf("the view has an undefined number:\n%s", view)
+				}
+				for tag, download := range test.downloads {
+					if _, known := download.fraction(); !known && !strings.Contains(view, tag+" "+m.spinner.View()) {
+						t.Errorf("no spinner for %s of unknown size:\n%s", tag, view)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestReleasesFractionWithoutReleases(t *testing.T) {
+	if fraction := releasesFraction(0, map[string]byteProgress{"v1.0.0": {received: 1, total: -1}}); fraction != 0 {
+		t.Errorf("got a completion of %v without any release, expected 0", fraction)
+	}
+}
+
+// TestProgressReaderUnknownSize checks that a download without a Content-Length header
+// is reported as done once read to its end.
+func TestProgressReaderUnknownSize(t *testing.T) {
+	for len(progressUpdates) > 0 {
+		<-progressUpdates
+	}
+	reader := &progressReader{
+		reader: strings.NewReader(strings.Repeat("a", 10_000)), release: "v1.0.0", progress: byteProgress{total: -1},
+	}
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		t.Fatal(err)
+	}
+	var last downloadProgressMsg
+	for len(progressUpdates) > 0 {
+		last = <-progressUpdates
+	}
+	if last.release != "v1.0.0" || !last.progress.done || last.progress.received != 10_000 {
+		t.Errorf("last reported %+v, expected the whole download done", last)
+	}
+	if fraction, ok := last.progress.fraction(); !ok || fraction != 1 {
+		t.Errorf("got a completion of %v, %v once done, expected 1", fraction, ok)
+	}
+}
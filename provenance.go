@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// slsaProvenancePrefix is the prefix of the predicate types of the SLSA provenance attestations,
+// as opposed to the publish attestations npm signs for every version.
+const slsaProvenancePrefix = "https://slsa.dev/provenance/"
+
+// Provenance is whether a version was published to npm with a provenance attestation.
+type Provenance struct {
+	Attested bool   // Whether the version has a provenance attestation
+	Builder  string // Identity of the builder of the attestation, empty if unknown
+}
+
+// provenanceMsg is a message that carries the provenance of a release.
+// Failures of the lookup are only logged, the provenance being left unknown.
+type provenanceMsg struct {
+	release    string
+	provenance Provenance
+	err        error
+}
+
+// npmAttestationsURL returns the URL of the attestations of the version of a GitHub release.
+func npmAttestationsURL(release string) string {
+	name, version := npmPackageVersion(release)
+	return registryURL("-/npm/v1/attestations/" + escapeScope(name) + "@" + version)
+}
+
+// fetchProvenance fetches the attestations of the version of a GitHub release from the npm registry.
+// A version without any attestation is reported by the registry as not found.
+func fetchProvenance(ctx context.Context, release string) (Provenance, error) {
+	request, err := newRegistryRequest(ctx, http.MethodGet, npmAttestationsURL(release))
+	if err != nil {
+		return Provenance{}, err
+	}
+	request.Header.Add("Accept", "application/json")
+
+	response, err := registryClient.Do(request)
+	if err != nil {
+		return Provenance{}, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+	if response.StatusCode == http.StatusNotFound {
+		return Provenance{}, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return Provenance{}, registryStatusError("could not fetch the attestations of "+release, response)
+	}
+
+	var document struct {
+		Attestations []struct {
+			PredicateType string `json:"predicateType"`
+			Bundle        struct {
+				DSSEEnvelope struct {
+					Payload string `json:"payload"`
+				} `json:"dsseEnvelope"`
+			} `json:"bundle"`
+		} `json:"attestations"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&document); err != nil {
+		return Provenance{}, fmt.Errorf("invalid attestations of %s: %w", release, err)
+	}
+	for _, attestation := range document.Attestations {
+		if strings.HasPrefix(attestation.PredicateType, slsaProvenancePrefix) {
+			return Provenance{Attested: true, Builder: provenanceBuilder(attestation.Bundle.DSSEEnvelope.Payload)}, nil
+		}
+	}
+	return Provenance{}, nil
+}
+
+// provenanceBuilder returns the builder identity of the base64 in-toto statement of a provenance attestation,
+// read from the predicate of SLSA v1, or of SLSA v0.2 for the oldest attestations. It is empty if absent.
+func provenanceBuilder(payload string) string {
+	content, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return ""
+	}
+	var statement struct {
+		Predicate struct {
+			RunDetails struct {
+				Builder struct {
+					ID string `json:"id"`
+				} `json:"builder"`
+			} `json:"runDetails"`
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+		} `json:"predicate"`
+	}
+	if err = json.Unmarshal(content, &statement); err != nil {
+		return ""
+	}
+	if id := statement.Predicate.RunDetails.Builder.ID; id != "" {
+		return id
+	}
+	return statement.Predicate.Builder.ID
+}
+
+// LookupProvenance fetches the provenance of a release.
+func LookupProvenance(ctx context.Context, release string) tea.Cmd {
+	return func() tea.Msg {
+		provenance, err := fetchProvenance(ctx, release)
+		return provenanceMsg{release: release, provenance: provenance, err: err}
+	}
+}
+
+// applyProvenance updates the provenance of the summary list items,
+// flagging the releases where the provenance first appears after a release without it.
+func (m *model) applyProvenance() tea.Cmd {
+	introduced := make(map[string]bool)
+	chronological := m.data.chronological()
+	for i := 1; i < len(chronological); i++ {
+		previous, previousKnown := m.data.provenance[chronological[i-1].releaseTag]
+		current, known := m.data.provenance[chronological[i].releaseTag]
+		if known && previousKnown && current.Attested && !previous.Attested {
+			introduced[chronological[i].releaseTag] = true
+		}
+	}
+	for i, item := range m.items {
+		if item, ok := item.(ListItem); ok {
+			if provenance, known := m.data.provenance[item.releaseTag]; known {
+				item.provenance = &provenance
+			}
+			item.provenanceIntroduced = introduced[item.releaseTag]
+			m.items[i] = item
+		}
+	}
+	return m.list.SetItems(m.sortedItems())
+}
+
+// handleProvenance records the provenance of a release, a failed lookup leaving it unknown.
+func (m model) handleProvenance(msg provenanceMsg) (model, tea.Cmd) {
+	if msg.err != nil {
+		log.Printf("%s: provenance unknown: %v", msg.release, msg.err)
+		return m, nil
+	}
+	if m.data.provenance == nil {
+		m.data.provenance = make(map[string]Provenance)
+	}
+	m.data.provenance[msg.release] = msg.provenance
+	return m, m.applyProvenance()
+}
+
+// provenanceText renders the provenance of the release, if it has any.
+func (l ListItem) provenanceText() string {
+	if l.provenance == nil || !l.provenance.Attested {
+		return ""
+	}
+	if l.provenance.Builder == "" {
+		return "provenance"
+	}
+	return "provenance: built by " + l.provenance.Builder
+}
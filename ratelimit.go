@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// autoBackoffLimit is the longest wait for the rate limit of the GitHub API to reset
+// that is waited without asking, such as for the secondary rate limits.
+const autoBackoffLimit = time.Minute
+
+// errRateLimited is the error of a request refused by the rate limit of the GitHub API.
+type errRateLimited struct {
+	reset time.Time // When the rate limit resets, zero if unknown
+}
+
+func (e errRateLimited) Error() string {
+	if e.reset.IsZero() {
+		return "GitHub API rate limit exceeded, provide a token to raise it"
+	}
+	return fmt.Sprintf(
+		"GitHub API rate limit exceeded until %s, provide a token to raise it", e.reset.Local().Format("15:04:05"),
+	)
+}
+
+// rateLimitError returns the error of a response refused by the rate limit of the GitHub API,
+// or nil if the response wasn't refused by it. The secondary rate limits are told apart
+// from a forbidden request by their `Retry-After` header, which takes precedence over
+// the reset of the primary rate limit.
+func rateLimitError(response *http.Response) error {
+	retryAfter := response.Header.Get("Retry-After")
+	limited := response.StatusCode == http.StatusTooManyRequests ||
+		response.StatusCode == http.StatusForbidden &&
+			(response.Header.Get("X-RateLimit-Remaining") == "0" || retryAfter != "")
+	if !limited {
+		return nil
+	}
+	var reset time.Time
+	if seconds, err := strconv.ParseInt(retryAfter, 10, 64); err == nil {
+		reset = time.Now().Add(time.Duration(seconds) * time.Second)
+	} else if date, err := http.ParseTime(retryAfter); err == nil {
+		reset = date
+	} else if seconds, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(seconds, 0)
+	}
+	return errRateLimited{reset}
+}
+
+// resetWait returns how long to wait for the rate limit to reset before resuming,
+// a minute if the reset is unknown.
+func (e errRateLimited) resetWait() time.Duration {
+	if e.reset.IsZero() {
+		return time.Minute
+	}
+	// The reset is rounded down to the second
+	if wait := time.Until(e.reset) + time.Second; wait > time.Second {
+		return wait
+	}
+	return time.Second
+}
+
+// githubQuota is the rate limit quota of the GitHub API reported by the latest response,
+// shared by the requests running concurrently.
+var githubQuota rateLimitQuota
+
+// rateLimitQuota is the remaining requests of a rate limit.
+type rateLimitQuota struct {
+	mu        sync.Mutex
+	remaining int
+	limit     int // Requests allowed per window, 0 while unknown
+}
+
+// record records the quota reported by the headers of a response, if any.
+func (q *rateLimitQuota) record(response *http.Response) {
+	remaining, err := strconv.Atoi(response.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, err := strconv.Atoi(response.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.remaining, q.limit = remaining, limit
+}
+
+// String renders the remaining requests, or an empty string before any response reported them.
+func (q *rateLimitQuota) String() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.limit == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d GitHub API requests left", q.remaining, q.limit)
+}
+
+// waitForReset resumes the releases fetching interrupted by the rate limit once it resets,
+// counting down until then.
+func (m model) waitForReset() (model, tea.Cmd) {
+	wait := errRateLimited{m.rateLimited.reset}.resetWait()
+	m.waitingReset, m.resumeAt = true, m.now().Add(wait)
+	return m, withRun(
+		m.run, tea.Tick(
+			wait, func(time.Time) tea.Msg {
+				return fetchResumeMsg{}
+			},
+		),
+	)
+}
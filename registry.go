@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultRegistry is the npm registry the releases are downloaded from, see `--registry`.
+const defaultRegistry = "https://registry.npmjs.com"
+
+// ValidateRegistry checks that the registry is an absolute HTTP(S) URL.
+func ValidateRegistry(registry string) error {
+	parsed, err := url.Parse(registry)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", registry)
+	}
+	return nil
+}
+
+// registryURL returns the URL of a path of the npm registry of `--registry`,
+// whatever the trailing slashes of the flag.
+func registryURL(path string) string {
+	return strings.TrimRight(*registry, "/") + "/" + path
+}
+
+// escapeScope returns the name of a package with the slash of its scope URL-encoded,
+// like npm requests the documents of scoped packages: @sveltejs/kit -> @sveltejs%2fkit.
+func escapeScope(name string) string {
+	if strings.HasPrefix(name, "@") {
+		return strings.Replace(name, "/", "%2f", 1)
+	}
+	return name
+}
+
+// npmPackageURL returns the URL of the npm registry document of a package.
+func npmPackageURL(name string) string {
+	return registryURL(escapeScope(name))
+}
+
+// newRegistryRequest creates a request to the npm registry, authenticated with `--registry-token` if set:
+// a "user:password" token is sent as basic credentials, and any other token as a bearer token.
+func newRegistryRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	request, err := newRequest(ctx, method, url)
+	if err != nil {
+		return nil, err
+	}
+	switch token := *registryToken; {
+	case token == "":
+	case strings.Contains(token, ":"):
+		request.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(token)))
+	default:
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request, nil
+}
+
+// registryStatusError returns the error of a response of the npm registry which isn't a success,
+// hinting at the authentication when the registry refused the request.
+func registryStatusError(what string, response *http.Response) error {
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		hint := "registry authentication may be required, see --registry-token"
+		if *registryToken != "" {
+			hint = "check that --registry-token is valid for " + *registry
+		}
+		return fmt.Errorf("%s: %s, %s", what, response.Status, hint)
+	}
+	return fmt.Errorf("%s: %s", what, response.Status)
+}
+
+// fetchPublishedVersions fetches the versions of a package published to the npm registry.
+// The abbreviated document is requested, as it is much smaller than the full one.
+func fetchPublishedVersions(ctx context.Context, name string) (map[string]bool, error) {
+	request, err := newRegistryRequest(ctx, http.MethodGet, npmPackageURL(name))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/vnd.npm.install-v1+json")
+
+	response, err := registryClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+	if response.StatusCode != http.StatusOK {
+		return nil, registryStatusError("could not fetch the registry metadata of "+name, response)
+	}
+
+	var document struct {
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid registry metadata of %s: %w", name, err)
+	}
+	versions := make(map[string]bool, len(document.Versions))
+	for version := range document.Versions {
+		versions[version] = true
+	}
+	return versions, nil
+}
+
+// checkPublished checks that the resolved endpoints of the plan were published to the npm registry,
+// as a GitHub release whose publication failed can't be downloaded.
+// The registry document of each package is fetched once, and the check is skipped
+// for the packages whose document can't be fetched, the downloads reporting the errors.
+func checkPublished(ctx context.Context, report PlanReport) error {
+	published := make(map[string]map[string]bool)
+	for _, tag := range []string{report.ResolvedTo, report.ResolvedFrom} {
+		name, version := npmPackageVersion(tag)
+		if tag == "" || version == "" {
+			continue
+		}
+		versions, fetched := published[name]
+		if !fetched {
+			versions, _ = fetchPublishedVersions(ctx, name)
+			published[name] = versions
+		}
+		if versions != nil && !versions[version] {
+			return fmt.Errorf(
+				"tag %s exists on GitHub but %s@%s was never published to npm, pick a published version",
+				tag, name, version,
+			)
+		}
+	}
+	return nil
+}
+
+// registryTag returns the tag of a version of a package in the `--registry-only` mode, <package>@<version>.
+// A tag already of that form is returned as is.
+func registryTag(name, tag string) string {
+	if tag == "" || strings.HasPrefix(tag, name+"@") {
+		return tag
+	}
+	return name + "@" + tag
+}
+
+// GetRegistryReleases lists the versions of a package published to the npm registry,
+// as releases tagged <package>@<version> created at their publication time, see registryTag,
+// then keeps those selected by planReleases like GetGitHubReleases does.
+// The full document is requested, as the abbreviated one lacks the publication times.
+func GetRegistryReleases(ctx context.Context, name, from, to, ignore string, ignoreMode IgnoreMode, order ReleaseOrder) tea.Cmd {
+	return func() tea.Msg {
+		ignored, err := compileIgnore(ignoreMode, ignore)
+		if err != nil {
+			return errMsg(err)
+		}
+		request, err := newRegistryRequest(ctx, http.MethodGet, npmPackageURL(name))
+		if err != nil {
+			return errMsg(err)
+		}
+		response, err := registryClient.Do(request)
+		if err != nil {
+			return errMsg(err)
+		}
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close()
+		}(response.Body)
+		if response.StatusCode == http.StatusNotFound {
+			return errMsg(fmt.Errorf("package %s not found in the npm registry %s", name, *registry))
+		}
+		if response.StatusCode != http.StatusOK {
+			return errMsg(registryStatusError("could not fetch the registry metadata of "+name, response))
+		}
+
+		var document struct {
+			Versions map[string]json.RawMessage `json:"versions"`
+			Time     map[string]time.Time       `json:"time"`
+		}
+		if err = json.NewDecoder(response.Body).Decode(&document); err != nil {
+			return errMsg(fmt.Errorf("invalid registry metadata of %s: %w", name, err))
+		}
+		// Sort the versions for the releases published at once to be planned in the same order
+		versions := make([]string, 0, len(document.Versions))
+		for version := range document.Versions {
+			versions = append(versions, version)
+		}
+		slices.Sort(versions)
+		releases := make([]Release, 0, len(versions))
+		for _, version := range versions {
+			published := document.Time[version]
+			releases = append(
+				releases, Release{
+					TagName:     registryTag(name, version),
+					CreatedAt:   published,
+					PublishedAt: &published,
+					Prerelease:  strings.Contains(version, "-"),
+				},
+			)
+		}
+
+		plan, report, err := planReleases(
+			releases, PlanOptions{
+				From:   registryTag(name, from),
+				To:     registryTag(name, to),
+				Ignore: ignored,
+				Order:  order,
+			},
+		)
+		if err != nil {
+			return errMsg(fmt.Errorf("%w among the %d versions of %s", err, len(releases), name))
+		}
+		return gitReleasesDownloadSuccessMsg{plan, report}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// TestGetRegistryReleases checks that the versions published to the npm registry are planned
+// like GitHub releases in the --registry-only mode, whether the endpoints are tags or bare versions.
+func TestGetRegistryReleases(t *testing.T) {
+	withFakeAPI(
+		t, http.HandlerFunc(
+			func(writer http.ResponseWriter, request *http.Request) {
+				if request.URL.Path != "/@scope%2fpkg" && request.URL.Path != "/@scope/pkg" {
+					http.NotFound(writer, request)
+					return
+				}
+				writer.Header().Set("Content-Type", "application/json")
+				_, _ = writer.Write(
+					[]byte(`{
+						"versions": {"1.0.0": {}, "1.1.0": {}, "2.0.0-next.1": {}, "2.0.0": {}},
+						"time": {
+							"created": "2024-01-01T00:00:00Z",
+							"1.0.0": "2024-01-01T00:00:00Z",
+							"1.1.0": "2024-02-01T00:00:00Z",
+							"2.0.0-next.1": "2024-03-01T00:00:00Z",
+							"2.0.0": "2024-04-01T00:00:00Z",
+							"3.0.0": "2024-05-01T00:00:00Z"
+						}
+					}`),
+				)
+			},
+		),
+	)
+	for _, test := range []struct {
+		from, to string
+		ignore   string
+		expected []string
+	}{
+		{"1.0.0", "2.0.0", "", []string{"@scope/pkg@2.0.0", "@scope/pkg@2.0.0-next.1", "@scope/pkg@1.1.0", "@scope/pkg@1.0.0"}},
+		{"@scope/pkg@1.1.0", "2.0.0", "next", []string{"@scope/pkg@2.0.0", "@scope/pkg@1.1.0"}},
+	} {
+		msg := GetRegistryReleases(context.Background(), "@scope/pkg", test.from, test.to, test.ignore, IgnoreModeSubstring, OrderDate)()
+		success, ok := msg.(gitReleasesDownloadSuccessMsg)
+		if !ok {
+			t.Fatalf("%s...%s: unexpected message %#v", test.from, test.to, msg)
+		}
+		if tags := tagNames(success.releases); !reflect.DeepEqual(tags, test.expected) {
+			t.Errorf("%s...%s: planned %v, expected %v", test.from, test.to, tags, test.expected)
+		}
+	}
+
+	// Unpublished versions only have a time
+	if _, ok := GetRegistryReleases(context.Background(), "@scope/pkg", "1.0.0", "3.0.0", "", IgnoreModeRegex, OrderDate)().(errMsg); !ok {
+		t.Error("planned a version that was never published")
+	}
+	if _, ok := GetRegistryReleases(context.Background(), "other", "1.0.0", "2.0.0", "", IgnoreModeRegex, OrderDate)().(errMsg); !ok {
+		t.Error("planned the versions of a missing package")
+	}
+}
@@ -1,17 +1,24 @@
 package main
 
 import (
-	"cmp"
+	"archive/tar"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"log"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -27,55 +34,309 @@ type (
 	gitReleaseExistsMsg struct {
 		exists  bool
 		release string
+		err     error // Error of the check, the existence being unknown
+	}
+	// gitReleasesDownloadSuccessMsg is a message that carries the list of GitHub releases
+	// to analyze, along with the report of their selection.
+	gitReleasesDownloadSuccessMsg struct {
+		releases []Release
+		report   PlanReport
 	}
-	// gitReleasesDownloadSuccessMsg is a message that carries a list of GitHub releases.
-	gitReleasesDownloadSuccessMsg = []Release
 	// gitReleaseDownloadedMsg is a message that carries information about
 	// a downloaded GitHub release: the release name, the destination directory,
-	// and whether the result was cached or not.
+	// and whether the result was cached or not. In stream-counting mode,
+	// it carries the analysis result instead of a destination directory.
 	gitReleaseDownloadedMsg struct {
-		release string
-		dest    string
-		cached  bool
+		release  string
+		dest     string
+		cached   bool
+		repaired bool            // Whether an incomplete extraction of a previous run was replaced
+		skipped  bool            // Whether the release was not downloaded, the disk budget being exhausted
+		analysis *AnalysisResult // Set when the release was analyzed while streaming
+		hookErr  error           // Error of the release extracted hook, if any
+		warning  string          // Warning about the download, such as a file moved aside
+		err      error           // Error of the download, the release being reported as failed
+	}
+	// tokenExpiredMsg is a message that carries the progress of the releases
+	// fetching, interrupted because the GitHub token expired.
+	tokenExpiredMsg struct {
+		progress fetchProgress
 	}
+	// rateLimitedMsg is a message that carries the progress of the releases
+	// fetching, interrupted because the rate limit of the GitHub API was exceeded.
+	rateLimitedMsg struct {
+		progress fetchProgress
+		reset    time.Time // When the rate limit resets, zero if unknown
+	}
+	// fetchResumeMsg is a message sent once the rate limit reset, to resume the releases fetching.
+	fetchResumeMsg struct{}
 	// analysisDoneMsg is a message that carries information about the analysis
 	// of a release. See AnalysisResult for more information.
 	analysisDoneMsg = AnalysisResult
 )
 
+// errUnauthorized is returned when GitHub rejects the authentication.
+var errUnauthorized = errors.New("unauthorized, please check your token")
+
+// fetchProgress is the progress of the releases fetching,
+// used to resume it where it left off.
+type fetchProgress struct {
+	page      int       // Next page to fetch
+	perPage   int       // Number of releases per page, kept when resuming as it defines the pages
+	releases  []Release // Releases fetched so far
+	foundFrom bool      // Whether the base release was fetched
+	foundTo   bool      // Whether the release to compare to was fetched
+}
+
+// missingError returns the error of the releases not found once every page was fetched.
+func (p fetchProgress) missingError(ownerRepo, from, to string) error {
+	var missing []string
+	if !p.foundFrom {
+		missing = append(missing, from)
+	}
+	if !p.foundTo {
+		missing = append(missing, to)
+	}
+	return fmt.Errorf(
+		"release %s not found among the %d releases of %s (%d pages scanned)",
+		strings.Join(missing, " nor "), len(p.releases), ownerRepo, p.page,
+	)
+}
+
+// maxAnalysisWarnings is the maximum number of warnings kept
+// for a single release. Warnings past this limit are only counted.
+const maxAnalysisWarnings = 50
+
 // AnalysisResult carries information about the analysis
 // of a release: the total number of lines, the total number of files, and
 // the number of lines by language, in addition to the release tag.
+// Files that could not be read are skipped and reported as warnings.
 type AnalysisResult struct {
 	releaseTag      string
 	totalLines      uint
 	totalFiles      uint
 	linesByLanguage map[string]uint
+	linesByExt      map[string]uint   // Lines by lowercase file extension, "" for files without one
+	files           map[string]uint   // Lines by file path, relative to the release root
+	excludedLines   uint              // Lines of the files in excluded directories
+	excludedFiles   uint              // Files in excluded directories
+	generatedLines  uint              // Lines of the generated and minified files, unless included
+	generatedFiles  uint              // Generated and minified files, unless included
+	topFiles        uint              // Number of largest files analyzed, 0 if all the files were
+	coverage        float64           // Ratio of the bytes of the release covered by the analyzed files
+	esmFiles        uint              // JavaScript files using ES modules
+	cjsFiles        uint              // JavaScript files using CommonJS
+	packageName     string            // Name of the package, from its root manifest
+	expectedPackage string            // Name of the package expected instead of packageName, if the release mismatched
+	tarSize         uint64            // Size of the gzipped tarball in bytes, 0 if unknown
+	totalDirSize    int64             // Unpacked bytes of the analyzed files
+	dependencies    map[string]string // Version ranges of the dependencies, from the root manifest
+	engines         map[string]string // Supported versions of the engines, from the root manifest
+	packageManager  string            // Package manager of the package, from the root manifest
+	failed          string            // Why the analysis failed, empty if it succeeded
+	lfLines         uint              // Lines ending with a bare \n
+	crlfLines       uint              // Lines ending with \r\n
+	largeFileCount  uint              // Files larger than the large file size of the settings
+	largeFiles      []fileSize        // Largest of the large files, from the largest
+	normalizedLines uint              // Lines once whitespace normalized, with --ignore-whitespace
+	lineKinds       LineKinds         // Lines by kind: code, comment and blank
+	otherByExt      map[string]uint   // Lines of no known language by lowercase file extension, "" for files without one
+	kindsCounted    bool              // Whether the lines were counted by kind, see measuredCodeLines
+	// Whether the lines were also counted once whitespace normalized, see measuredNormalizedLines
+	whitespaceNormalized bool
+	warnings             []string
+	warningsCount        uint
+
+	packageTypes map[string]string // Type fields of the package manifests by directory, during the analysis
+	bareJSFiles  []string          // Paths of the .js files to resolve the module system of, during the analysis
+}
+
+// newAnalysisResult creates an empty analysis result for a release.
+func newAnalysisResult(releaseTag string) AnalysisResult {
+	return AnalysisResult{
+		releaseTag:      releaseTag,
+		linesByLanguage: make(map[string]uint),
+		linesByExt:      make(map[string]uint),
+		files:           make(map[string]uint),
+		otherByExt:      make(map[string]uint),
+		kindsCounted:    true,
+	}
+}
+
+// addFile counts the lines of a file and adds them to the result.
+// The path is the slash-separated path of the file within the release.
+// It is shared by the on-disk and the streaming analyzers
+// so that both produce identical numbers.
+func (a *AnalysisResult) addFile(path string, reader io.Reader, settings AnalysisSettings) error {
+	if isPackageJSON(path) {
+		var err error
+		if reader, err = a.readPackageJSON(path, reader); err != nil {
+			return err
+		}
+	}
+	var size byteCounter
+	var head contentHead
+	lines, err := countFileLines(path, io.TeeReader(io.TeeReader(reader, &size), &head), settings)
+	if err != nil {
+		return err
+	}
+	a.addLines(path, lines, int64(size), settings.language(path, head), settings)
+	return nil
+}
+
+// addLines adds the already counted lines and the size of a file to the result.
+func (a *AnalysisResult) addLines(path string, counts LineCounts, size int64, language string, settings AnalysisSettings) {
+	lines := counts.Total()
+	a.addLargeFile(path, size, settings)
+	extension := filepath.Ext(path)
+	if language == "" || !settings.IncludeTests && isInExcludedDir(path) {
+		a.excludedLines += lines
+		a.excludedFiles++
+		return
+	}
+	if !settings.IncludeGenerated && isGenerated(path, lines, size) {
+		a.generatedLines += lines
+		a.generatedFiles++
+		return
+	}
+	a.totalLines += lines
+	a.totalFiles++
+	a.totalDirSize += size
+	a.lfLines += counts.LF
+	a.crlfLines += counts.CRLF
+	a.normalizedLines += counts.Normalized
+	a.lineKinds.add(counts.Kinds)
+	a.whitespaceNormalized = settings.IgnoreWhitespace
+	a.files[path] = lines
+
+	a.addModule(path, extension)
+
+	// Count languages
+	a.linesByExt[strings.ToLower(extension)] += lines
+	a.linesByLanguage[language] += lines
+	if language == otherLanguage {
+		a.otherByExt[strings.ToLower(extension)] += lines
+	}
+}
+
+// fileSize is the size of a file of a release, used to select the largest files.
+type fileSize struct {
+	path string
+	size int64
+}
+
+// largestFiles returns the paths of the n largest files, ties being broken
+// by path, along with the ratio of the total bytes they cover.
+func largestFiles(files []fileSize, n int) (map[string]bool, float64) {
+	sorted := slices.Clone(files)
+	sortLargestFirst(sorted)
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+
+	selected := make(map[string]bool, n)
+	var totalBytes, selectedBytes int64
+	for i, file := range sorted {
+		totalBytes += file.size
+		if i < n {
+			selected[file.path] = true
+			selectedBytes += file.size
+		}
+	}
+	if totalBytes == 0 {
+		return selected, 1
+	}
+	return selected, float64(selectedBytes) / float64(totalBytes)
+}
+
+// approximate marks the result as only covering the top files of the release.
+func (a *AnalysisResult) approximate(topFiles int, coverage float64) {
+	a.topFiles = uint(topFiles)
+	a.coverage = coverage
+}
+
+// approximation returns a description of the approximation of the result,
+// or an empty string if the result is exact.
+func (a AnalysisResult) approximation() string {
+	if a.topFiles == 0 {
+		return ""
+	}
+	return fmt.Sprintf("approximate (top %d files ≈ %.0f%% of bytes)", a.topFiles, a.coverage*100)
+}
+
+// addWarning records a non-fatal analysis warning, keeping
+// at most maxAnalysisWarnings of them.
+func (a *AnalysisResult) addWarning(warning string) {
+	a.warningsCount++
+	if len(a.warnings) < maxAnalysisWarnings {
+		a.warnings = append(a.warnings, warning)
+	}
 }
 
 type ListItem struct {
-	previous *ListItem
-	next     *ListItem
+	previous   *ListItem
+	next       *ListItem
+	reactions  ReactionRollup // Reactions of the GitHub release, empty without reaction data
+	note       string         // Note of the user about the release
+	unverified []string       // Published files absent from the tagged source, with --verify-source
+	columns    []string       // Names of the description columns shown
+	pinned     bool           // Whether the release is pinned to the top of the list
+	endpoint   endpoint       // Which of the compared releases the release is, if any
+	provenance *Provenance    // Provenance of the release, nil until looked up
+	// Whether the release is the first with a provenance attestation after a release without
+	provenanceIntroduced bool
+	// Whether the density changed from the previous release by more than --density-threshold
+	packagingChange bool
+	// Whether the normalized change of lines from the previous release is below --formatting-threshold of the raw one
+	mostlyFormatting bool
+	dominantFile     *fileAttribution // File accounting for most of the change of lines from the previous release, if any
 	AnalysisResult
 }
 
-func (l ListItem) Title() string {
-	textForDiff := func(diff int) string {
-		if diff > 0 {
-			return successStyle.Render(fmt.Sprintf("+%d lines", diff))
-		} else if diff < 0 {
-			return errorStyle.Render(fmt.Sprintf("%d lines", diff))
-		} else {
-			return "No change"
-		}
+// reactionsCount returns the total number of reactions of the release.
+func (l ListItem) reactionsCount() int32 {
+	return l.reactions.TotalCount
+}
+
+// textForDiff renders a signed difference of lines, or "n/a" if either side was not measured.
+func textForDiff(diff measure[int]) string {
+	return textForUnitDiff(diff, "lines")
+}
+
+// diffText describes a signed difference in the unit as plain text,
+// or "n/a" if either side was not measured. It is shared by the summary and the exports.
+func diffText(diff measure[int], unit string) string {
+	if !diff.measured {
+		return notMeasuredText
+	} else if diff.value == 0 {
+		return "No change"
 	}
+	return fmt.Sprintf("%+d %s", diff.value, unit)
+}
+
+// textForUnitDiff renders the diffText of a signed difference in the unit, styled by its sign for the summary.
+func textForUnitDiff(diff measure[int], unit string) string {
+	text := diffText(diff, unit)
+	if !diff.measured {
+		return blurredStyle.Render(text)
+	} else if diff.value > 0 {
+		return successStyle.Render(text)
+	} else if diff.value < 0 {
+		return errorStyle.Render(text)
+	}
+	return text
+}
+
+func (l ListItem) Title() string {
 	var sb strings.Builder
 
+	if l.failed != "" {
+		return l.endpoint.marker() + errorStyle.Render(l.releaseTag+"  ✗ failed")
+	}
 	if l.previous != nil {
 		// All releases except the last one of the list
 		sb.WriteString("  ")
-		diffWithPrevious := int(l.totalLines) - int(l.previous.totalLines)
-		sb.WriteString(textForDiff(diffWithPrevious))
+		sb.WriteString(textForUnitDiff(lineChange(l.previous.AnalysisResult, l.AnalysisResult)))
 
 		if l.next == nil {
 			// First release of the list
@@ -84,50 +345,69 @@ func (l ListItem) Title() string {
 			for first.previous != nil {
 				first = first.previous
 			}
-			diffWithFirst := int(l.totalLines) - int(first.totalLines)
-			sb.WriteString(textForDiff(diffWithFirst))
+			sb.WriteString(textForUnitDiff(lineChange(first.AnalysisResult, l.AnalysisResult)))
 		}
 	}
-	return l.releaseTag + sb.String()
-}
-
-func (l ListItem) Description() string {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%d files • %d lines • ", l.totalFiles, l.totalLines))
-
-	// Sort and shorten map
-	type kv struct {
-		Key   string
-		Value uint
+	if l.warningsCount > 0 {
+		sb.WriteString(warningStyle.Render(fmt.Sprintf("  ⚠ %d", l.warningsCount)))
 	}
-	sorted := make([]kv, 0, len(l.linesByLanguage))
-	for k, v := range l.linesByLanguage {
-		sorted = append(sorted, kv{k, v})
+	if len(l.unverified) > 0 {
+		sb.WriteString(errorStyle.Render(fmt.Sprintf("  ✗ %d unverified", len(l.unverified))))
 	}
-	slices.SortStableFunc(
-		sorted, func(a, b kv) int {
-			return cmp.Compare(a.Value, b.Value)
-		},
-	)
-	visibleLanguages := 2
-	if len(sorted) > visibleLanguages {
-		// Shorten to visibleLanguages languages and concat all the others into the "Other" category
-		otherElem := kv{fmt.Sprintf("and %d more", len(sorted[visibleLanguages:])), 0}
-		for i := visibleLanguages; i < len(sorted); i++ {
-			otherElem.Value += l.linesByLanguage[sorted[i].Key]
+	if l.packagingChange {
+		sb.WriteString(warningStyle.Render("  packaging change suspected"))
+	}
+	if l.mostlyFormatting {
+		sb.WriteString(warningStyle.Render("  " + l.formattingText()))
+	}
+	if l.dominantFile != nil {
+		sb.WriteString(blurredStyle.Render("  " + l.dominantFileText()))
+	}
+	if l.previous != nil && l.previous.failed == "" && len(runtimeChanges(l.previous.AnalysisResult, l.AnalysisResult)) > 0 {
+		sb.WriteString(warningStyle.Render("  ⚙ engines changed"))
+	}
+	if l.largeFileCount > 0 {
+		sb.WriteString(warningStyle.Render(fmt.Sprintf("  ▣ %d large", l.largeFileCount)))
+	}
+	if l.provenanceIntroduced {
+		sb.WriteString(successStyle.Render("  ✓ provenance introduced"))
+	} else if l.provenance != nil && l.provenance.Attested {
+		sb.WriteString(successStyle.Render("  ✓ provenance"))
+	}
+	if l.previous != nil {
+		if from, to, flipped := lineEndingFlip(l.previous.AnalysisResult, l.AnalysisResult); flipped {
+			sb.WriteString(warningStyle.Render(fmt.Sprintf("  ⏎ %s→%s", from, to)))
 		}
-		sorted = append(sorted[:visibleLanguages], otherElem)
 	}
+	tag := l.releaseTag
+	if l.topFiles > 0 {
+		tag = "≈ " + tag
+	}
+	if l.pinned {
+		tag = svelteText.Render("◆ ") + tag
+	}
+	if l.note != "" {
+		tag += svelteText.Render(" ✎")
+	}
+	return l.endpoint.marker() + tag + sb.String()
+}
 
-	// Print languages
-	for i, lang := range sorted {
-		if i > 0 {
-			sb.WriteString(" / ")
+// Description renders the chosen description columns of the release,
+// skipping the ones with nothing to show.
+func (l ListItem) Description() string {
+	if l.failed != "" {
+		return errorStyle.Render(l.failed)
+	}
+	var segments []string
+	for _, column := range descriptionColumns {
+		if !slices.Contains(l.columns, column.name) {
+			continue
+		}
+		if segment := column.render(l); segment != "" {
+			segments = append(segments, segment)
 		}
-		sb.WriteString(fmt.Sprintf("%s (%d lines)", lang.Key, lang.Value))
 	}
-
-	return sb.String()
+	return strings.Join(segments, " • ")
 }
 
 func (l ListItem) FilterValue() string {
@@ -136,36 +416,56 @@ func (l ListItem) FilterValue() string {
 
 var _ list.DefaultItem = (*ListItem)(nil)
 
-// extToLang is a map that maps file extensions to programming languages.
-// It is used to count the number of lines by language.
-// It is not exhaustive and can be extended as needed.
+// excludedDirs is the set of well-known directory names whose content
+// is excluded from the analysis by default, as it is not what consumers
+// of the package experience.
+var excludedDirs = map[string]bool{
+	"test":      true,
+	"tests":     true,
+	"__tests__": true,
+	"examples":  true,
+	"docs":      true,
+}
+
+// isInExcludedDir returns whether the slash-separated path is within
+// one of the excludedDirs. Only whole path segments are matched.
+func isInExcludedDir(path string) bool {
+	segments := strings.Split(path, "/")
+	for _, segment := range segments[:len(segments)-1] {
+		if excludedDirs[segment] {
+			return true
+		}
+	}
+	return false
+}
+
+// extToLang maps the file extensions Linguist doesn't know, such as the ones of build artifacts,
+// to languages. The other files are classified by detectLanguage.
 // Note that keys should be lowercase, don't contain two-dot extensions,
 // and start by a leading dot, in order to directly be used with filepath.Ext.
 var extToLang = map[string]string{
-	".js":   "JavaScript",
-	".cjs":  "JavaScript",
-	".mjs":  "JavaScript",
-	".ts":   "TypeScript",
 	".map":  "Source Map",
-	".json": "JSON",
-	".md":   "Markdown",
+	".wasm": "WebAssembly",
 }
 
 // DoesGitHubReleaseExist checks if a GitHub release exists for
 // a given repository. Can use a token for authentication.
-func DoesGitHubReleaseExist(ownerRepo, token, release string) tea.Cmd {
+// Errors are reported in the message, along with the release they are about.
+func DoesGitHubReleaseExist(ctx context.Context, ownerRepo, token, release string) tea.Cmd {
 	return func() tea.Msg {
-		req, err := http.NewRequest(
-			http.MethodGet,
+		failed := func(err error) tea.Msg {
+			return gitReleaseExistsMsg{release: release, err: err}
+		}
+		req, err := newRequest(
+			ctx, http.MethodGet,
 			fmt.Sprintf(
 				"https://api.github.com/repos/%s/releases/tags/%s",
 				strings.TrimSuffix(ownerRepo, ".git"),
 				release,
 			),
-			nil,
 		)
 		if err != nil {
-			return errMsg(err)
+			return failed(err)
 		}
 
 		req.Header.Add("Accept", "application/vnd.github+json")
@@ -175,17 +475,21 @@ func DoesGitHubReleaseExist(ownerRepo, token, release string) tea.Cmd {
 
 		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
-			return errMsg(err)
+			return failed(err)
 		}
 		defer func(Body io.ReadCloser) {
-			err := Body.Close()
-			if err != nil {
-				panic(err)
-			}
+			_ = Body.Close()
 		}(resp.Body)
 
+		githubQuota.record(resp)
+		if err = rateLimitError(resp); err != nil {
+			return failed(err)
+		}
 		if resp.StatusCode == http.StatusForbidden {
-			return errMsg(fmt.Errorf("forbidden, please check your token or provide one"))
+			return failed(fmt.Errorf("forbidden, please check your token or provide one"))
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return failed(errUnauthorized)
 		}
 
 		return gitReleaseExistsMsg{
@@ -195,27 +499,34 @@ func DoesGitHubReleaseExist(ownerRepo, token, release string) tea.Cmd {
 	}
 }
 
+// githubReleasesPerPage is the number of releases requested per page, the maximum of the API.
+const githubReleasesPerPage = 100
+
 // GetGitHubReleases fetches GitHub releases for a repository.
-// It can use a token for authentication, and it will fetch only
-// releases between the `from` and the `to` release, ignoring the
-// releases that don't match the `regex` regular expression.
-func GetGitHubReleases(ownerRepo, token, from, to, regex string) tea.Cmd {
-	page := 1
+// It can use a token for authentication, and it will fetch
+// releases until both the `from` and the `to` release are found,
+// or until the last page was fetched, failing if either of them is missing,
+// then keep those selected by planReleases, ignoring the
+// releases that match the `ignore` pattern in the given mode, in the given order.
+// It can resume from the progress of a previous call, e.g. after the token expired
+// or the rate limit was exceeded, or from the partial fetch saved by a previous run.
+func GetGitHubReleases(ctx context.Context, ownerRepo, token, from, to, ignore string, ignoreMode IgnoreMode, order ReleaseOrder, resume *fetchProgress) tea.Cmd {
+	progress := fetchProgress{page: 1, perPage: githubReleasesPerPage}
 	fetchReleases := func() ([]Release, error) {
-		request, err := http.NewRequest(
-			http.MethodGet,
+		request, err := newRequest(
+			ctx, http.MethodGet,
 			fmt.Sprintf(
 				"https://api.github.com/repos/%s/releases",
 				strings.TrimSuffix(ownerRepo, ".git"),
 			),
-			nil,
 		)
 		if err != nil {
 			return nil, err
 		}
 
 		query := request.URL.Query()
-		query.Add("page", fmt.Sprintf("%d", page))
+		query.Add("page", fmt.Sprintf("%d", progress.page))
+		query.Add("per_page", fmt.Sprintf("%d", progress.perPage))
 		request.URL.RawQuery = query.Encode()
 
 		request.Header.Add("Accept", "application/vnd.github+json")
@@ -228,15 +539,19 @@ func GetGitHubReleases(ownerRepo, token, from, to, regex string) tea.Cmd {
 			return nil, err
 		}
 		defer func(Body io.ReadCloser) {
-			err = Body.Close()
-			if err != nil {
-				panic(err)
-			}
+			_ = Body.Close()
 		}(response.Body)
 
+		githubQuota.record(response)
+		if err = rateLimitError(response); err != nil {
+			return nil, err
+		}
 		if response.StatusCode == http.StatusForbidden {
 			return nil, fmt.Errorf("forbidden, please check your token or provide one")
 		}
+		if response.StatusCode == http.StatusUnauthorized {
+			return nil, errUnauthorized
+		}
 
 		body, err := io.ReadAll(response.Body)
 		if err != nil {
@@ -245,213 +560,594 @@ func GetGitHubReleases(ownerRepo, token, from, to, regex string) tea.Cmd {
 
 		var releases []Release
 		err = json.Unmarshal(body, &releases)
-		if err != nil {
-			return releases, err
-		}
-
-		// Sort releases by reverse creation date
-		slices.SortStableFunc(
-			releases, func(a, b Release) int {
-				return cmp.Compare(a.CreatedAt.Unix(), b.CreatedAt.Unix())
-			},
-		)
-
-		return releases, nil
+		return releases, err
 	}
 
-	var compile *regexp.Regexp
-	if regex != "" {
-		var err error
-		compile, err = regexp.Compile(regex)
+	return func() tea.Msg {
+		ignored, err := compileIgnore(ignoreMode, ignore)
 		if err != nil {
-			return func() tea.Msg {
-				return errMsg(err)
-			}
+			return errMsg(err)
+		}
+		if resume != nil {
+			progress = *resume
+		} else if partial, ok := loadPartialFetch(ownerRepo, from, to); ok {
+			log.Printf("resuming the fetch of the releases at page %d", partial.page)
+			progress = partial
+		}
+		if progress.perPage == 0 {
+			progress.perPage = githubReleasesPerPage
 		}
-	}
-
-	return func() tea.Msg {
-		var releases []Release
-
-		foundFrom := false
-		foundTo := false
-
 		for {
 			fetchedReleases, err := fetchReleases()
-			if err != nil {
+			var limited errRateLimited
+			if errors.Is(err, errUnauthorized) && token != "" {
+				// The token was valid when checking the releases, so it expired since
+				return tokenExpiredMsg{progress}
+			} else if errors.As(err, &limited) {
+				return rateLimitedMsg{progress, limited.reset}
+			} else if err != nil {
 				return errMsg(err)
 			}
-
-			if releases == nil {
-				// Slightly optimize the slice allocation
-				releases = make([]Release, 0, len(fetchedReleases))
-			}
+			progress.releases = append(progress.releases, fetchedReleases...)
 
 			for _, release := range fetchedReleases {
-				if compile != nil {
-					if compile.MatchString(release.TagName) {
-						continue
-					}
-				}
-				if foundFrom && foundTo {
-					// We've found both releases, so we don't need to add any anymore
-					break
-				}
 				if release.TagName == from {
-					foundFrom = true
+					progress.foundFrom = true
 				} else if release.TagName == to {
-					foundTo = true
-				}
-				if !foundFrom && !foundTo {
-					// Don't start adding releases until we find the first one
-					continue
+					progress.foundTo = true
 				}
-				releases = append(releases, release)
 			}
 
-			if foundFrom && foundTo {
+			if progress.foundFrom && progress.foundTo {
 				// We've found both releases, so we don't need to fetch any anymore
 				break
 			}
+			if len(fetchedReleases) < progress.perPage {
+				// A partial page is the last one, there is nothing left to fetch
+				return errMsg(progress.missingError(ownerRepo, from, to))
+			}
 
-			page++
+			progress.page++
 		}
 
-		return releases
+		plan, report, err := planReleases(
+			progress.releases, PlanOptions{
+				From:   from,
+				To:     to,
+				Ignore: ignored,
+				Order:  order,
+			},
+		)
+		if err != nil {
+			return errMsg(err)
+		}
+		if report.Duplicates > 0 {
+			log.Printf("dropped %d duplicate releases returned across pages", report.Duplicates)
+		}
+		if err = checkPublished(ctx, report); err != nil {
+			return errMsg(err)
+		}
+
+		return gitReleasesDownloadSuccessMsg{plan, report}
 	}
 }
 
-// DownloadGitHubRelease downloads a GitHub release from npmjs.com
-// and extracts it to a destination directory.
-// The destination directory is determined by the `destDir` function,
-// which receives the release name as an argument.
-func DownloadGitHubRelease(release, destDir string) tea.Cmd {
+// DownloadGitHubRelease downloads a GitHub release from the npm registry
+// and extracts it to its destination directory, see planReleaseDirs,
+// within the extraction limits; an extraction exceeding them is removed.
+// A failed download is reported in the message, so that the run goes on with the other releases,
+// and its partial extraction is removed, as is the one of a download cancelled with the context.
+// Once the disk budget of the limits is exhausted, releases are skipped instead.
+// Once extracted, a metadata file documenting the extraction is written
+// in the release directory; releases having one are reused if they are
+// valid under the cache policy, see CachePolicy.
+// If set, the hook command is then run with the `{dir}` and `{tag}` of the release.
+func DownloadGitHubRelease(ctx context.Context, release, dest, hook string, policy CachePolicy, limits ExtractLimits) tea.Cmd {
 	return func() tea.Msg {
-		// Create the destination directory
-		dest := filepath.Clean(filepath.Join(destDir, release))
-		if _, err := os.Stat(dest); err == nil {
-			return gitReleaseDownloadedMsg{
-				release: release,
-				dest:    dest,
-				cached:  true,
+		downloadsInFlight.Add(1)
+		defer downloadsInFlight.Done()
+
+		runHook := func(msg gitReleaseDownloadedMsg) gitReleaseDownloadedMsg {
+			if hook != "" {
+				msg.hookErr = RunHook(
+					"on-release-extracted", hook, map[string]string{
+						"dir": msg.dest,
+						"tag": msg.release,
+					},
+				)
 			}
-		} else if err = os.MkdirAll(dest, 0750); err != nil {
-			return errMsg(err)
+			return msg
 		}
 
-		// Create the URL
-		// sveltejs/svelte svelte@5.0.0-next.90 -> https://registry.npmjs.com/svelte/-/svelte-5.0.0-next.90.tgz
-		// sveltejs/kit @sveltejs/kit@1.0.0-next.589 -> https://registry.npmjs.com/@sveltejs/kit/-/kit-1.0.0-next.589.tgz
-		name := ""
-		if split := strings.Split(release, "@"); len(split) > 0 {
-			if len(split) > 1 && strings.HasPrefix(release, "@") {
-				name = "@" + split[1]
-			} else {
-				name = strings.Split(release, "@")[0]
-			}
+		// Create the destination directory
+		dest := filepath.Clean(dest)
+		failed := func(err error) tea.Msg {
+			return gitReleaseDownloadedMsg{release: release, dest: dest, err: err}
 		}
-		pkg := release
-		if strings.Contains(release, "/") {
-			pkg = strings.SplitN(release, "/", 2)[1]
+		valid, reason := validateCache(ctx, dest, release, policy)
+		if valid {
+			log.Printf("%s: cache hit (%s)", release, reason)
+			return runHook(
+				gitReleaseDownloadedMsg{
+					release: release,
+					dest:    dest,
+					cached:  true,
+				},
+			)
 		}
-		url := fmt.Sprintf(
-			"https://registry.npmjs.com/%s/-/%s.tgz",
-			name, strings.ReplaceAll(pkg, "@", "-"),
-		)
-
-		// Fetch the release
-		request, err := http.NewRequest(http.MethodGet, url, nil)
+		// Without valid metadata, the extraction is missing or incomplete
+		log.Printf("%s: cache miss (%s)", release, reason)
+		info, statErr := os.Stat(dest)
+		repaired := statErr == nil && info.IsDir()
+		if limits.Disk.Exhausted() {
+			log.Printf("%s: skipped, the disk budget of %s is exhausted", release, limits.Disk)
+			return gitReleaseDownloadedMsg{release: release, dest: dest, skipped: true}
+		}
+		warning, err := moveFileAside(dest)
 		if err != nil {
-			return errMsg(err)
+			return failed(err)
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			return failed(err)
+		}
+		if err := os.MkdirAll(dest, 0750); err != nil {
+			return failed(err)
 		}
 
-		response, err := http.DefaultClient.Do(request)
+		// Download and un-tar the release
+		hash := sha1.New()
+		var size byteCounter
+		downloadedAt := time.Now()
+		err = fetchNpmTarball(
+			ctx, release, func(body io.Reader) error {
+				tee := io.TeeReader(body, io.MultiWriter(hash, &size))
+				if err := Extract(dest, tee, limits); err != nil {
+					return err
+				}
+				// Consume the remaining bytes for the checksum to be complete
+				_, err := io.Copy(io.Discard, tee)
+				return err
+			},
+		)
 		if err != nil {
-			return errMsg(err)
+			// Don't leave a partial extraction nor a bomb on the disk
+			_ = os.RemoveAll(dest)
+			return failed(err)
 		}
-		defer func(Body io.ReadCloser) {
-			err := Body.Close()
-			if err != nil {
-				panic(err)
-			}
-		}(response.Body)
 
-		if response.StatusCode != http.StatusOK {
-			if response.StatusCode == http.StatusNotFound {
-				return errMsg(fmt.Errorf("release not found at %s", request.URL.String()))
-			}
-			return errMsg(fmt.Errorf("could not download release: %s", response.Status))
+		// Document the extraction
+		files, err := listExtractedFiles(dest)
+		if err != nil {
+			return failed(err)
+		}
+		name, version := npmPackageVersion(release)
+		err = WriteExtractionMetadata(
+			dest, ExtractionMetadata{
+				Tag:          release,
+				Package:      name,
+				Version:      version,
+				RegistryURL:  npmTarballURL(release),
+				Shasum:       hex.EncodeToString(hash.Sum(nil)),
+				TarSize:      uint64(size),
+				DownloadedAt: downloadedAt,
+				ToolVersion:  appVersion,
+				Files:        files,
+			},
+		)
+		if err != nil {
+			return failed(err)
 		}
 
-		// Un-tar the release
-		err = Untar(dest, response.Body)
+		return runHook(
+			gitReleaseDownloadedMsg{
+				release:  release,
+				dest:     dest,
+				repaired: repaired,
+				warning:  warning,
+			},
+		)
+	}
+}
+
+// downloadsInFlight counts the downloads running, so that quitting waits for the
+// cancelled ones to remove their partial extraction.
+var downloadsInFlight sync.WaitGroup
+
+// downloadsCleanupTimeout is how long quitting waits for the cancelled downloads.
+const downloadsCleanupTimeout = 5 * time.Second
+
+// waitDownloads waits for the downloads in flight to return, at most for the timeout.
+func waitDownloads(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		downloadsInFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// StreamGitHubRelease downloads a GitHub release from the npm registry
+// and analyzes it directly from the tarball stream,
+// without writing anything to disk.
+// A download and analysis taking longer than the timeout, unless 0, is reported as a failed analysis.
+func StreamGitHubRelease(ctx context.Context, release string, settings AnalysisSettings, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := analysisContext(ctx, timeout)
+		defer cancel()
+		var result AnalysisResult
+		var size byteCounter
+		processed := 0
+		err := fetchNpmTarball(
+			ctx, release, func(body io.Reader) error {
+				tee := io.TeeReader(body, &size)
+				var err error
+				if result, processed, err = analyzeTarball(ctx, tee, release, settings); err != nil {
+					return err
+				}
+				// Consume the remaining bytes for the size to be complete
+				_, err = io.Copy(io.Discard, tee)
+				return err
+			},
+		)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			// The partial counts of a timed out analysis are discarded, not reported as complete
+			failed := timedOutAnalysis(release, timeout, fmt.Sprintf("%d files processed", processed))
+			return gitReleaseDownloadedMsg{release: release, analysis: &failed}
+		}
+		result.tarSize = uint64(size)
 		if err != nil {
-			return errMsg(err)
+			return gitReleaseDownloadedMsg{release: release, err: err}
 		}
 
+		result = checkPackageName(result)
 		return gitReleaseDownloadedMsg{
-			release: release,
-			dest:    dest,
+			release:  release,
+			analysis: &result,
+		}
+	}
+}
+
+// npmPackageVersion returns the npm package name and version of a GitHub release.
+// sveltejs/svelte svelte@5.0.0-next.90 -> svelte, 5.0.0-next.90
+// sveltejs/kit @sveltejs/kit@1.0.0-next.589 -> @sveltejs/kit, 1.0.0-next.589
+// With --package @sveltejs/kit: v1.0.0 -> @sveltejs/kit, 1.0.0
+func npmPackageVersion(release string) (name, version string) {
+	if packageName != "" {
+		return packageName, tagVersion(release)
+	}
+	if split := strings.Split(release, "@"); len(split) > 0 {
+		if len(split) > 1 && strings.HasPrefix(release, "@") {
+			name = "@" + split[1]
+		} else {
+			name = strings.Split(release, "@")[0]
 		}
 	}
+	if index := strings.LastIndex(release, "@"); index > 0 {
+		version = release[index+1:]
+	}
+	return name, version
+}
+
+// npmTarball returns the package name and the tarball file name of a GitHub release.
+func npmTarball(release string) (name, file string) {
+	// sveltejs/svelte svelte@5.0.0-next.90 -> svelte, svelte-5.0.0-next.90.tgz
+	// sveltejs/kit @sveltejs/kit@1.0.0-next.589 -> @sveltejs/kit, kit-1.0.0-next.589.tgz
+	// --package @sveltejs/kit v1.0.0 -> @sveltejs/kit, kit-1.0.0.tgz
+	name, version := npmPackageVersion(release)
+	if packageName != "" {
+		return name, path.Base(name) + "-" + version + ".tgz"
+	}
+	pkg := release
+	if strings.Contains(release, "/") {
+		pkg = strings.SplitN(release, "/", 2)[1]
+	}
+	return name, strings.ReplaceAll(pkg, "@", "-") + ".tgz"
+}
+
+// npmTarballURL returns the URL of the tarball of a GitHub release on the npm registry of `--registry`,
+// e.g. https://registry.npmjs.com/@sveltejs/kit/-/kit-1.0.0.tgz.
+func npmTarballURL(release string) string {
+	name, file := npmTarball(release)
+	return registryURL(name + "/-/" + file)
+}
+
+// fetchNpmTarball fetches the npm registry tarball of a GitHub release
+// and passes its content to the handle function, usually a gzipped tarball.
+// The bytes read from the content are reported as the download progress.
+// Some registries only serve scoped packages under their URL-encoded name,
+// so a scoped tarball not found is requested again under it.
+func fetchNpmTarball(ctx context.Context, release string, handle func(body io.Reader) error) error {
+	response, err := getNpmTarball(ctx, npmTarballURL(release))
+	if err != nil {
+		return err
+	}
+	if name, file := npmTarball(release); response.StatusCode == http.StatusNotFound && escapeScope(name) != name {
+		_ = response.Body.Close()
+		if response, err = getNpmTarball(ctx, registryURL(escapeScope(name)+"/-/"+file)); err != nil {
+			return err
+		}
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	response = faults.response(response)
+	if response.StatusCode != http.StatusOK {
+		if response.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("release not found at %s", response.Request.URL.String())
+		}
+		return registryStatusError("could not download release", response)
+	}
+
+	return handle(
+		&progressReader{
+			reader:   faults.tarball(response.Body),
+			release:  release,
+			progress: byteProgress{total: response.ContentLength},
+		},
+	)
+}
+
+// getNpmTarball requests a tarball from the npm registry.
+func getNpmTarball(ctx context.Context, url string) (*http.Response, error) {
+	request, err := newRegistryRequest(ctx, http.MethodGet, url)
+	if err != nil {
+		return nil, err
+	}
+	return registryClient.Do(request)
 }
 
 // AnalyzeRelease analyzes a release by counting lines of code
-// for a given release within the location directory.
-func AnalyzeRelease(locationDir string, releaseTag string) tea.Cmd {
+// for a given release extracted to the directory.
+// The extraction metadata file is not part of the release and is skipped.
+// An analysis taking longer than the timeout, unless 0, is reported as failed.
+func AnalyzeRelease(
+	ctx context.Context, dir string, releaseTag string, settings AnalysisSettings, timeout time.Duration,
+) tea.Cmd {
+	return analyzeDirectory(ctx, dir, releaseTag, settings, true, timeout)
+}
+
+// AnalyzeDirectory analyzes the content of the root directory by counting
+// lines of code, reporting the result under the given release tag.
+// Files that cannot be read are recorded as warnings instead of
+// failing the whole analysis; only an error on the root itself fails it.
+// An analysis taking longer than the timeout, unless 0, is reported as failed.
+func AnalyzeDirectory(
+	ctx context.Context, root string, releaseTag string, settings AnalysisSettings, timeout time.Duration,
+) tea.Cmd {
+	return analyzeDirectory(ctx, root, releaseTag, settings, false, timeout)
+}
+
+// analysisContext returns the context of the analysis of a release within the pipeline context,
+// expiring after the timeout unless 0.
+func analysisContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// timedOutAnalysis returns the analysis result of a release whose analysis timed out,
+// with the progress it made.
+func timedOutAnalysis(releaseTag string, timeout time.Duration, progress string) AnalysisResult {
+	return failedAnalysis(releaseTag, fmt.Sprintf("timed out after %s, %s", timeout, progress))
+}
+
+// analyzeDirectory is AnalyzeDirectory, optionally skipping
+// the extraction metadata files at the root of the directory.
+func analyzeDirectory(
+	ctx context.Context, root string, releaseTag string, settings AnalysisSettings, skipMetadata bool,
+	timeout time.Duration,
+) tea.Cmd {
 	return func() tea.Msg {
-		totalLines := uint(0)
-		totalFiles := uint(0)
-		linesByLanguage := make(map[string]uint)
+		ctx, cancel := analysisContext(ctx, timeout)
+		defer cancel()
+		processed := 0
+		// The partial counts of a timed out analysis are discarded, not reported as complete
+		timedOut := func(progress string) tea.Msg {
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return analysisDoneMsg(failedAnalysis(releaseTag, ctx.Err().Error()))
+			}
+			return analysisDoneMsg(timedOutAnalysis(releaseTag, timeout, progress))
+		}
+
+		result := newAnalysisResult(releaseTag)
+		if skipMetadata {
+			if metadata, err := ReadExtractionMetadata(root); err == nil {
+				result.tarSize = metadata.TarSize
+			}
+		}
 
-		// Walk the directory
+		// Walk the directory, through its extended-length path on Windows
+		root := longPath(filepath.Clean(root))
+		var files []fileSize
 		err := filepath.WalkDir(
-			filepath.Clean(filepath.Join(locationDir, releaseTag)),
+			root,
 			func(path string, d fs.DirEntry, err error) error {
-				if err != nil {
-					return err
+				if ctx.Err() != nil {
+					return ctx.Err()
 				}
-				if d.IsDir() {
+				if err == nil {
+					err = faults.walk(path)
+				}
+				if err != nil {
+					if path == root {
+						return err
+					}
+					result.addWarning(fmt.Sprintf("%s: %v", path, err))
 					return nil
 				}
-
-				// Count lines of code
-				file, err := os.Open(path)
+				rel, err := filepath.Rel(root, path)
 				if err != nil {
-					return err
+					rel = path
 				}
-				defer func(file *os.File) {
-					err = file.Close()
-					if err != nil {
-						panic(err)
+				// Extracted releases hold their content in a directory, local directories don't
+				releasePath, inRelease := releaseRelativePath(filepath.ToSlash(rel), skipMetadata)
+				if d.IsDir() {
+					if inRelease && path != root && settings.excludesDir(releasePath) {
+						return fs.SkipDir
 					}
-				}(file)
-
-				lines, err := CountLines(file)
-				if err != nil {
-					return err
+					return nil
 				}
-				totalLines += lines
-				totalFiles++
-
-				// Count languages
-				extension := filepath.Ext(path)
-				if extension == "" {
+				if skipMetadata && strings.HasPrefix(rel, metadataFileName) {
+					return nil
+				}
+				if inRelease && settings.excludesPath(releasePath) {
 					return nil
 				}
-				language := "Other"
-				if lang, ok := extToLang[extension]; ok {
-					language = lang
+				file := fileSize{path: filepath.ToSlash(rel)}
+				if settings.TopFiles > 0 {
+					info, err := d.Info()
+					if err != nil {
+						result.addWarning(fmt.Sprintf("%s: %v", path, err))
+						return nil
+					}
+					file.size = info.Size()
 				}
-				linesByLanguage[language] += lines
-
+				files = append(files, file)
 				return nil
 			},
 		)
+		if ctx.Err() != nil {
+			return timedOut(fmt.Sprintf("%d files found while listing them", len(files)))
+		}
 		if err != nil {
-			return errMsg(err)
+			return analysisDoneMsg(failedAnalysis(releaseTag, err.Error()))
+		}
+
+		// Only keep the largest files in shallow mode
+		var selected map[string]bool
+		if settings.TopFiles > 0 {
+			var coverage float64
+			selected, coverage = largestFiles(files, settings.TopFiles)
+			result.approximate(settings.TopFiles, coverage)
 		}
 
-		return analysisDoneMsg{releaseTag, totalLines, totalFiles, linesByLanguage}
+		// Count lines of code
+		for _, file := range files {
+			if ctx.Err() != nil {
+				return timedOut(fmt.Sprintf("%d files processed", processed))
+			}
+			processed++
+			path := filepath.Join(root, filepath.FromSlash(file.path))
+			if selected != nil && !selected[file.path] {
+				// Package manifests still resolve the module system of the selected files
+				if isPackageJSON(file.path) {
+					if content, err := os.ReadFile(path); err == nil {
+						result.addPackageJSON(file.path, content)
+					} else {
+						result.addWarning(fmt.Sprintf("%s: %v", path, err))
+					}
+				}
+				continue
+			}
+			if err = analyzeFile(&result, path, file.path, settings); err != nil {
+				result.addWarning(fmt.Sprintf("%s: %v", path, err))
+			}
+		}
+		result.resolveModules()
+		if skipMetadata {
+			// Local directories aren't published under a known name
+			result = checkPackageName(result)
+		}
+
+		return analysisDoneMsg(result)
+	}
+}
+
+// failedAnalysis returns the analysis result of a release whose analysis failed,
+// without any count.
+func failedAnalysis(releaseTag, reason string) AnalysisResult {
+	result := newAnalysisResult(releaseTag)
+	result.failed = reason
+	return result
+}
+
+// analyzeFile counts the lines of the file at path and adds them
+// to the result under the slash-separated rel path.
+func analyzeFile(result *AnalysisResult, path, rel string, settings AnalysisSettings) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	return result.addFile(rel, file, settings)
+}
+
+// AnalyzeTarball analyzes an archive, gzipped or plain tar or zip, by counting lines of code
+// of its regular files, reporting the result under the given release tag.
+// In shallow mode, the lines of every file are counted while streaming,
+// but only the largest files are added to the result.
+func AnalyzeTarball(reader io.Reader, releaseTag string, settings AnalysisSettings) (AnalysisResult, error) {
+	result, _, err := analyzeTarball(context.Background(), reader, releaseTag, settings)
+	return result, err
+}
+
+// analyzeTarball is AnalyzeTarball, stopping once the context is done,
+// also returning the number of files processed.
+func analyzeTarball(
+	ctx context.Context, reader io.Reader, releaseTag string, settings AnalysisSettings,
+) (AnalysisResult, int, error) {
+	result := newAnalysisResult(releaseTag)
+	processed := 0
+	var files []fileSize
+	linesByFile := make(map[string]LineCounts)
+	languageByFile := make(map[string]string)
+	err := WalkArchive(
+		reader, func(header *tar.Header, content io.Reader) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if header.Typeflag != tar.TypeReg {
+				return nil
+			}
+			filePath := path.Clean(header.Name)
+			if releasePath, ok := releaseRelativePath(filePath, true); ok && settings.excludesPath(releasePath) {
+				return nil
+			}
+			processed++
+			if settings.TopFiles == 0 {
+				return result.addFile(filePath, content, settings)
+			}
+			if isPackageJSON(filePath) {
+				var err error
+				if content, err = result.readPackageJSON(filePath, content); err != nil {
+					return err
+				}
+			}
+			var head contentHead
+			lines, err := countFileLines(filePath, io.TeeReader(content, &head), settings)
+			if err != nil {
+				return err
+			}
+			files = append(files, fileSize{filePath, header.Size})
+			linesByFile[filePath] = lines
+			languageByFile[filePath] = settings.language(filePath, head)
+			return nil
+		},
+	)
+	if err != nil {
+		return result, processed, err
+	}
+	if settings.TopFiles == 0 {
+		result.resolveModules()
+		return result, processed, nil
+	}
+
+	// Only keep the largest files
+	selected, coverage := largestFiles(files, settings.TopFiles)
+	result.approximate(settings.TopFiles, coverage)
+	for _, file := range files {
+		if selected[file.path] {
+			result.addLines(file.path, linesByFile[file.path], file.size, languageByFile[file.path], settings)
+		}
 	}
+	result.resolveModules()
+	return result, processed, nil
 }
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestStreamedAnalysisMatchesExtracted checks that a release analyzed from the stream of its tarball,
+// with --no-extract, is counted the same as once extracted to the disk.
+func TestStreamedAnalysisMatchesExtracted(t *testing.T) {
+	tarball := packageTarball(t)
+	for name, settings := range map[string]AnalysisSettings{
+		"default":           {},
+		"all files":         {IncludeTests: true, IncludeGenerated: true},
+		"excluded":          {Exclude: []string{"lib/**"}},
+		"included":          {Include: []string{"**/*.js"}},
+		"top files":         {TopFiles: 3},
+		"ignore whitespace": {IgnoreWhitespace: true, LargeFileSize: 100},
+		"language map":      {LangMap: map[string]string{".md": "", ".cjs": "TypeScript"}},
+	} {
+		t.Run(
+			name, func(t *testing.T) {
+				streamed, err := AnalyzeTarball(bytes.NewReader(tarball), "pkg@1.0.0", settings)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				dir := t.TempDir()
+				if err := Untar(dir, bytes.NewReader(tarball)); err != nil {
+					t.Fatal(err)
+				}
+				msg := AnalyzeRelease(context.Background(), dir, "pkg@1.0.0", settings, 0)()
+				extracted, ok := msg.(analysisDoneMsg)
+				if !ok {
+					t.Fatalf("unexpected message %T", msg)
+				}
+
+				if streamed.totalFiles == 0 || streamed.failed != "" {
+					t.Fatalf("nothing analyzed from the stream: %+v", streamed)
+				}
+				if !reflect.DeepEqual(streamed, AnalysisResult(extracted)) {
+					t.Errorf("streamed:\n%+v\nextracted:\n%+v", streamed, AnalysisResult(extracted))
+				}
+			},
+		)
+	}
+}
+
+// TestStreamedAnalysisTimeout checks that a release whose tarball stalls while streamed
+// is reported as failed after the analysis timeout, without its partial counts.
+func TestStreamedAnalysisTimeout(t *testing.T) {
+	plain := archiveFormats(t)["plain tar"]
+	withFakeAPI(
+		t, http.HandlerFunc(
+			func(writer http.ResponseWriter, request *http.Request) {
+				// Serve the first entries, then stall
+				_, _ = writer.Write(plain[:len(plain)/2])
+				writer.(http.Flusher).Flush()
+				<-request.Context().Done()
+			},
+		),
+	)
+	msg := StreamGitHubRelease(context.Background(), "pkg@1.0.0", AnalysisSettings{}, 200*time.Millisecond)()
+	downloaded, ok := msg.(gitReleaseDownloadedMsg)
+	if !ok {
+		t.Fatalf("unexpected message %#v", msg)
+	}
+	if downloaded.err != nil || downloaded.analysis == nil {
+		t.Fatalf("got %+v, expected a failed analysis", downloaded)
+	}
+	analysis := *downloaded.analysis
+	if !strings.HasPrefix(analysis.failed, "timed out after 200ms, ") || !strings.HasSuffix(analysis.failed, " files processed") {
+		t.Errorf("failed with %q", analysis.failed)
+	}
+	if analysis.totalFiles != 0 || analysis.tarSize != 0 {
+		t.Errorf("partial counts reported: %+v", analysis)
+	}
+}
+
+// TestAnalysisCanceled checks that the analysis of a release stops with the pipeline.
+func TestAnalysisCanceled(t *testing.T) {
+	dir := t.TempDir()
+	if err := Untar(dir, bytes.NewReader(packageTarball(t))); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	msg := AnalyzeRelease(ctx, dir, "pkg@1.0.0", AnalysisSettings{}, time.Minute)()
+	analysis, ok := msg.(analysisDoneMsg)
+	if !ok {
+		t.Fatalf("unexpected message %T", msg)
+	}
+	if analysis.failed != context.Canceled.Error() || analysis.totalFiles != 0 {
+		t.Errorf("got %+v, expected a canceled analysis", AnalysisResult(analysis))
+	}
+}
+
+// fetchReleases fetches the releases of owner/repo from one to the other, resuming from the progress if any.
+func fetchReleases(from, to string, resume *fetchProgress) tea.Msg {
+	return GetGitHubReleases(context.Background(), "owner/repo", "", from, to, "", IgnoreModeRegex, OrderDate, resume)()
+}
+
+// firstPage is the progress of a fetching starting at the first page, by pages of 2 releases.
+func firstPage() *fetchProgress {
+	return &fetchProgress{page: 1, perPage: 2}
+}
+
+// TestGetGitHubReleasesDuplicates checks that a release returned again on the next page,
+// as when a release is created during the pagination, is analyzed once.
+func TestGetGitHubReleasesDuplicates(t *testing.T) {
+	releases := plannerReleases("v1.3.0", "v1.2.0", "v1.1.0", "v1.0.0")
+	api := &fakeGitHub{pages: [][]Release{releases[0:2], releases[1:3], releases[3:4]}}
+	withFakeAPI(t, api)
+	msg := fetchReleases("v1.0.0", "v1.3.0", firstPage())
+	success, ok := msg.(gitReleasesDownloadSuccessMsg)
+	if !ok {
+		t.Fatalf("unexpected message %#v", msg)
+	}
+	if tags, expected := tagNames(success.releases), []string{"v1.3.0", "v1.2.0", "v1.1.0", "v1.0.0"}; !reflect.DeepEqual(tags, expected) {
+		t.Errorf("planned %v, expected %v", tags, expected)
+	}
+	if success.report.Duplicates != 1 || success.report.Kept != 4 {
+		t.Errorf("reported %+v, expected 1 duplicate dropped and 4 releases kept", success.report)
+	}
+}
+
+// TestGetGitHubReleasesMissing checks that the fetching stops at the last page when a release
+// is never found, naming the missing releases and the number of pages scanned.
+func TestGetGitHubReleasesMissing(t *testing.T) {
+	releases := plannerReleases("v1.3.0", "v1.2.0", "v1.1.0", "v1.0.0")
+	for _, test := range []struct {
+		name     string
+		pages    [][]Release
+		from, to string
+		served   []int
+		err      string
+	}{
+		{
+			"partial last page", [][]Release{releases[0:2], releases[2:3]}, "v1.0.0", "v1.3.0", []int{1, 2},
+			"release v1.0.0 not found among the 3 releases of owner/repo (2 pages scanned)",
+		},
+		{
+			"empty last page", [][]Release{releases[0:2], releases[2:4]}, "v1.0.0", "v2.0.0", []int{1, 2, 3},
+			"release v2.0.0 not found among the 4 releases of owner/repo (3 pages scanned)",
+		},
+		{
+			"both missing", [][]Release{releases[0:2]}, "v0.1.0", "v2.0.0", []int{1, 2},
+			"release v0.1.0 nor v2.0.0 not found among the 2 releases of owner/repo (2 pages scanned)",
+		},
+		{
+			"no release", nil, "v1.0.0", "v1.3.0", []int{1},
+			"release v1.0.0 nor v1.3.0 not found among the 0 releases of owner/repo (1 pages scanned)",
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				api := &fakeGitHub{pages: test.pages}
+				withFakeAPI(t, api)
+				msg := fetchReleases(test.from, test.to, firstPage())
+				err, ok := msg.(errMsg)
+				if !ok {
+					t.Fatalf("unexpected message %#v", msg)
+				}
+				if err.Error() != test.err {
+					t.Errorf("got the error %q, expected %q", err, test.err)
+				}
+				if !reflect.DeepEqual(api.served, test.served) {
+					t.Errorf("served the pages %v, expected %v", api.served, test.served)
+				}
+			},
+		)
+	}
+}
+
+// TestGetGitHubReleasesRateLimited checks that a fetching interrupted by the rate limit
+// resumes at the page it stopped at, whether the run waited for the reset or was run again,
+// without requesting any page twice.
+func TestGetGitHubReleasesRateLimited(t *testing.T) {
+	releases := plannerReleases("v1.3.0", "v1.2.0", "v1.1.0", "v1.0.0")
+	for _, test := range []struct {
+		name   string
+		resume func(t *testing.T, limited rateLimitedMsg) *fetchProgress
+	}{
+		{
+			"waited for the reset", func(t *testing.T, limited rateLimitedMsg) *fetchProgress {
+				return &limited.progress
+			},
+		},
+		{
+			"run again", func(t *testing.T, limited rateLimitedMsg) *fetchProgress {
+				if err := SavePartialFetch("owner/repo", "v1.0.0", "v1.3.0", limited.progress); err != nil {
+					t.Fatal(err)
+				}
+				return nil
+			},
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				api := &fakeGitHub{pages: [][]Release{releases[0:2], releases[2:4]}, limitedAt: 2}
+				withFakeAPI(t, api)
+				msg := fetchReleases("v1.0.0", "v1.3.0", firstPage())
+				limited, ok := msg.(rateLimitedMsg)
+				if !ok {
+					t.Fatalf("unexpected message %#v", msg)
+				}
+				if limited.progress.page != 2 || len(limited.progress.releases) != 2 {
+					t.Fatalf("interrupted at %+v, expected page 2 after 2 releases", limited.progress)
+				}
+
+				// A run again fetches by pages of the size of the saved progress
+				msg = fetchReleases("v1.0.0", "v1.3.0", test.resume(t, limited))
+				success, ok := msg.(gitReleasesDownloadSuccessMsg)
+				if !ok {
+					t.Fatalf("unexpected message %#v", msg)
+				}
+				if tags, expected := tagNames(success.releases), tagNames(releases); !reflect.DeepEqual(tags, expected) {
+					t.Errorf("planned %v, expected %v", tags, expected)
+				}
+				if served := []int{1, 2}; !reflect.DeepEqual(api.served, served) || !reflect.DeepEqual(api.limited, []int{2}) {
+					t.Errorf("served the pages %v and limited %v, expected %v and [2]", api.served, api.limited, served)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReportFormat is the format of the report of the comparison.
+type ReportFormat string
+
+const (
+	// ReportNone means no report is generated.
+	ReportNone ReportFormat = ""
+	// ReportMarkdown renders the comparison as a Markdown table, to paste in an issue or a pull request.
+	ReportMarkdown ReportFormat = "markdown"
+)
+
+// reportLanguages is the number of languages detailed per release in the report,
+// the other ones being grouped together.
+const reportLanguages = 3
+
+// ParseReportFormat parses the value of the `--report` flag.
+func ParseReportFormat(value string) (ReportFormat, error) {
+	switch format := ReportFormat(value); format {
+	case ReportNone, ReportMarkdown:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid report format %q, expected %s", value, ReportMarkdown)
+	}
+}
+
+// markdownCell escapes the pipes of a Markdown table cell.
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
+
+// markdownText escapes the characters of a text that Markdown would render as emphasis.
+func markdownText(value string) string {
+	return strings.NewReplacer("*", `\*`, "_", `\_`).Replace(value)
+}
+
+// growthSummary describes the growth of the package from the oldest release to the newest one,
+// in code lines if both were counted by kind, see lineChange,
+// e.g. "The package grew by 1200 code lines (+12.5%) between v1.0.0 and v2.0.0."
+func growthSummary(chronological []AnalysisResult) string {
+	if len(chronological) < 2 {
+		return "A single release was analyzed."
+	}
+	base, last := chronological[0], chronological[len(chronological)-1]
+	diff, unit := lineChange(base, last)
+	if !diff.measured {
+		return fmt.Sprintf("The growth between %s and %s was not measured, an analysis failed.", base.releaseTag, last.releaseTag)
+	}
+	verb, lines := "grew", diff.value
+	if lines < 0 {
+		verb, lines = "shrank", -lines
+	}
+	if lines == 0 {
+		return fmt.Sprintf("The package has the same number of %s in %s and %s.", unit, base.releaseTag, last.releaseTag)
+	}
+	baseLines := base.totalLines
+	if unit != "lines" {
+		baseLines = base.lineKinds.Code
+	}
+	percent := ""
+	if baseLines > 0 {
+		percent = fmt.Sprintf(" (%+.1f%%)", float64(diff.value)/float64(baseLines)*100)
+	}
+	return fmt.Sprintf(
+		"The package %s by %d %s%s between %s and %s.", verb, lines, unit, percent, base.releaseTag, last.releaseTag,
+	)
+}
+
+// RenderMarkdownReport renders the comparison of the releases, ordered from the oldest to the newest,
+// as a short summary followed by a Markdown table of the releases, then the biggest jumps between them.
+func RenderMarkdownReport(d data, chronological []AnalysisResult) string {
+	var sb strings.Builder
+	title := fmt.Sprintf("%s → %s", d.firstRelease, d.secondRelease)
+	if d.ghRepo != "" {
+		title = d.ghRepo + ": " + title
+	}
+	sb.WriteString("<!-- Generated by " + appDirName + " " + appVersion + " -->\n")
+	sb.WriteString("## " + title + "\n\n")
+	sb.WriteString("_" + markdownText(d.settingsLine()) + "_\n\n")
+	sb.WriteString(growthSummary(chronological) + "\n\n")
+	sb.WriteString("| Tag | Total lines | Files | Size | Δ previous | Δ base | Languages |\n")
+	sb.WriteString("| --- | ---: | ---: | ---: | ---: | ---: | --- |\n")
+	for i, release := range chronological {
+		// The oldest release has nothing to be compared to
+		previous, base := "—", "—"
+		if i > 0 {
+			previous = diffText(lineChange(chronological[i-1], release))
+			base = diffText(lineChange(chronological[0], release))
+		}
+		size := notMeasuredText
+		if tarSize := release.measuredTarSize(); tarSize.measured {
+			size = formatBytes(float64(tarSize.value)) + " gz"
+		}
+		var languages []string
+		if release.failed == "" {
+			others := func(int) string { return "other" }
+			for _, language := range topLanguages(release.linesByLanguage, reportLanguages, others) {
+				languages = append(languages, fmt.Sprintf("%s %d", language.language, language.lines))
+			}
+		}
+		sb.WriteString(
+			fmt.Sprintf(
+				"| %s | %s | %s | %s | %s | %s | %s |\n",
+				markdownCell(release.releaseTag),
+				release.measuredLines().format("%d"),
+				release.measuredFiles().format("%d"),
+				size,
+				previous,
+				base,
+				markdownCell(strings.Join(languages, ", ")),
+			),
+		)
+	}
+	if panel := spikesPanel(d.biggestJumps(d.spikeCount)); panel != "" {
+		sb.WriteString("\n")
+		for _, row := range strings.Split(panel, "\n") {
+			sb.WriteString("- " + row + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// AnalysisSettings gathers every knob that shapes the analysis.
+// It is the single source used to describe the analysis settings
+// in the summary header and in the exports.
+type AnalysisSettings struct {
+	IgnoreRegex  string       // Pattern to ignore releases names from the analysis
+	IgnoreMode   IgnoreMode   // How the ignore pattern matches the releases names
+	Order        ReleaseOrder // How the releases are ordered, by date if empty
+	BaselineMode BaselineMode // Whether a baseline is read, written, or not used
+	BaselinePath string       // Path to the baseline file
+	LocalDir     string       // Local directory analyzed as the release to compare to
+	NoExtract    bool         // Whether the releases are analyzed from the tarball stream
+	IncludeTests bool         // Whether the test, example and documentation directories are analyzed
+	// Whether the generated and minified files are analyzed, see isGenerated
+	IncludeGenerated bool
+	TopFiles         int   // Number of largest files analyzed per release, 0 for all
+	LargeFileSize    int64 // Size above which a file is counted as large, 0 to disable
+	// Whether the lines are also counted once whitespace normalized, see whitespaceNormalizer
+	IgnoreWhitespace bool
+	LangMap          map[string]string // Language overrides of file extensions, an empty language excluding them
+	Exclude          []string          // Glob patterns of the paths left out of the analysis, see ParsePathPatterns
+	Include          []string          // Glob patterns of the only paths analyzed, if any
+}
+
+// UnmarshalJSON decodes the analysis settings of a bundle, a bundle written before
+// `--large-file-size` existed counting the large files with the default size.
+func (s *AnalysisSettings) UnmarshalJSON(content []byte) error {
+	type plain AnalysisSettings
+	settings := plain{LargeFileSize: defaultLargeFileSize}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return err
+	}
+	*s = AnalysisSettings(settings)
+	return nil
+}
+
+// analysisSettings returns the analysis settings in effect for the data.
+func (d data) analysisSettings() AnalysisSettings {
+	return AnalysisSettings{
+		IgnoreRegex:      d.ignoreRegex,
+		IgnoreMode:       d.ignoreMode,
+		Order:            d.releaseOrder,
+		BaselineMode:     d.baselineMode,
+		BaselinePath:     d.baselinePath,
+		LocalDir:         d.localDir,
+		NoExtract:        *noExtract,
+		IncludeTests:     *includeTests,
+		IncludeGenerated: *includeGenerated,
+		TopFiles:         *topFiles,
+		LargeFileSize:    d.largeFileSize,
+		IgnoreWhitespace: *ignoreWhitespace,
+		LangMap:          d.langMap,
+		Exclude:          d.excludePaths,
+		Include:          d.includePaths,
+	}
+}
+
+// settingsLine returns the one-line description of the analysis settings of the comparison,
+// shown in the summary header and included in every export: the ones recorded in the imported bundles if any,
+// else the ones in effect.
+func (d data) settingsLine() string {
+	if d.imported != nil {
+		return fmt.Sprintf("Imported from %s • %s", d.importedFrom, d.imported)
+	}
+	return d.analysisSettings().String()
+}
+
+// language returns the language of a file from its path and the head of its content,
+// or an empty string if the files of its extension are excluded by an override.
+// The overrides of `--lang-map` come first, then the detection of Linguist,
+// then extToLang, the files of no known language being counted as Other.
+func (s AnalysisSettings) language(path string, head contentHead) string {
+	extension := strings.ToLower(filepath.Ext(path))
+	if language, ok := s.LangMap[extension]; ok {
+		return language
+	}
+	if language := detectLanguage(path, head); language != "" {
+		return language
+	}
+	if language, ok := extToLang[extension]; ok {
+		return language
+	}
+	return otherLanguage
+}
+
+// NonDefault returns a description of each setting that differs
+// from its default value, in a stable order.
+func (s AnalysisSettings) NonDefault() []string {
+	var settings []string
+	if s.IgnoreRegex != "" {
+		settings = append(settings, fmt.Sprintf("ignored releases: %s", s.IgnoreRegex))
+		if s.IgnoreMode != IgnoreModeRegex && s.IgnoreMode != "" {
+			settings = append(settings, fmt.Sprintf("ignore mode: %s", s.IgnoreMode))
+		}
+	}
+	if s.Order != OrderDate && s.Order != "" {
+		settings = append(settings, fmt.Sprintf("order: %s", s.Order))
+	}
+	if s.BaselineMode != BaselineNone {
+		settings = append(settings, fmt.Sprintf("baseline: %s=%s", s.BaselineMode, s.BaselinePath))
+	}
+	if s.LocalDir != "" {
+		settings = append(settings, fmt.Sprintf("local: %s", s.LocalDir))
+	}
+	if s.NoExtract {
+		settings = append(settings, "no extraction")
+	}
+	if s.IncludeTests {
+		settings = append(settings, "tests/examples/docs included")
+	}
+	if s.IncludeGenerated {
+		settings = append(settings, "generated/minified files included")
+	}
+	if s.TopFiles > 0 {
+		settings = append(settings, fmt.Sprintf("approximate: top %d files per release", s.TopFiles))
+	}
+	if len(s.LangMap) > 0 {
+		settings = append(settings, fmt.Sprintf("languages: %s", formatLangMap(s.LangMap)))
+	}
+	if len(s.Exclude) > 0 {
+		settings = append(settings, fmt.Sprintf("excluded paths: %s", strings.Join(s.Exclude, ",")))
+	}
+	if len(s.Include) > 0 {
+		settings = append(settings, fmt.Sprintf("only paths: %s", strings.Join(s.Include, ",")))
+	}
+	if s.IgnoreWhitespace {
+		settings = append(settings, "whitespace changes counted apart")
+	}
+	if s.LargeFileSize <= 0 {
+		settings = append(settings, "large files: not counted")
+	} else if s.LargeFileSize != defaultLargeFileSize {
+		settings = append(settings, fmt.Sprintf("large files: over %s", formatBytes(float64(s.LargeFileSize))))
+	}
+	return settings
+}
+
+// String returns a one-line description of the analysis settings.
+func (s AnalysisSettings) String() string {
+	settings := s.NonDefault()
+	if len(settings) == 0 {
+		return "Analysis settings: defaults"
+	}
+	return "Analysis settings: " + strings.Join(settings, " • ")
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Shard is the part of the planned releases a run processes, see `--shard`,
+// so that the releases of a big range are downloaded and analyzed by several runs,
+// whose bundles are then merged with `--merge`.
+type Shard struct {
+	Index int // Position of the shard, from 1
+	Count int // Number of shards, 0 without sharding
+}
+
+// ParseShard parses the value of the `--shard` flag, formatted as `index/count`,
+// an empty value meaning no sharding.
+func ParseShard(value string) (Shard, error) {
+	if value == "" {
+		return Shard{}, nil
+	}
+	index, count, found := strings.Cut(value, "/")
+	shard := Shard{}
+	var indexErr, countErr error
+	shard.Index, indexErr = strconv.Atoi(index)
+	shard.Count, countErr = strconv.Atoi(count)
+	if !found || indexErr != nil || countErr != nil || shard.Count < 1 || shard.Index < 1 || shard.Index > shard.Count {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected index/count such as 1/4, the index being between 1 and the count", value)
+	}
+	return shard, nil
+}
+
+// String returns the shard formatted as `index/count`, or an empty string without sharding.
+func (s Shard) String() string {
+	if s.Count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// bounds returns the chronological range [start, end) of the releases of the shard
+// among the planned ones. The shards of a plan partition it, whatever its size:
+// the end of each shard is the start of the next one.
+func (s Shard) bounds(planned int) (start, end int) {
+	if s.Count == 0 {
+		return 0, planned
+	}
+	return (s.Index - 1) * planned / s.Count, s.Index * planned / s.Count
+}
+
+// releases returns the releases of the shard among the planned ones,
+// both ordered from the newest to the oldest.
+func (s Shard) releases(planned []Release) []Release {
+	start, end := s.bounds(len(planned))
+	// The chronological range is reversed in the newest-first order
+	return slices.Clone(planned[len(planned)-end : len(planned)-start])
+}
+
+// MergeBundles merges the bundles of the shards of a comparison into the bundle of the whole comparison.
+// The bundles must be the shards of the same plan, each exactly once, in any order.
+func MergeBundles(paths []string) (Bundle, error) {
+	if len(paths) == 0 {
+		return Bundle{}, fmt.Errorf("no bundle to merge")
+	}
+	bundles := make([]Bundle, len(paths))
+	shards := make([]Shard, len(paths))
+	for i, path := range paths {
+		bundle, err := ReadBundle(path)
+		if err != nil {
+			return Bundle{}, err
+		}
+		if shards[i], err = ParseShard(bundle.Shard); err != nil || shards[i].Count == 0 {
+			return Bundle{}, fmt.Errorf("bundle %s is not the bundle of a shard, exported from a --shard run", path)
+		}
+		first := bundles[0]
+		if i > 0 && (bundle.Repository != first.Repository || bundle.From != first.From || bundle.To != first.To ||
+			bundle.Planned != first.Planned || shards[i].Count != shards[0].Count) {
+			return Bundle{}, fmt.Errorf(
+				"bundle %s (%s %s → %s, shard %s of %d releases) is not a shard of the comparison of %s"+
+					" (%s %s → %s, shards of %d releases)",
+				path, bundle.Repository, bundle.From, bundle.To, bundle.Shard, bundle.Planned,
+				paths[0], first.Repository, first.From, first.To, first.Planned,
+			)
+		}
+		start, end := shards[i].bounds(bundle.Planned)
+		if len(bundle.Releases) != end-start {
+			return Bundle{}, fmt.Errorf(
+				"bundle %s holds %d releases instead of the %d of the shard %s", path, len(bundle.Releases), end-start, bundle.Shard,
+			)
+		}
+		bundles[i] = bundle
+	}
+
+	// The newest shard comes first, like the releases
+	byIndex := make(map[int]int, len(paths))
+	for i, shard := range shards {
+		if previous, ok := byIndex[shard.Index]; ok {
+			return Bundle{}, fmt.Errorf("bundles %s and %s are both the shard %s", paths[previous], paths[i], shard)
+		}
+		byIndex[shard.Index] = i
+	}
+	var missing []string
+	for index := 1; index <= shards[0].Count; index++ {
+		if _, ok := byIndex[index]; !ok {
+			missing = append(missing, Shard{index, shards[0].Count}.String())
+		}
+	}
+	if len(missing) > 0 {
+		return Bundle{}, fmt.Errorf("missing the bundles of the shards %s", strings.Join(missing, ", "))
+	}
+
+	merged := bundles[0]
+	merged.Shard = ""
+	merged.Releases, merged.Analysis = nil, nil
+	merged.Notes, merged.Unverified = make(map[string]string), make(map[string][]string)
+	for index := shards[0].Count; index >= 1; index-- {
+		bundle := bundles[byIndex[index]]
+		merged.Releases = append(merged.Releases, bundle.Releases...)
+		merged.Analysis = append(merged.Analysis, bundle.Analysis...)
+		for tag, note := range bundle.Notes {
+			merged.Notes[tag] = note
+		}
+		for tag, files := range bundle.Unverified {
+			merged.Unverified[tag] = files
+		}
+	}
+	if len(merged.Releases) != merged.Planned {
+		return Bundle{}, fmt.Errorf(
+			"the shards hold %d releases instead of the %d planned ones", len(merged.Releases), merged.Planned,
+		)
+	}
+	return merged, nil
+}
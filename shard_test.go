@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// shardPlan returns a synthetic plan of releases, from the newest to the oldest.
+func shardPlan(planned int) []Release {
+	releases := make([]Release, planned)
+	for i := range releases {
+		releases[i] = testRelease(
+			fmt.Sprintf("v1.%d.0", planned-1-i), time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, planned-i),
+		)
+	}
+	return releases
+}
+
+func TestParseShard(t *testing.T) {
+	for _, test := range []struct {
+		value string
+		shard Shard
+		valid bool
+	}{
+		{"", Shard{}, true},
+		{"1/1", Shard{1, 1}, true},
+		{"3/4", Shard{3, 4}, true},
+		{"4/4", Shard{4, 4}, true},
+		{"0/4", Shard{}, false},
+		{"5/4", Shard{}, false},
+		{"1/0", Shard{}, false},
+		{"-1/4", Shard{}, false},
+		{"1", Shard{}, false},
+		{"a/b", Shard{}, false},
+	} {
+		shard, err := ParseShard(test.value)
+		if (err == nil) != test.valid || shard != test.shard {
+			t.Errorf("ParseShard(%q) = %v, %v", test.value, shard, err)
+		}
+		if test.valid && shard.String() != test.value {
+			t.Errorf("%v is formatted as %q, expected %q", shard, shard.String(), test.value)
+		}
+	}
+}
+
+// TestShardsPartitionPlans checks that the shards of any plan hold each of its releases exactly once,
+// the first shard the oldest releases, with sizes differing by at most one release.
+func TestShardsPartitionPlans(t *testing.T) {
+	for planned := 0; planned <= 40; planned++ {
+		plan := shardPlan(planned)
+		for count := 1; count <= 9; count++ {
+			var merged []Release
+			smallest, largest := planned, 0
+			for index := count; index >= 1; index-- {
+				releases := Shard{index, count}.releases(plan)
+				if again := (Shard{index, count}).releases(plan); !reflect.DeepEqual(again, releases) {
+					t.Fatalf("the shard %d/%d of %d releases changed from %v to %v", index, count, planned, tagNames(releases), tagNames(again))
+				}
+				merged = append(merged, releases...)
+				if len(releases) < smallest {
+					smallest = len(releases)
+				}
+				if len(releases) > largest {
+					largest = len(releases)
+				}
+			}
+			if !reflect.DeepEqual(tagNames(merged), tagNames(plan)) {
+				t.Errorf("the %d shards of %d releases hold %v", count, planned, tagNames(merged))
+			}
+			if largest-smallest > 1 {
+				t.Errorf("the %d shards of %d releases hold from %d to %d releases", count, planned, smallest, largest)
+			}
+		}
+	}
+	if first := (Shard{1, 2}).releases(shardPlan(4)); !reflect.DeepEqual(tagNames(first), []string{"v1.1.0", "v1.0.0"}) {
+		t.Errorf("the first shard holds %v, expected the oldest releases", tagNames(first))
+	}
+}
+
+// shardBundles writes the bundles of the shards of a plan of planned releases, returning their paths by index from 1.
+func shardBundles(t *testing.T, planned, count int) (map[int]string, []Release) {
+	t.Helper()
+	plan := shardPlan(planned)
+	dir := t.TempDir()
+	paths := make(map[int]string)
+	for index := 1; index <= count; index++ {
+		d := data{ghRepo: "owner/repo", firstRelease: plan[len(plan)-1].TagName, secondRelease: plan[0].TagName}
+		d.shard, d.planned = Shard{index, count}, planned
+		d.releases = d.shard.releases(plan)
+		for _, release := range d.releases {
+			d.analysis = append(d.analysis, testAnalysis(release.TagName, 1000))
+		}
+		d.notes = map[string]string{d.releases[0].TagName: fmt.Sprintf("shard %d", index)}
+		content, err := EncodeBundle(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths[index] = filepath.Join(dir, fmt.Sprintf("shard-%d.nsc", index))
+		if err = os.WriteFile(paths[index], content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths, plan
+}
+
+func TestMergeBundles(t *testing.T) {
+	paths, plan := shardBundles(t, 7, 3)
+	merged, err := MergeBundles([]string{paths[2], paths[3], paths[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tagNames(merged.Releases), tagNames(plan)) {
+		t.Errorf("merged %v, expected %v", tagNames(merged.Releases), tagNames(plan))
+	}
+	for i, analysis := range merged.Analysis {
+		if analysis.ReleaseTag != plan[i].TagName {
+			t.Errorf("merged the analysis of %s in place of %s", analysis.ReleaseTag, plan[i].TagName)
+		}
+	}
+	if merged.Shard != "" || merged.Planned != 7 || len(merged.Notes) != 3 {
+		t.Errorf("merged the shard %q of %d releases with the notes %v", merged.Shard, merged.Planned, merged.Notes)
+	}
+
+	other, _ := shardBundles(t, 8, 3)
+	unsharded := filepath.Join(t.TempDir(), "whole.nsc")
+	content, err := EncodeBundle(data{ghRepo: "owner/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(unsharded, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name  string
+		paths []string
+		err   string
+	}{
+		{"none", nil, "no bundle to merge"},
+		{"missing shard", []string{paths[1], paths[3]}, "missing the bundles of the shards 2/3"},
+		{"duplicate shard", []string{paths[1], paths[2], paths[2], paths[3]}, "are both the shard 2/3"},
+		{"unsharded", []string{paths[1], unsharded}, "is not the bundle of a shard"},
+		{"other plan", []string{paths[1], paths[2], other[3]}, "is not a shard of the comparison"},
+	} {
+		if _, err := MergeBundles(test.paths); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("merging %s: got %v, expected an error with %q", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// sortMetric is the metric the summary list is sorted by, cycled with `s`.
+type sortMetric int
+
+const (
+	sortRelease    sortMetric = iota // Release order
+	sortReactions                    // Reactions of the GitHub release
+	sortLines                        // Total lines
+	sortFiles                        // Total files
+	sortTarSize                      // Size of the gzipped tarball
+	sortLinesDelta                   // Change of code lines from the previous release, see lineChange
+	sortMetricsCount
+)
+
+func (s sortMetric) String() string {
+	switch s {
+	case sortReactions:
+		return "reactions"
+	case sortLines:
+		return "lines"
+	case sortFiles:
+		return "files"
+	case sortTarSize:
+		return "tarball size"
+	case sortLinesDelta:
+		return "change of lines"
+	default:
+		return "release"
+	}
+}
+
+// value returns the value of the metric for the release, not measured if unknown.
+func (s sortMetric) value(item ListItem) measure[float64] {
+	var value measure[int64]
+	switch s {
+	case sortReactions:
+		value = measured(int64(item.reactionsCount()))
+	case sortLines:
+		lines := item.measuredLines()
+		value = measure[int64]{int64(lines.value), lines.measured}
+	case sortFiles:
+		files := item.measuredFiles()
+		value = measure[int64]{int64(files.value), files.measured}
+	case sortTarSize:
+		value = item.measuredTarSize()
+	case sortLinesDelta:
+		if item.previous != nil {
+			delta, _ := lineChange(item.previous.AnalysisResult, item.AnalysisResult)
+			value = measure[int64]{int64(delta.value), delta.measured}
+		}
+	}
+	return measure[float64]{float64(value.value), value.measured}
+}
+
+// summarySort is the order of the summary list: a metric, largest first unless ascending.
+// The release order is newest first unless ascending.
+type summarySort struct {
+	metric    sortMetric
+	ascending bool
+}
+
+// next returns the sort by the next metric, in the same direction.
+func (s summarySort) next() summarySort {
+	s.metric = (s.metric + 1) % sortMetricsCount
+	return s
+}
+
+func (s summarySort) String() string {
+	arrow := "↓"
+	if s.ascending {
+		arrow = "↑"
+	}
+	return fmt.Sprintf("sorted by %s %s", s.metric, arrow)
+}
+
+// isDefault returns whether the list is in its default order, the newest release first.
+func (s summarySort) isDefault() bool {
+	return s.metric == sortRelease && !s.ascending
+}
+
+// sort returns the items, in release order, sorted by the metric. The releases whose metric
+// is not measured are listed last whatever the direction, and ties keep the release order.
+// Only the order of the list changes: each item keeps its neighbors in release order,
+// so that the differences from the previous release remain correct.
+func (s summarySort) sort(items []list.Item) []list.Item {
+	sorted := slices.Clone(items)
+	if s.metric == sortRelease {
+		if s.ascending {
+			slices.Reverse(sorted)
+		}
+		return sorted
+	}
+	slices.SortStableFunc(
+		sorted, func(a, b list.Item) int {
+			first, second := s.metric.value(a.(ListItem)), s.metric.value(b.(ListItem))
+			switch {
+			case first.measured != second.measured:
+				if first.measured {
+					return -1
+				}
+				return 1
+			case s.ascending:
+				return cmp.Compare(first.value, second.value)
+			default:
+				return cmp.Compare(second.value, first.value)
+			}
+		},
+	)
+	return sorted
+}
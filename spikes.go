@@ -0,0 +1,134 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultSpikes is the default of `--spikes`.
+const defaultSpikes = 3
+
+// spikeMetric is a metric whose biggest changes from a release to the next one are listed.
+type spikeMetric struct {
+	name   string                                                // Name of the metric in the exports
+	label  string                                                // Label of the metric in the summary
+	change func(previous, current AnalysisResult) measure[int64] // Change of the metric from a release to the next one
+	format func(delta int64) string                              // Rendering of a change of the metric
+}
+
+// spikeMetrics are the metrics whose biggest changes are listed, in the order of the summary.
+var spikeMetrics = []spikeMetric{
+	{
+		"lines", "lines",
+		// The change of code lines, as in the summary list
+		func(previous, current AnalysisResult) measure[int64] {
+			lines, _ := lineChange(previous, current)
+			return measure[int64]{int64(lines.value), lines.measured}
+		},
+		func(delta int64) string {
+			return fmt.Sprintf("%+d", delta)
+		},
+	},
+	{
+		"tar_size", "gz size",
+		func(previous, current AnalysisResult) measure[int64] {
+			return current.measuredTarSize().since(previous.measuredTarSize())
+		},
+		func(delta int64) string {
+			return formatBytesDiff(float64(delta))
+		},
+	},
+}
+
+// spike is the change of a metric from a release to the next one.
+type spike struct {
+	metric   spikeMetric
+	from, to string // Tags of the releases
+	delta    int64
+}
+
+// String renders the spike, such as `v2.0.0 +1200`.
+func (s spike) String() string {
+	return fmt.Sprintf("%s %s", s.to, s.metric.format(s.delta))
+}
+
+// metricSpikes returns the count biggest changes of the metric, in absolute value, between the consecutive
+// releases ordered from the oldest to the newest, the biggest first. Ties are ordered from the newest release
+// to the oldest. The changes involving a release where the metric wasn't measured, or of nothing, are left out.
+func metricSpikes(chronological []AnalysisResult, metric spikeMetric, count int) []spike {
+	var spikes []spike
+	for i := len(chronological) - 1; i > 0; i-- {
+		previous, current := chronological[i-1], chronological[i]
+		if delta := metric.change(previous, current); delta.measured && delta.value != 0 {
+			spikes = append(spikes, spike{metric, previous.releaseTag, current.releaseTag, delta.value})
+		}
+	}
+	// Stable, for the ties to stay from the newest to the oldest
+	slices.SortStableFunc(
+		spikes, func(a, b spike) int {
+			return cmp.Compare(abs(b.delta), abs(a.delta))
+		},
+	)
+	if len(spikes) > count {
+		spikes = spikes[:count]
+	}
+	return spikes
+}
+
+// biggestJumps returns the count biggest changes of each of the spikeMetrics, in their order,
+// between the consecutive analyzed releases. A count of 0 disables them.
+func (d data) biggestJumps(count int) []spike {
+	if count <= 0 {
+		return nil
+	}
+	chronological := d.chronological()
+	var spikes []spike
+	for _, metric := range spikeMetrics {
+		spikes = append(spikes, metricSpikes(chronological, metric, count)...)
+	}
+	return spikes
+}
+
+// spikeTags returns the tags of the releases of the spikes, each once, in the order of the spikes.
+func spikeTags(spikes []spike) []string {
+	var tags []string
+	for _, s := range spikes {
+		if !slices.Contains(tags, s.to) {
+			tags = append(tags, s.to)
+		}
+	}
+	return tags
+}
+
+// spikesPanel renders the biggest jumps of each metric as a ranked line, or an empty string without any.
+func spikesPanel(spikes []spike) string {
+	var rows []string
+	for _, metric := range spikeMetrics {
+		var ranked []string
+		for _, s := range spikes {
+			if s.metric.name == metric.name {
+				ranked = append(ranked, fmt.Sprintf("%d. %s", len(ranked)+1, s))
+			}
+		}
+		if len(ranked) > 0 {
+			rows = append(rows, fmt.Sprintf("Biggest jumps in %s: %s", metric.label, strings.Join(ranked, " • ")))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// jumpToNextSpike selects the release of the next of the biggest jumps in the summary list,
+// cycling through them in the order of the summary.
+func (m model) jumpToNextSpike() (model, tea.Cmd) {
+	tags := spikeTags(m.data.biggestJumps(m.data.spikeCount))
+	if len(tags) == 0 {
+		return m, nil
+	}
+	tag := tags[m.spikeIndex%len(tags)]
+	m.spikeIndex++
+	return m.jumpToRelease(tag)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "fmt"
+
+// releaseStatus is the progress of a release through the download and the analysis.
+// A release failing is reported as such in the summary, and the run goes on with the others.
+type releaseStatus int
+
+const (
+	releasePending    releaseStatus = iota // Not downloaded yet
+	releaseDownloaded                      // Downloaded and extracted, or streamed, not analyzed yet
+	releaseFailed                          // Failed to download or to analyze
+	releaseAnalyzed                        // Analyzed
+)
+
+// setStatus records the status of a release.
+func (m *model) setStatus(release string, status releaseStatus) {
+	if m.releaseStatuses == nil {
+		// Comparisons against a baseline skip the downloads
+		m.releaseStatuses = make(map[string]releaseStatus)
+	}
+	m.releaseStatuses[release] = status
+}
+
+// failedCount returns the number of releases that failed to download or to analyze.
+func (m model) failedCount() int {
+	count := 0
+	for _, status := range m.releaseStatuses {
+		if status == releaseFailed {
+			count++
+		}
+	}
+	return count
+}
+
+// failedText renders the number of failed releases for the progress views, if any.
+func (m model) failedText() string {
+	if count := m.failedCount(); count > 0 {
+		return errorStyle.Render(fmt.Sprintf(" - %d failed", count))
+	}
+	return ""
+}
+
+// downloadFailure returns the analysis result reported for a release that failed to download.
+func downloadFailure(release string, err error) AnalysisResult {
+	return failedAnalysis(release, "download failed: "+err.Error())
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// insecureTLSWarning is the warning shown when the certificates of the npm registry aren't verified.
+const insecureTLSWarning = "the TLS certificates of the npm registry are NOT verified (--insecure-skip-tls-verify): " +
+	"its responses could be tampered with, use --ca-cert with the certificate of its authority instead"
+
+// registryClient is the HTTP client of the requests to the npm registry, trusting the certificates
+// of `--ca-cert` or skipping their verification with `--insecure-skip-tls-verify`.
+// The requests to GitHub keep the default client.
+var registryClient = http.DefaultClient
+
+// ConfigureRegistryTLS configures the client of the npm registry to also trust the certificates
+// of the PEM file, if any, or to skip the verification of the certificates if insecure.
+func ConfigureRegistryTLS(caCert string, insecure bool) error {
+	if caCert == "" && !insecure {
+		registryClient = http.DefaultClient
+		return nil
+	}
+	config, err := registryTLSConfig(caCert, insecure)
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	registryClient = &http.Client{Transport: transport}
+	return nil
+}
+
+// registryTLSConfig returns a TLS configuration trusting the system certificates and the ones
+// of the PEM file, if any, or skipping the verification of the certificates if insecure.
+func registryTLSConfig(caCert string, insecure bool) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecure}
+	if caCert == "" {
+		return config, nil
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// The system certificates are unavailable on some platforms
+		pool = x509.NewCertPool()
+	}
+	content, err := os.ReadFile(caCert)
+	if err != nil {
+		return nil, err
+	}
+	if err = appendCertificates(pool, content); err != nil {
+		return nil, fmt.Errorf("%s: %w", caCert, err)
+	}
+	config.RootCAs = pool
+	return config, nil
+}
+
+// appendCertificates adds the certificates of the PEM blocks of the content to the pool,
+// ignoring the other kinds of blocks such as keys. It fails on an invalid certificate,
+// naming its block, or if the content holds no certificate at all.
+func appendCertificates(pool *x509.CertPool, content []byte) error {
+	count := 0
+	for block, rest := pem.Decode(content); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		count++
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("invalid certificate #%d: %w", count, err)
+		}
+		pool.AddCert(certificate)
+	}
+	if count == 0 {
+		return fmt.Errorf("no PEM certificate found")
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePEM writes the PEM blocks to a file of the test, returning its path.
+func writePEM(t *testing.T, blocks ...*pem.Block) string {
+	t.Helper()
+	var content []byte
+	for _, block := range blocks {
+		content = append(content, pem.EncodeToMemory(block)...)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// certificateBlock returns the PEM block of the certificate of a TLS test server.
+func certificateBlock(server *httptest.Server) *pem.Block {
+	return &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+}
+
+// getWith requests the URL with a client of the TLS configuration.
+func getWith(config *tls.Config, url string) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	response, err := (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+func TestRegistryTLSConfig(t *testing.T) {
+	ok := http.HandlerFunc(
+		func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		},
+	)
+	registry, mirror := httptest.NewTLSServer(ok), httptest.NewTLSServer(ok)
+	defer registry.Close()
+	defer mirror.Close()
+
+	// Without configuration, the certificates of the test authority aren't trusted
+	config, err := registryTLSConfig("", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = getWith(config, registry.URL); err == nil {
+		t.Error("trusted the certificate of an unknown authority")
+	}
+
+	// Every certificate of the bundle is trusted, the other blocks being ignored
+	key := &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("not a certificate")}
+	config, err = registryTLSConfig(writePEM(t, key, certificateBlock(registry), certificateBlock(mirror)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.InsecureSkipVerify || config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("got the configuration %+v", config)
+	}
+	for _, server := range []*httptest.Server{registry, mirror} {
+		if err = getWith(config, server.URL); err != nil {
+			t.Errorf("the certificate of %s isn't trusted: %v", server.URL, err)
+		}
+	}
+
+	config, err = registryTLSConfig("", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = getWith(config, registry.URL); err != nil {
+		t.Errorf("verified the certificate while insecure: %v", err)
+	}
+}
+
+func TestRegistryTLSConfigInvalid(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	if _, err := registryTLSConfig(filepath.Join(t.TempDir(), "missing.pem"), false); !os.IsNotExist(err) {
+		t.Errorf("got %v for a missing file", err)
+	}
+	for _, test := range []struct {
+		name string
+		path func(t *testing.T) string
+		err  string
+	}{
+		{
+			"not PEM", func(t *testing.T) string {
+				path := filepath.Join(t.TempDir(), "ca.pem")
+				if err := os.WriteFile(path, []byte("not a certificate"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			}, "no PEM certificate found",
+		},
+		{
+			"only a key", func(t *testing.T) string {
+				return writePEM(t, &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")})
+			}, "no PEM certificate found",
+		},
+		{
+			"invalid second certificate", func(t *testing.T) string {
+				return writePEM(t, certificateBlock(server), &pem.Block{Type: "CERTIFICATE", Bytes: []byte("corrupted")})
+			}, "invalid certificate #2",
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				path := test.path(t)
+				_, err := registryTLSConfig(path, false)
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("got %v, expected an error with %q", err, test.err)
+				}
+			},
+		)
+	}
+}
+
+func TestConfigureRegistryTLS(t *testing.T) {
+	t.Cleanup(
+		func() {
+			registryClient = http.DefaultClient
+		},
+	)
+	if err := ConfigureRegistryTLS("", true); err != nil {
+		t.Fatal(err)
+	}
+	if registryClient == http.DefaultClient {
+		t.Error("the registry client is the default one while insecure")
+	}
+	// The requests to GitHub keep verifying the certificates
+	if http.DefaultTransport.(*http.Transport).TLSClientConfig != nil &&
+		http.DefaultTransport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Error("the default transport skips the verification of the certificates")
+	}
+	if err := ConfigureRegistryTLS(filepath.Join(t.TempDir(), "missing.pem"), false); err == nil {
+		t.Error("configured a missing certificate file")
+	}
+	if err := ConfigureRegistryTLS("", false); err != nil || registryClient != http.DefaultClient {
+		t.Errorf("the registry client isn't the default one without configuration: %v", err)
+	}
+}
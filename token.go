@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// tokenEnvVars are the environment variables a GitHub token is read from, in order of precedence.
+var tokenEnvVars = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+
+// ResolveToken returns the GitHub token to use and where it comes from, trying in order
+// the `--token` flag, the tokenEnvVars and the credentials of the gh CLI.
+// It returns empty strings if none of them provides a token.
+func ResolveToken(flagToken string) (token, source string) {
+	if flagToken != "" {
+		return flagToken, "--token"
+	}
+	for _, name := range tokenEnvVars {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token, name
+		}
+	}
+	if token := ghCLIToken(ghHostsPath()); token != "" {
+		return token, "gh CLI"
+	}
+	return "", ""
+}
+
+// ghHostsPath returns the path of the hosts file of the gh CLI, holding its credentials.
+func ghHostsPath() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI", "hosts.yml")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
+
+// ghCLIToken returns the github.com token of the hosts file of the gh CLI.
+// The file is a small YAML document, so only the `oauth_token` of the `github.com:` section is read;
+// recent versions of gh keep the token in the system keyring instead, where it isn't found.
+func ghCLIToken(hostsPath string) string {
+	if hostsPath == "" {
+		return ""
+	}
+	file, err := os.Open(hostsPath)
+	if err != nil {
+		return ""
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	inGitHub := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			// A top-level key starts the section of a host
+			inGitHub = strings.TrimSuffix(trimmed, ":") == "github.com"
+			continue
+		}
+		if value, ok := strings.CutPrefix(trimmed, "oauth_token:"); ok && inGitHub {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// maskToken hides a token but its prefix and its last characters, e.g. "ghp_…a1b2".
+func maskToken(token string) string {
+	if len(token) < 12 {
+		return strings.Repeat("•", len(token))
+	}
+	prefix := ""
+	if i := strings.IndexByte(token, '_'); i != -1 && i < 12 {
+		prefix = token[:i+1]
+	}
+	return prefix + "…" + token[len(token)-4:]
+}
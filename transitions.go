@@ -0,0 +1,99 @@
+package main
+
+import "fmt"
+
+// pipelineEvent is an event making the pipeline move to another state.
+type pipelineEvent int
+
+const (
+	// eventStarted is sent when the inputs are known.
+	eventStarted pipelineEvent = iota
+	// eventResumed is sent when the inputs are known and match an interrupted run.
+	eventResumed
+	// eventChecked is sent when both releases exist.
+	eventChecked
+	// eventFetched is sent when the releases to analyze are fetched.
+	eventFetched
+	// eventDownloaded is sent when all the releases are downloaded.
+	eventDownloaded
+	// eventAnalyzed is sent when all the releases are analyzed.
+	eventAnalyzed
+)
+
+// pipelineFlow is a sequence of states the pipeline goes through.
+type pipelineFlow int
+
+const (
+	// flowStandard checks, fetches, downloads and analyzes GitHub releases.
+	flowStandard pipelineFlow = iota
+	// flowLocal analyzes a local directory against a baseline file.
+	flowLocal
+	// flowRegistryOnly fetches, downloads and analyzes the versions published to the npm registry,
+	// without any GitHub release to check, see `--registry-only`.
+	flowRegistryOnly
+	// flowSkipCheck fetches, downloads and analyzes GitHub releases without checking them first,
+	// see `--skip-check`.
+	flowSkipCheck
+)
+
+// fromGitHub returns whether the releases of the flow are GitHub releases.
+func (f pipelineFlow) fromGitHub() bool {
+	return f == flowStandard || f == flowSkipCheck
+}
+
+// transitions holds the legal transitions of each flow,
+// from a state and an event to the next state.
+var transitions = map[pipelineFlow]map[State]map[pipelineEvent]State{
+	flowStandard: {
+		StateInit:            {eventStarted: StateChecking, eventResumed: StateDownloadExtract},
+		StateChecking:        {eventChecked: StateFetching},
+		StateFetching:        {eventFetched: StateDownloadExtract},
+		StateDownloadExtract: {eventDownloaded: StateAnalyzing},
+		StateAnalyzing:       {eventAnalyzed: StateSummary},
+	},
+	flowLocal: {
+		StateInit:      {eventStarted: StateAnalyzing},
+		StateAnalyzing: {eventAnalyzed: StateSummary},
+	},
+	flowRegistryOnly: {
+		StateInit:            {eventStarted: StateFetching, eventResumed: StateDownloadExtract},
+		StateFetching:        {eventFetched: StateDownloadExtract},
+		StateDownloadExtract: {eventDownloaded: StateAnalyzing},
+		StateAnalyzing:       {eventAnalyzed: StateSummary},
+	},
+	flowSkipCheck: {
+		StateInit:            {eventStarted: StateFetching, eventResumed: StateDownloadExtract},
+		StateFetching:        {eventFetched: StateDownloadExtract},
+		StateDownloadExtract: {eventDownloaded: StateAnalyzing},
+		StateAnalyzing:       {eventAnalyzed: StateSummary},
+	},
+}
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateInit:
+		return "init"
+	case StateChecking:
+		return "checking"
+	case StateFetching:
+		return "fetching"
+	case StateDownloadExtract:
+		return "download/extract"
+	case StateAnalyzing:
+		return "analyzing"
+	case StateSummary:
+		return "summary"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// nextState returns the state following the current state on an event within a flow,
+// or an error if the transition is not legal.
+func nextState(flow pipelineFlow, current State, event pipelineEvent) (State, error) {
+	if next, ok := transitions[flow][current][event]; ok {
+		return next, nil
+	}
+	return current, fmt.Errorf("illegal transition from the %s state on event %d", current, event)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// statePaths returns every path of states the flow can go through from StateInit until a state
+// without any transition, walking the events in their order.
+func statePaths(flow pipelineFlow) [][]State {
+	var paths [][]State
+	var walk func(path []State)
+	walk = func(path []State) {
+		current := path[len(path)-1]
+		leaf := true
+		for event := eventStarted; event <= eventAnalyzed; event++ {
+			if next, err := nextState(flow, current, event); err == nil {
+				leaf = false
+				walk(append(path[:len(path):len(path)], next))
+			}
+		}
+		if leaf {
+			paths = append(paths, path)
+		}
+	}
+	walk([]State{StateInit})
+	return paths
+}
+
+func TestTransitionsLegalPaths(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		flow  pipelineFlow
+		paths [][]State
+	}{
+		{
+			"standard", flowStandard, [][]State{
+				{StateInit, StateChecking, StateFetching, StateDownloadExtract, StateAnalyzing, StateSummary},
+				// A resumed run downloads the releases of the interrupted one
+				{StateInit, StateDownloadExtract, StateAnalyzing, StateSummary},
+			},
+		},
+		{
+			"local", flowLocal, [][]State{
+				{StateInit, StateAnalyzing, StateSummary},
+			},
+		},
+		{
+			"registry-only", flowRegistryOnly, [][]State{
+				{StateInit, StateFetching, StateDownloadExtract, StateAnalyzing, StateSummary},
+				{StateInit, StateDownloadExtract, StateAnalyzing, StateSummary},
+			},
+		},
+		{
+			"skip-check", flowSkipCheck, [][]State{
+				{StateInit, StateFetching, StateDownloadExtract, StateAnalyzing, StateSummary},
+				{StateInit, StateDownloadExtract, StateAnalyzing, StateSummary},
+			},
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if paths := statePaths(test.flow); !reflect.DeepEqual(paths, test.paths) {
+					t.Errorf("got the paths %v, expected %v", paths, test.paths)
+				}
+			},
+		)
+	}
+}
+
+func TestTransitionsIllegal(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		flow    pipelineFlow
+		current State
+		event   pipelineEvent
+	}{
+		{"standard skipping the check", flowStandard, StateInit, eventChecked},
+		{"standard checking twice", flowStandard, StateFetching, eventChecked},
+		{"standard analyzing before downloading", flowStandard, StateFetching, eventAnalyzed},
+		{"standard going back", flowStandard, StateAnalyzing, eventDownloaded},
+		{"standard after the summary", flowStandard, StateSummary, eventStarted},
+		{"local resumed", flowLocal, StateInit, eventResumed},
+		{"local checking", flowLocal, StateChecking, eventChecked},
+		{"local downloading", flowLocal, StateInit, eventDownloaded},
+		{"local after the summary", flowLocal, StateSummary, eventAnalyzed},
+		{"registry-only checking", flowRegistryOnly, StateChecking, eventChecked},
+		{"registry-only analyzing before downloading", flowRegistryOnly, StateFetching, eventAnalyzed},
+		{"skip-check checking", flowSkipCheck, StateChecking, eventChecked},
+		{"skip-check downloading before fetching", flowSkipCheck, StateInit, eventDownloaded},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				next, err := nextState(test.flow, test.current, test.event)
+				if err == nil {
+					t.Fatalf("moved from the %s state to the %s state on event %d", test.current, next, test.event)
+				}
+				if next != test.current {
+					t.Errorf("moved to the %s state from the %s state on an illegal event", next, test.current)
+				}
+			},
+		)
+	}
+}
+
+func TestModelFlow(t *testing.T) {
+	baseline := testAnalysis("v1.0.0", 1000)
+	for _, test := range []struct {
+		name   string
+		config config
+		local  bool
+		flow   pipelineFlow
+	}{
+		{"GitHub releases", config{}, false, flowStandard},
+		{"local directory against a baseline", config{localDir: "./package"}, true, flowLocal},
+		{"registry-only", config{registryOnly: true}, false, flowRegistryOnly},
+		{"skip-check", config{skipCheck: true}, false, flowSkipCheck},
+		{"registry-only skipping the check", config{registryOnly: true, skipCheck: true}, false, flowRegistryOnly},
+		{"local directory skipping the check", config{localDir: "./package", skipCheck: true}, true, flowLocal},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				var m model
+				m.data.config = test.config
+				if test.local {
+					m.data.baseline = &baseline
+				}
+				if flow := m.flow(); flow != test.flow {
+					t.Errorf("got flow %d, expected %d", flow, test.flow)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParsePercent parses a human-friendly percentage, such as `20%` or `20`,
+// into a ratio, such as 0.2. Negative percentages are rejected.
+func ParsePercent(value string) (float64, error) {
+	number := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	percent, err := strconv.ParseFloat(number, 64)
+	if err != nil || percent < 0 {
+		return 0, fmt.Errorf("invalid percentage %q, expected a positive number such as 20%%", value)
+	}
+	return percent / 100, nil
+}
+
+// ByteCountSI formats a number of bytes with a decimal unit, such as `1.2 kB` or `3.4 MB`,
+// as npm reports the unpacked sizes. Negative numbers keep their sign.
+func ByteCountSI(bytes int64) string {
+	sign := ""
+	if bytes < 0 {
+		sign, bytes = "-", -bytes
+	}
+	const unit = 1000
+	if bytes < unit {
+		return fmt.Sprintf("%s%d B", sign, bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%s%.1f %cB", sign, float64(bytes)/float64(div), "kMGTPE"[exp])
+}
+
+// byteCountSIDiff formats a signed difference of bytes with ByteCountSI.
+func byteCountSIDiff(bytes int64) string {
+	if bytes < 0 {
+		return ByteCountSI(bytes)
+	}
+	return "+" + ByteCountSI(bytes)
+}
+
+// sizeUnits are the units of ParseSize, both decimal and binary, by lowercase suffix.
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a human-friendly size, such as `2GB`, `512 MiB` or `1000`,
+// into a number of bytes. Negative sizes are rejected.
+func ParseSize(value string) (int64, error) {
+	trimmed := strings.TrimSpace(value)
+	split := strings.IndexFunc(
+		trimmed, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		},
+	)
+	if split == -1 {
+		split = len(trimmed)
+	}
+	number, unit := trimmed[:split], strings.ToLower(strings.TrimSpace(trimmed[split:]))
+	size, err := strconv.ParseFloat(number, 64)
+	multiplier, known := sizeUnits[unit]
+	if err != nil || !known {
+		return 0, fmt.Errorf("invalid size %q, expected a number of bytes such as 2GB or 512MiB", value)
+	}
+	return int64(size * multiplier), nil
+}
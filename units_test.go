@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestByteCountSI(t *testing.T) {
+	for _, test := range []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1000, "1.0 kB"},
+		{1234, "1.2 kB"},
+		{999_949, "999.9 kB"},
+		{1_000_000, "1.0 MB"},
+		{2_500_000_000, "2.5 GB"},
+		{-1234, "-1.2 kB"},
+	} {
+		if got := ByteCountSI(test.bytes); got != test.want {
+			t.Errorf("ByteCountSI(%d) = %q, want %q", test.bytes, got, test.want)
+		}
+	}
+}
+
+func TestByteCountSIDiff(t *testing.T) {
+	for bytes, want := range map[int64]string{0: "+0 B", 1500: "+1.5 kB", -1500: "-1.5 kB"} {
+		if got := byteCountSIDiff(bytes); got != want {
+			t.Errorf("byteCountSIDiff(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
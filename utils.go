@@ -2,96 +2,378 @@ package main
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bufio"
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 )
 
+// archiveFormat is the packaging of a release archive.
+type archiveFormat int
+
+const (
+	archiveGzipTar archiveFormat = iota // Gzipped tar, as served by npm
+	archiveTar                          // Plain tar, without compression
+	archiveZip                          // Zip
+)
+
+// sniffArchive detects the format of an archive from its magic bytes,
+// without consuming them. Content types can't be trusted, as registries
+// and GitHub assets often serve archives as generic binary streams.
+// Anything neither gzipped nor zipped is assumed to be a plain tar.
+func sniffArchive(reader *bufio.Reader) archiveFormat {
+	magic, _ := reader.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return archiveGzipTar
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")), bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		return archiveZip
+	default:
+		return archiveTar
+	}
+}
+
+// ExtractLimits caps what the extraction of a single archive may write,
+// so that a hostile or corrupted archive can't fill the disk. A zero cap is no cap.
+type ExtractLimits struct {
+	MaxTotalBytes int64       // Bytes of all the extracted files
+	MaxFileBytes  int64       // Bytes of a single extracted file
+	MaxEntries    int         // Number of entries, directories included
+	Disk          *DiskBudget // Budget shared by all the extractions of the run, nil for none
+}
+
+// defaultExtractLimits are the extraction limits, way above what any npm package needs.
+var defaultExtractLimits = ExtractLimits{
+	MaxTotalBytes: 2_000_000_000,
+	MaxFileBytes:  512 << 20,
+	MaxEntries:    200_000,
+}
+
+// ExtractLimitError is the error of an extraction exceeding one of its limits.
+type ExtractLimitError struct {
+	Limit string // Name of the exceeded limit
+	Value string // Value of the exceeded limit
+	Entry string // Name of the entry exceeding the limit
+}
+
+func (e ExtractLimitError) Error() string {
+	return fmt.Sprintf("extraction aborted at %s: more than the %s of %s, possibly a decompression bomb", e.Entry, e.Limit, e.Value)
+}
+
+// Extract takes a destination path and a reader over an archive, either a gzipped tar,
+// a plain tar or a zip, and creates its file structure at destDir like Untar does,
+// within the limits.
+func Extract(destDir string, reader io.Reader, limits ExtractLimits) error {
+	return walkArchive(reader, limits.MaxTotalBytes, extractEntry(longPath(destDir), limits))
+}
+
 // Untar takes a destination path and a reader; a tar reader loops over the tar file
 // creating the file structure at 'dst' along the way, and writing any files.
+// On Windows, the files are created through their extended-length paths,
+// as npm packages may be nested deeper than MAX_PATH allows.
 func Untar(destDir string, reader io.Reader) error {
+	return WalkTar(reader, extractEntry(longPath(destDir), defaultExtractLimits))
+}
+
+// Unzip takes a destination path and a reader over a zip file,
+// and creates its file structure at destDir like Untar does.
+func Unzip(destDir string, reader io.Reader) error {
+	return walkZip(reader, defaultExtractLimits.MaxTotalBytes, extractEntry(longPath(destDir), defaultExtractLimits))
+}
+
+// entryTarget returns the path an archive entry is extracted to within destDir.
+// It fails for the entries with an absolute path or escaping destDir through "..",
+// as a crafted archive could otherwise overwrite any file of the user.
+func entryTarget(destDir, name string) (string, error) {
+	if path.IsAbs(filepath.ToSlash(name)) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("refusing to extract %s: absolute path", name)
+	}
+	target := filepath.Join(destDir, name)
+	if !isWithin(target, filepath.Clean(destDir)) {
+		return "", fmt.Errorf("refusing to extract %s: outside of the extraction directory", name)
+	}
+	return target, nil
+}
+
+// extractEntry returns the function creating the entries of an archive within destDir.
+// Symbolic and hard links are skipped, so that no entry can be written through a link
+// pointing outside of destDir. The limits are enforced over the bytes actually written,
+// as the sizes announced by the headers can't be trusted.
+func extractEntry(destDir string, limits ExtractLimits) func(header *tar.Header, content io.Reader) error {
+	var entries int
+	var written int64
+	return func(header *tar.Header, content io.Reader) error {
+		entries++
+		if limits.MaxEntries > 0 && entries > limits.MaxEntries {
+			return ExtractLimitError{"maximum entry count", fmt.Sprint(limits.MaxEntries), header.Name}
+		}
+		target, err := entryTarget(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil && !os.IsExist(err) {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil && !os.IsExist(err) {
+				return err
+			}
+
+			// Only keep the permission bits of the mode, which may be negative or overflowing,
+			// and make sure the file can be written and read back
+			mode := os.FileMode(header.Mode).Perm() | 0600
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+
+			// Read one byte past the remaining budget to detect an exceeded limit
+			budget, limit := int64(-1), ExtractLimitError{Entry: header.Name}
+			if limits.MaxFileBytes > 0 {
+				budget = limits.MaxFileBytes
+				limit.Limit, limit.Value = "maximum file size", formatBytes(float64(limits.MaxFileBytes))
+			}
+			if remaining := limits.MaxTotalBytes - written; limits.MaxTotalBytes > 0 && (budget < 0 || remaining < budget) {
+				budget = remaining
+				limit.Limit, limit.Value = "maximum extracted size", formatBytes(float64(limits.MaxTotalBytes))
+			}
+			if budget >= 0 {
+				content = io.LimitReader(content, budget+1)
+			}
+			var writer io.Writer = file
+			if limits.Disk != nil {
+				writer = io.MultiWriter(file, limits.Disk)
+			}
+			copied, err := io.Copy(writer, content)
+			written += copied
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("extracting %s: %w", header.Name, err)
+			}
+			if budget >= 0 && copied > budget {
+				return limit
+			}
+		}
+		return nil
+	}
+}
+
+// WalkArchive takes a reader over an archive, either a gzipped tar, a plain tar
+// or a zip, and calls fn for each of its entries like WalkTar does.
+// Zip entries are described by tar headers of regular files and directories.
+func WalkArchive(reader io.Reader, fn func(header *tar.Header, content io.Reader) error) error {
+	return walkArchive(reader, defaultExtractLimits.MaxTotalBytes, fn)
+}
+
+// walkArchive is WalkArchive reading at most maxZipBytes of a zip file, 0 for no limit.
+func walkArchive(reader io.Reader, maxZipBytes int64, fn func(header *tar.Header, content io.Reader) error) error {
+	buffered := bufio.NewReader(reader)
+	switch sniffArchive(buffered) {
+	case archiveZip:
+		return walkZip(buffered, maxZipBytes, fn)
+	case archiveTar:
+		return walkTar(tar.NewReader(buffered), fn)
+	default:
+		return WalkTar(buffered, fn)
+	}
+}
+
+// WalkTar takes a gzipped tar reader and calls fn for each entry of the tar file,
+// with the entry header and a reader over the entry content.
+// A truncated or corrupted gzip stream fails, even past the end of the tar file.
+func WalkTar(reader io.Reader, fn func(header *tar.Header, content io.Reader) error) error {
 	gzReader, err := gzip.NewReader(reader)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid gzip stream: %w", err)
 	}
 	defer func(gzr *gzip.Reader) {
-		err = gzr.Close()
-		if err != nil {
-			panic(err)
-		}
+		_ = gzr.Close()
 	}(gzReader)
 
-	tarReader := tar.NewReader(gzReader)
+	if err = walkTar(tar.NewReader(gzReader), fn); err != nil {
+		return err
+	}
+	// The checksum of the gzip stream is only verified once it is read to its end
+	if _, err = io.Copy(io.Discard, gzReader); err != nil {
+		return fmt.Errorf("invalid gzip stream: %w", err)
+	}
+	return nil
+}
 
+// walkTar calls fn for each entry of a tar reader.
+func walkTar(tarReader *tar.Reader, fn func(header *tar.Header, content io.Reader) error) error {
 	for {
-		var header *tar.Header
-		header, err = tarReader.Next()
+		header, err := tarReader.Next()
 
 		switch {
 		case err == io.EOF:
 			return nil
 		case err != nil:
-			return err
+			return fmt.Errorf("invalid tar stream: %w", err)
 		case header == nil:
 			continue
 		}
 
-		target := filepath.Join(destDir, header.Name)
+		if err = fn(header, tarReader); err != nil {
+			return err
+		}
+	}
+}
 
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err = os.MkdirAll(target, 0755); err != nil && !os.IsExist(err) {
-				return err
-			}
-		case tar.TypeReg:
-			if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil && !os.IsExist(err) {
-				return err
-			}
+// walkZip calls fn for each regular file and directory of a zip file of at most maxBytes, 0 for no limit.
+// The central directory of a zip file is at its end, so the whole file is first spooled to a temporary file.
+func walkZip(reader io.Reader, maxBytes int64, fn func(header *tar.Header, content io.Reader) error) error {
+	spool, err := os.CreateTemp("", "npm-stats-comparator-*.zip")
+	if err != nil {
+		return err
+	}
+	defer func(spool *os.File) {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}(spool)
 
-			var file *os.File
-			file, err = os.OpenFile(target, os.O_CREATE|os.O_WRONLY, os.FileMode(header.Mode))
-			if err != nil {
-				return err
-			}
+	// Read one byte past the limit to detect an exceeded limit
+	if maxBytes > 0 {
+		reader = io.LimitReader(reader, maxBytes+1)
+	}
+	size, err := io.Copy(spool, reader)
+	if err != nil {
+		return err
+	}
+	if maxBytes > 0 && size > maxBytes {
+		return ExtractLimitError{"maximum extracted size", formatBytes(float64(maxBytes)), "the zip file"}
+	}
+	zipReader, err := zip.NewReader(spool, size)
+	if err != nil {
+		return fmt.Errorf("invalid zip file: %w", err)
+	}
 
-			if _, err = io.Copy(file, tarReader); err != nil {
-				return err
-			}
+	for _, file := range zipReader.File {
+		header := &tar.Header{
+			Name: file.Name,
+			Size: int64(file.UncompressedSize64),
+			Mode: int64(file.Mode().Perm()),
+		}
+		switch {
+		case file.FileInfo().IsDir():
+			header.Typeflag = tar.TypeDir
+		case file.Mode().IsRegular():
+			header.Typeflag = tar.TypeReg
+		default:
+			// Symbolic links and other special files aren't extracted from tar files either
+			continue
+		}
 
-			_ = file.Close()
+		entry, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("invalid zip entry %s: %w", file.Name, err)
+		}
+		err = fn(header, entry)
+		_ = entry.Close()
+		if err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// byteCounter is an io.Writer that counts the bytes written to it.
+type byteCounter uint64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// maxEmptyReads is the number of consecutive empty reads after which
+// a reader is considered stuck, like bufio does.
+const maxEmptyReads = 100
+
+// LineCounts is the number of lines of a content by line ending.
+type LineCounts struct {
+	LF   uint // Lines ending with a bare \n
+	CRLF uint // Lines ending with \r\n
+	// Lines once whitespace normalized, see whitespaceNormalizer, 0 unless counted with `--ignore-whitespace`
+	Normalized uint
+	Kinds      LineKinds // Lines by kind, see lineClassifier
+}
+
+// Total returns the number of lines, whatever their ending.
+func (c LineCounts) Total() uint {
+	return c.LF + c.CRLF
 }
 
-// CountLines takes a reader and counts the number of lines in the reader.
-func CountLines(reader io.Reader) (uint, error) {
-	var count uint
+// CountLines takes a reader and counts the number of lines in the reader, by line ending.
+// It only uses a fixed-size buffer, whatever the size of the content.
+func CountLines(reader io.Reader) (LineCounts, error) {
+	var counts LineCounts
 	const lineBreak = '\n'
 
 	buf := make([]byte, bufio.MaxScanTokenSize)
 
+	emptyReads := 0
+	previousCR := false // Whether the previous read ended with a \r
 	for {
 		bufferSize, err := reader.Read(buf)
 		if err != nil && err != io.EOF {
-			return 0, err
+			return LineCounts{}, err
 		}
 
-		var buffPosition int
-		for {
-			i := bytes.IndexByte(buf[buffPosition:], lineBreak)
-			if i == -1 || bufferSize == buffPosition {
-				break
-			}
-			buffPosition += i + 1
-			count++
+		// Only count the line breaks of the bytes read this time
+		read := buf[:bufferSize]
+		lines := uint(bytes.Count(read, []byte{lineBreak}))
+		crlf := uint(bytes.Count(read, []byte{'\r', lineBreak}))
+		if previousCR && bufferSize > 0 && read[0] == lineBreak {
+			// \r\n split across two reads
+			crlf++
+		}
+		counts.CRLF += crlf
+		counts.LF += lines - crlf
+		if bufferSize > 0 {
+			previousCR = read[bufferSize-1] == '\r'
 		}
 		if err == io.EOF {
 			break
 		}
+
+		if bufferSize == 0 {
+			emptyReads++
+			if emptyReads >= maxEmptyReads {
+				return LineCounts{}, io.ErrNoProgress
+			}
+		} else {
+			emptyReads = 0
+		}
 	}
 
-	return count, nil
+	return counts, nil
+}
+
+// writeFileAtomically writes a file through a temporary file renamed over it,
+// so that the file is either complete or left as it was.
+func writeFileAtomically(path string, content []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = temp.Write(content); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), path)
 }
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// chunkReader reads its content by chunks of at most size bytes, as a network stream may.
+type chunkReader struct {
+	content []byte
+	size    int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.content) == 0 {
+		return 0, io.EOF
+	}
+	if len(p) > r.size {
+		p = p[:r.size]
+	}
+	n := copy(p, r.content)
+	r.content = r.content[n:]
+	return n, nil
+}
+
+func FuzzCountLines(f *testing.F) {
+	f.Add([]byte(""), uint8(1))
+	f.Add([]byte("a\nb\n"), uint8(1))
+	f.Add([]byte("a\r\nb\r\n\r\n"), uint8(2))
+	f.Add([]byte("\r\r\n\n\r"), uint8(1))
+	f.Add([]byte("no line break"), uint8(3))
+	f.Add(bytes.Repeat([]byte("a\r\n"), 30_000), uint8(0))
+	f.Fuzz(
+		func(t *testing.T, content []byte, chunk uint8) {
+			// A chunk of 0 reads the content at once
+			size := int(chunk)
+			if size == 0 {
+				size = len(content) + 1
+			}
+			counts, err := CountLines(&chunkReader{content, size})
+			if err != nil {
+				t.Fatal(err)
+			}
+			crlf := uint(bytes.Count(content, []byte("\r\n")))
+			if lf := uint(bytes.Count(content, []byte("\n"))) - crlf; counts.LF != lf || counts.CRLF != crlf {
+				t.Errorf("counted %d LF and %d CRLF lines, expected %d and %d", counts.LF, counts.CRLF, lf, crlf)
+			}
+		},
+	)
+}
+
+func TestCountLinesErrors(t *testing.T) {
+	failure := errors.New("connection reset")
+	if _, err := CountLines(iotest.ErrReader(failure)); !errors.Is(err, failure) {
+		t.Errorf("got %v, expected the error of the reader", err)
+	}
+	// A reader returning nothing forever doesn't make CountLines loop forever
+	if _, err := CountLines(&chunkReader{[]byte("a\n"), 0}); !errors.Is(err, io.ErrNoProgress) {
+		t.Errorf("got %v, expected %v", err, io.ErrNoProgress)
+	}
+}
+
+// checkExtracted checks that an extraction to dest, within parent, only created files and directories
+// within dest, so that no crafted archive can write elsewhere.
+func checkExtracted(t *testing.T, parent, dest string) {
+	t.Helper()
+	err := filepath.WalkDir(
+		parent, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != parent && path != dest && !isWithin(path, dest) {
+				t.Errorf("extracted %s outside of %s", path, dest)
+			}
+			if !d.IsDir() && !d.Type().IsRegular() {
+				t.Errorf("extracted %s of type %s", path, d.Type())
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// archiveSeeds are archives of every format, well-formed, crafted or corrupted.
+func archiveSeeds(t testing.TB) [][]byte {
+	tarball := packageTarball(t)
+	var zipped bytes.Buffer
+	writer := zip.NewWriter(&zipped)
+	for _, name := range []string{"package/index.js", "../escaped.js"} {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = file.Write([]byte("export default 1;\n"))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return [][]byte{
+		tarball,
+		tarball[:len(tarball)/2],
+		testTarball(t, craftedEntries()...),
+		// Modes negative or overflowing the permission bits
+		testTarball(
+			t,
+			tarEntry{tar.Header{Name: "package/a.js", Typeflag: tar.TypeReg, Mode: -1, Size: 1}, "a"},
+			tarEntry{tar.Header{Name: "package/b.js", Typeflag: tar.TypeReg, Mode: 1 << 40, Size: 1}, "b"},
+		),
+		zipped.Bytes(),
+		{},
+		[]byte("not an archive"),
+	}
+}
+
+func FuzzUntar(f *testing.F) {
+	for _, seed := range archiveSeeds(f) {
+		f.Add(seed)
+	}
+	f.Fuzz(
+		func(t *testing.T, archive []byte) {
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "release")
+			_ = Untar(dest, bytes.NewReader(archive))
+			checkExtracted(t, parent, dest)
+		},
+	)
+}
+
+func FuzzExtract(f *testing.F) {
+	for _, seed := range archiveSeeds(f) {
+		f.Add(seed)
+	}
+	limits := ExtractLimits{MaxTotalBytes: 1 << 20, MaxFileBytes: 64 << 10, MaxEntries: 100}
+	f.Fuzz(
+		func(t *testing.T, archive []byte) {
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "release")
+			err := Extract(dest, bytes.NewReader(archive), limits)
+			checkExtracted(t, parent, dest)
+			if err != nil {
+				return
+			}
+			// Nothing beyond the limits is extracted
+			var total int64
+			_ = filepath.WalkDir(
+				dest, func(path string, d fs.DirEntry, err error) error {
+					if err == nil && d.Type().IsRegular() {
+						info, err := os.Stat(path)
+						if err == nil {
+							total += info.Size()
+						}
+					}
+					return nil
+				},
+			)
+			if total > limits.MaxTotalBytes {
+				t.Errorf("extracted %d bytes, over the limit of %d", total, limits.MaxTotalBytes)
+			}
+		},
+	)
+}
+
+func TestUntarTruncated(t *testing.T) {
+	tarball := packageTarball(t)
+	if err := Untar(t.TempDir(), bytes.NewReader(tarball[:len(tarball)-10])); err == nil {
+		t.Error("extracted a truncated tarball")
+	}
+	if err := Untar(t.TempDir(), bytes.NewReader([]byte("not a tarball"))); err == nil {
+		t.Error("extracted a file that isn't a tarball")
+	}
+}
+
+func TestEntryTarget(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "release")
+	for _, test := range []struct {
+		name   string
+		target string // Slash-separated path within dest, empty if refused
+	}{
+		{"package/index.js", "package/index.js"},
+		{"./package/lib/../index.js", "package/index.js"},
+		{"package/..", "."},
+		{"../escaped.js", ""},
+		{"package/../../escaped.js", ""},
+		{"package/../../release-sibling/a.js", ""},
+		{"/etc/passwd", ""},
+	} {
+		target, err := entryTarget(dest, test.name)
+		switch {
+		case test.target == "" && err == nil:
+			t.Errorf("%s is extracted to %s", test.name, target)
+		case test.target != "" && err != nil:
+			t.Errorf("%s is refused: %v", test.name, err)
+		case test.target != "" && target != filepath.Join(dest, filepath.FromSlash(test.target)):
+			t.Errorf("%s is extracted to %s, expected %s", test.name, target, test.target)
+		}
+	}
+}
+
+func TestUntarCraftedEntries(t *testing.T) {
+	for _, entry := range craftedEntries() {
+		t.Run(
+			entry.header.Name, func(t *testing.T) {
+				parent := t.TempDir()
+				dest := filepath.Join(parent, "release")
+				tarball := testTarball(t, tarFile("package/index.js", "export default 1;\n"), entry)
+				err := Untar(dest, bytes.NewReader(tarball))
+				checkExtracted(t, parent, dest)
+				switch {
+				case entry.header.Typeflag == tar.TypeSymlink || entry.header.Typeflag == tar.TypeLink:
+					// Links are skipped
+					if err != nil {
+						t.Fatal(err)
+					}
+					if _, err := os.Lstat(filepath.Join(dest, entry.header.Name)); !os.IsNotExist(err) {
+						t.Errorf("the link %s was extracted", entry.header.Name)
+					}
+				case strings.HasPrefix(entry.header.Name, "package/link/"):
+					// Without the link, the file is extracted within the destination, see TestUntarThroughSkippedLink
+					if err != nil {
+						t.Fatal(err)
+					}
+				case err == nil:
+					t.Fatalf("%s was extracted", entry.header.Name)
+				case !strings.Contains(err.Error(), "refusing to extract "+entry.header.Name):
+					t.Errorf("undescriptive error %q", err)
+				}
+			},
+		)
+	}
+}
+
+// TestUntarThroughSkippedLink checks that a file under a skipped symbolic link
+// is extracted to a directory within the destination, not through the link.
+func TestUntarThroughSkippedLink(t *testing.T) {
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "release")
+	tarball := testTarball(
+		t,
+		tarEntry{tar.Header{Name: "package/link", Typeflag: tar.TypeSymlink, Linkname: parent}, ""},
+		tarFile("package/link/escaped.js", "through the link"),
+	)
+	if err := Untar(dest, bytes.NewReader(tarball)); err != nil {
+		t.Fatal(err)
+	}
+	checkExtracted(t, parent, dest)
+	if _, err := os.Stat(filepath.Join(dest, "package", "link", "escaped.js")); err != nil {
+		t.Error(err)
+	}
+}
+
+// archiveFormats returns the archive of the package tarball in every supported format.
+func archiveFormats(t *testing.T) map[string][]byte {
+	t.Helper()
+	tarball := packageTarball(t)
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var zipped bytes.Buffer
+	writer := zip.NewWriter(&zipped)
+	tarReader := tar.NewReader(bytes.NewReader(plain))
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeDir {
+			continue
+		}
+		file, err := writer.Create(header.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(file, tarReader); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return map[string][]byte{"gzip tar": tarball, "plain tar": plain, "zip": zipped.Bytes()}
+}
+
+// TestDownloadArchiveFormats checks that every archive format is extracted
+// whatever its content type, as the format is sniffed from the content.
+func TestDownloadArchiveFormats(t *testing.T) {
+	for format, archive := range archiveFormats(t) {
+		t.Run(
+			format, func(t *testing.T) {
+				withFakeAPI(
+					t, http.HandlerFunc(
+						func(writer http.ResponseWriter, request *http.Request) {
+							writer.Header().Set("Content-Type", "application/octet-stream")
+							_, _ = writer.Write(archive)
+						},
+					),
+				)
+				dest := filepath.Join(t.TempDir(), "pkg@1.0.0")
+				msg := DownloadGitHubRelease(context.Background(), "pkg@1.0.0", dest, "", CacheExists, defaultExtractLimits)()
+				downloaded, ok := msg.(gitReleaseDownloadedMsg)
+				if !ok {
+					t.Fatalf("unexpected message %#v", msg)
+				}
+				if downloaded.err != nil {
+					t.Fatal(downloaded.err)
+				}
+				for _, name := range []string{"index.js", "lib/legacy/index.js", "bin/cli"} {
+					if _, err := os.Stat(filepath.Join(dest, "package", filepath.FromSlash(name))); err != nil {
+						t.Error(err)
+					}
+				}
+				if valid, reason := validateCache(context.Background(), dest, "pkg@1.0.0", CacheVerifyFiles); !valid {
+					t.Errorf("the extraction can't be reused: %s", reason)
+				}
+			},
+		)
+	}
+}
+
+func TestExtractZipLimit(t *testing.T) {
+	zipped := archiveFormats(t)["zip"]
+	limits := ExtractLimits{MaxTotalBytes: int64(len(zipped)) - 1}
+	err := Extract(t.TempDir(), bytes.NewReader(zipped), limits)
+	var limitErr ExtractLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("got %v, expected the zip file to exceed the limit", err)
+	}
+	limits.MaxTotalBytes = int64(len(zipped))
+	if err := Extract(t.TempDir(), bytes.NewReader(zipped), limits); err != nil {
+		t.Error(err)
+	}
+}
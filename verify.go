@@ -0,0 +1,157 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultSourceAllowlist is the default allowlist of built artifacts,
+// expected in a published package but not in its tagged source.
+const defaultSourceAllowlist = "dist/**,build/**,*.d.ts,*.d.mts,*.d.cts,*.map"
+
+// sourceVerifiedMsg is a message that carries the files of a published release
+// absent from its tagged source on GitHub.
+type sourceVerifiedMsg struct {
+	release    string
+	suspicious []string // Normalized paths of the published files absent from the source
+	err        error
+}
+
+// ParseSourceAllowlist parses the comma-separated globs of the allowlist
+// of built artifacts, checking their syntax.
+func ParseSourceAllowlist(value string) ([]string, error) {
+	var globs []string
+	for _, glob := range strings.Split(value, ",") {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+		if _, err := path.Match(strings.TrimSuffix(glob, "/**"), ""); err != nil {
+			return nil, fmt.Errorf("invalid allowlist glob %q: %w", glob, err)
+		}
+		globs = append(globs, glob)
+	}
+	return globs, nil
+}
+
+// matchAllowlist returns whether a normalized path matches a glob of the allowlist.
+// Globs ending with `/**` match everything under a directory, globs without
+// a slash match the file name, and other globs match the full path.
+func matchAllowlist(globs []string, file string) bool {
+	for _, glob := range globs {
+		var matched bool
+		switch {
+		case strings.HasSuffix(glob, "/**"):
+			dir := strings.TrimSuffix(glob, "/**")
+			for current := path.Dir(file); current != "." && !matched; current = path.Dir(current) {
+				matched, _ = path.Match(dir, current)
+			}
+		case !strings.Contains(glob, "/"):
+			matched, _ = path.Match(glob, path.Base(file))
+		default:
+			matched, _ = path.Match(glob, file)
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// unverifiedFiles returns the published files absent from the source files,
+// ignoring the allowlisted ones, sorted. A published file is present if
+// a source file has the same path or ends with it, as the package may live
+// in a subdirectory of the repository, e.g. in a monorepo.
+func unverifiedFiles(published, source []string, allowlist []string) []string {
+	sourceSet := make(map[string]bool, len(source))
+	for _, file := range source {
+		sourceSet[file] = true
+	}
+	var suspicious []string
+	for _, file := range published {
+		if sourceSet[file] || matchAllowlist(allowlist, file) {
+			continue
+		}
+		found := false
+		for _, sourceFile := range source {
+			if strings.HasSuffix(sourceFile, "/"+file) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			suspicious = append(suspicious, file)
+		}
+	}
+	slices.Sort(suspicious)
+	return suspicious
+}
+
+// fetchGitHubSourceFiles downloads the source tarball of a tag of a GitHub repository,
+// and returns the normalized paths of its regular files.
+func fetchGitHubSourceFiles(ctx context.Context, ownerRepo, token, tag string) ([]string, error) {
+	request, err := newRequest(
+		ctx, http.MethodGet,
+		fmt.Sprintf("https://api.github.com/repos/%s/tarball/%s", strings.TrimSuffix(ownerRepo, ".git"), tag),
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/vnd.github+json")
+	if token != "" {
+		request.Header.Add("Authorization", fmt.Sprintf("token %s", token))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+	githubQuota.record(response)
+	if err = rateLimitError(response); err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download the source of %s: %s", tag, response.Status)
+	}
+
+	var files []string
+	err = WalkArchive(
+		response.Body, func(header *tar.Header, _ io.Reader) error {
+			if header.Typeflag == tar.TypeReg {
+				files = append(files, normalizeReleasePath(path.Clean(header.Name)))
+			}
+			return nil
+		},
+	)
+	return files, err
+}
+
+// VerifySource compares the files of a published release, as analyzed,
+// to the files of its tagged source on GitHub.
+func VerifySource(ctx context.Context, ownerRepo, token string, analysis AnalysisResult, allowlist []string) tea.Cmd {
+	return func() tea.Msg {
+		source, err := fetchGitHubSourceFiles(ctx, ownerRepo, token, analysis.releaseTag)
+		if err != nil {
+			return sourceVerifiedMsg{release: analysis.releaseTag, err: err}
+		}
+		published := make([]string, 0, len(analysis.files))
+		for file := range analysis.normalizedFiles() {
+			published = append(published, file)
+		}
+		return sourceVerifiedMsg{
+			release:    analysis.releaseTag,
+			suspicious: unverifiedFiles(published, source, allowlist),
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// updateGoldens rewrites the golden files of the views with their current rendering.
+var updateGoldens = flag.Bool("update-goldens", false, "rewrite the golden files of the views")
+
+// Size of the window the views are rendered in.
+const (
+	viewWidth  = 100
+	viewHeight = 24
+)
+
+// viewClock is the pinned time the views are rendered at.
+var viewClock = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+// viewReleases are the releases of the views, from the newest to the oldest.
+func viewReleases() []Release {
+	return []Release{
+		testRelease("v2.0.0", time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC)),
+		testRelease("v1.1.0", time.Date(2024, time.April, 2, 0, 0, 0, 0, time.UTC)),
+		testRelease("v1.0.0", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)),
+	}
+}
+
+// viewModel returns a model of the comparison of viewReleases in the state,
+// started 90 seconds before viewClock, in a window of the size of the views.
+func viewModel(state State) model {
+	m := model{
+		data: data{
+			ghRepo:        "owner/repo",
+			firstRelease:  "v1.0.0",
+			secondRelease: "v2.0.0",
+		},
+		state:       state,
+		spinner:     newSpinner(),
+		progressBar: newProgressBar(),
+		width:       viewWidth,
+		height:      viewHeight,
+		startedAt:   viewClock.Add(-90 * time.Second),
+		clock: func() time.Time {
+			return viewClock
+		},
+	}
+	m.data.releaseOrder = OrderDate
+	m.data.spikeCount = defaultSpikes
+	m.data.largeFileSize = defaultLargeFileSize
+	m.data.planReport = PlanReport{Fetched: 3, Kept: 3, ResolvedFrom: "v1.0.0", ResolvedTo: "v2.0.0"}
+	if state > StateFetching {
+		m.data.releases = viewReleases()
+	}
+	return m
+}
+
+// withFlags sets the flags of the form for the duration of the test.
+func withFlags(t *testing.T, values map[*string]string) {
+	t.Helper()
+	for pointer, value := range values {
+		previous := *pointer
+		*pointer = value
+		pointer := pointer
+		t.Cleanup(
+			func() {
+				*pointer = previous
+			},
+		)
+	}
+}
+
+// renderView renders the view of the model without any style, as in a terminal without colors.
+func renderView(t *testing.T, m model) string {
+	t.Helper()
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.Ascii)
+	t.Cleanup(
+		func() {
+			lipgloss.SetColorProfile(profile)
+		},
+	)
+	view := m.View()
+	if i := strings.IndexByte(view, '\x1b'); i >= 0 {
+		t.Errorf("the view has an escape sequence at %d", i)
+	}
+	// The lines are padded to the width of the window
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// checkGolden compares the rendering to the golden file at the slash-separated path within testdata,
+// rewriting it instead with -update-goldens.
+func checkGolden(t *testing.T, name, rendering string) {
+	t.Helper()
+	path := filepath.Join("testdata", filepath.FromSlash(name))
+	if *updateGoldens {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(rendering), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run the tests with -update-goldens to create it", err)
+	}
+	if rendering != string(golden) {
+		t.Errorf("the view differs from %s, run the tests with -update-goldens to accept it:\n%s", path, rendering)
+	}
+}
+
+func TestViewGoldens(t *testing.T) {
+	// The preferences of the summary and the times of the rate limit are the ones of the test
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(
+		func() {
+			time.Local = local
+		},
+	)
+
+	analyzed := func(m model, count int) model {
+		m.data.analysis = make([]AnalysisResult, len(m.data.releases))
+		lines := []uint{1500, 1100, 1000}
+		for i := len(m.data.releases) - count; i < len(m.data.releases); i++ {
+			m.data.analysis[i] = testAnalysis(m.data.releases[i].TagName, lines[i])
+		}
+		return m
+	}
+
+	for _, test := range []struct {
+		name  string
+		flags map[*string]string
+		model func() model
+	}{
+		{
+			"init", nil, func() model {
+				m := viewModel(StateInit)
+				m.data = data{}
+				m.inputs = newInputs(m.data)
+				m.startedAt = time.Time{}
+				return m
+			},
+		},
+		{
+			"init-prefilled", map[*string]string{ghRepo: "owner/repo", firstRelease: "v1.0.0"}, func() model {
+				m := viewModel(StateInit)
+				m.data.tokenSource = "GITHUB_TOKEN"
+				m.data.ghToken = "ghp_0123456789abcdef"
+				// The values entered before going back to the form
+				m.data.secondRelease, m.data.ignoreRegex = "v2.0.0", "beta"
+				m.inputs = newInputs(m.data)
+				m.startedAt = time.Time{}
+				return m
+			},
+		},
+		{
+			"checking", nil, func() model {
+				return viewModel(StateChecking)
+			},
+		},
+		{
+			"fetching", nil, func() model {
+				return viewModel(StateFetching)
+			},
+		},
+		{
+			"fetching-rate-limited", nil, func() model {
+				m := viewModel(StateFetching)
+				progress := fetchProgress{page: 3, perPage: 100, releases: viewReleases()[:2]}
+				m.fetchProgress = &progress
+				m.rateLimited = &rateLimitedMsg{progress, viewClock.Add(10 * time.Minute)}
+				return m
+			},
+		},
+		{
+			"fetching-waiting-reset", nil, func() model {
+				m := viewModel(StateFetching)
+				progress := fetchProgress{page: 3, perPage: 100, releases: viewReleases()[:2]}
+				m.fetchProgress = &progress
+				m.rateLimited = &rateLimitedMsg{progress, viewClock.Add(10 * time.Minute)}
+				m.waitingReset, m.resumeAt = true, viewClock.Add(10*time.Minute+time.Second)
+				return m
+			},
+		},
+		{
+			"fetching-same-tarball", nil, func() model {
+				m := viewModel(StateFetching)
+				m.data.releases = viewReleases()
+				m.data.planReport.SameTarball = "pkg@2.0.0"
+				m.confirmSameTarball = true
+				return m
+			},
+		},
+		{
+			"download-started", nil, func() model {
+				return viewModel(StateDownloadExtract)
+			},
+		},
+		{
+			"download-half", nil, func() model {
+				m := viewModel(StateDownloadExtract)
+				m.downloadProgress, m.downloadCacheCount = 1, 1
+				m.byteProgress = map[string]byteProgress{
+					"v1.0.0": {received: 20_000, total: 20_000, done: true},
+					"v1.1.0": {received: 10_000, total: 40_000},
+					"v2.0.0": {received: 5_000, total: -1},
+				}
+				return m
+			},
+		},
+		{
+			"download-failed", nil, func() model {
+				m := viewModel(StateDownloadExtract)
+				m.downloadProgress = 3
+				m.data.analysis = make([]AnalysisResult, len(m.data.releases))
+				m.data.analysis[1] = downloadFailure("v1.1.0", errors.New("404 Not Found"))
+				m.setStatus("v1.1.0", releaseFailed)
+				return m
+			},
+		},
+		{
+			"analyzing-started", nil, func() model {
+				return analyzed(viewModel(StateAnalyzing), 0)
+			},
+		},
+		{
+			"analyzing-partial", nil, func() model {
+				return analyzed(viewModel(StateAnalyzing), 2)
+			},
+		},
+		{
+			"analyzing-local", nil, func() model {
+				m := viewModel(StateAnalyzing)
+				baseline := testAnalysis("v1.0.0", 1000)
+				m.data.baseline = &baseline
+				m.data.ghRepo, m.data.localDir = "", "./package"
+				m.data.secondRelease = localReleaseTag
+				m.data.releases = nil
+				return m
+			},
+		},
+		{
+			"summary", nil, func() model {
+				m := analyzed(viewModel(StateSummary), 3)
+				m.finishedAt = viewClock.Add(-10 * time.Second)
+				return m.buildSummary()
+			},
+		},
+		{
+			"error", nil, func() model {
+				m := viewModel(StateFetching)
+				m.fail(errors.New("could not fetch the releases: 502 Bad Gateway"))
+				return m
+			},
+		},
+		{
+			"error-fatal", nil, func() model {
+				m := viewModel(StateInit)
+				m.startedAt = time.Time{}
+				m.failConfig(errors.New("invalid --top-files -1, expected a positive number"))
+				return m
+			},
+		},
+	} {
+		t.Run(
+			test.name, func(t *testing.T) {
+				withFlags(t, test.flags)
+				checkGolden(t, "views/"+test.name+".golden", renderView(t, test.model()))
+			},
+		)
+	}
+}
+
+// TestViewGoldensCoverStates checks that every state of the transition table has a golden view.
+func TestViewGoldensCoverStates(t *testing.T) {
+	names := map[State]string{
+		StateInit:            "init",
+		StateChecking:        "checking",
+		StateFetching:        "fetching",
+		StateDownloadExtract: "download-started",
+		StateAnalyzing:       "analyzing-started",
+		StateSummary:         "summary",
+	}
+	for flow, states := range transitions {
+		for from, events := range states {
+			for _, to := range events {
+				for _, state := range []State{from, to} {
+					name, ok := names[state]
+					if !ok {
+						t.Errorf("the %s state of flow %d has no golden view", state, flow)
+						continue
+					}
+					if _, err := os.Stat(filepath.Join("testdata", "views", name+".golden")); err != nil {
+						t.Errorf("the %s state of flow %d: %v", state, flow, err)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import "fmt"
+
+// packageRenameWarnings returns a warning for each change of package name
+// between consecutive releases, the results being ordered from the newest
+// to the oldest. Releases whose package name is unknown are skipped.
+func packageRenameWarnings(results []AnalysisResult) []string {
+	var warnings []string
+	previous := ""
+	for i := len(results) - 1; i >= 0; i-- {
+		name := results[i].packageName
+		if name == "" || results[i].failed != "" {
+			continue
+		}
+		if previous != "" && name != previous {
+			warnings = append(
+				warnings, fmt.Sprintf(
+					"the package was renamed from %s to %s at %s: the comparison spans different packages,"+
+						" and releases published under another name may be missing",
+					previous, name, results[i].releaseTag,
+				),
+			)
+		}
+		previous = name
+	}
+	return warnings
+}
+
+// packageMismatchWarnings returns a warning if every release is another package than expected,
+// the same one for all of them, suggesting the `--package` value to compare it.
+// Releases mismatching only partly are reported as failed on their own.
+func packageMismatchWarnings(results []AnalysisResult) []string {
+	if len(results) == 0 {
+		return nil
+	}
+	for _, result := range results {
+		if result.expectedPackage == "" ||
+			result.packageName != results[0].packageName || result.expectedPackage != results[0].expectedPackage {
+			return nil
+		}
+	}
+	return []string{
+		fmt.Sprintf(
+			"every release is the package %s instead of %s: to compare %s, use --package %s",
+			results[0].packageName, results[0].expectedPackage, results[0].packageName, results[0].packageName,
+		),
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+)
+
+// defaultFormattingThreshold is the default of `--formatting-threshold`.
+const defaultFormattingThreshold = "10%"
+
+// minFormattingChange is the change of lines below which a release isn't labeled as mostly formatting,
+// as the normalized change of small releases is meaningless.
+const minFormattingChange = 100
+
+// whitespaceNormalizer is an io.Writer normalizing the whitespace of the lines written to it,
+// so that reformat-only changes vanish: the trailing whitespace of the lines is trimmed,
+// carriage returns included, and runs of blank lines are collapsed into a single one.
+// It only keeps the trailing whitespace of the current line, whatever the size of the content.
+type whitespaceNormalizer struct {
+	out           io.Writer // Normalized content, nil to only count the lines
+	lines         uint      // Normalized lines, ending with \n
+	pending       []byte    // Whitespace of the current line, trimmed unless followed by content
+	contentLine   bool      // Whether the current line has content
+	previousBlank bool      // Whether the previous line was blank
+}
+
+func (n *whitespaceNormalizer) Write(p []byte) (int, error) {
+	for _, b := range p {
+		switch b {
+		case '\n':
+			blank := !n.contentLine
+			n.pending, n.contentLine = n.pending[:0], false
+			if blank && n.previousBlank {
+				continue
+			}
+			n.previousBlank = blank
+			n.lines++
+			if err := n.emit([]byte{'\n'}); err != nil {
+				return 0, err
+			}
+		case ' ', '\t', '\r', '\f', '\v':
+			n.pending = append(n.pending, b)
+		default:
+			if err := n.emit(append(n.pending, b)); err != nil {
+				return 0, err
+			}
+			n.pending, n.contentLine = n.pending[:0], true
+		}
+	}
+	return len(p), nil
+}
+
+func (n *whitespaceNormalizer) emit(p []byte) error {
+	if n.out == nil {
+		return nil
+	}
+	_, err := n.out.Write(p)
+	return err
+}
+
+// NormalizeWhitespace returns the content with the trailing whitespace of its lines trimmed
+// and its runs of blank lines collapsed, see whitespaceNormalizer.
+func NormalizeWhitespace(content []byte) []byte {
+	var buf bytes.Buffer
+	normalizer := whitespaceNormalizer{out: &buf}
+	_, _ = normalizer.Write(content)
+	return buf.Bytes()
+}
+
+// countFileLines counts the lines of a file by line ending, by kind following the comment syntax
+// of its path, and once normalized with `--ignore-whitespace`.
+func countFileLines(path string, reader io.Reader, settings AnalysisSettings) (LineCounts, error) {
+	classifier := newLineClassifier(path)
+	reader = io.TeeReader(reader, classifier)
+	if !settings.IgnoreWhitespace {
+		counts, err := CountLines(reader)
+		counts.Kinds = classifier.kinds
+		return counts, err
+	}
+	var normalizer whitespaceNormalizer
+	counts, err := CountLines(io.TeeReader(reader, &normalizer))
+	counts.Normalized, counts.Kinds = normalizer.lines, classifier.kinds
+	return counts, err
+}
+
+// measuredNormalizedLines returns the lines once whitespace normalized,
+// not measured without `--ignore-whitespace` or if the analysis failed.
+func (a AnalysisResult) measuredNormalizedLines() measure[int] {
+	if a.failed != "" || !a.whitespaceNormalized {
+		return measure[int]{}
+	}
+	return measure[int]{int(a.normalizedLines), true}
+}
+
+// formattingChange returns whether the normalized change of lines from the previous release
+// is at most the threshold ratio of the raw one, the release then being mostly reformatted.
+// A threshold of 0 disables it.
+func formattingChange(previous, current AnalysisResult, threshold float64) bool {
+	raw := current.measuredLines().since(previous.measuredLines())
+	normalized := current.measuredNormalizedLines().since(previous.measuredNormalizedLines())
+	if threshold <= 0 || !raw.measured || !normalized.measured {
+		return false
+	}
+	rawChange := math.Abs(float64(raw.value))
+	return rawChange >= minFormattingChange && math.Abs(float64(normalized.value)) <= threshold*rawChange
+}
+
+// formattingText renders the label of a release mostly reformatted from the previous one.
+func (l ListItem) formattingText() string {
+	if !l.mostlyFormatting || l.previous == nil {
+		return ""
+	}
+	raw := l.measuredLines().since(l.previous.measuredLines())
+	normalized := l.measuredNormalizedLines().since(l.previous.measuredNormalizedLines())
+	return fmt.Sprintf("mostly formatting (raw %+d / normalized %+d lines)", raw.value, normalized.value)
+}
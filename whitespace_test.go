@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// whitespaceCases are contents with their normalized form.
+var whitespaceCases = []struct {
+	name       string
+	content    string
+	normalized string
+}{
+	{"empty", "", ""},
+	{"trailing spaces", "a  \nb\t\n", "a\nb\n"},
+	{"carriage returns", "a\r\nb \r\n", "a\nb\n"},
+	{"inner whitespace", "a \t b\n", "a \t b\n"},
+	{"blank lines", "a\n\n\n\nb\n", "a\n\nb\n"},
+	{"whitespace-only lines", "a\n  \n\t\r\n\nb\n", "a\n\nb\n"},
+	{"leading blank lines", "\n\n\na\n", "\na\n"},
+	{"no final newline", "a\nb  ", "a\nb"},
+	{"form feeds", "a\f\v\n", "a\n"},
+}
+
+func TestNormalizeWhitespace(t *testing.T) {
+	for _, test := range whitespaceCases {
+		if normalized := string(NormalizeWhitespace([]byte(test.content))); normalized != test.normalized {
+			t.Errorf("%s: normalized %q to %q, expected %q", test.name, test.content, normalized, test.normalized)
+		}
+	}
+}
+
+// TestWhitespaceNormalizerSplitWrites checks that the whitespace split across writes,
+// such as a \r\n or a run of blank lines over two buffers, is normalized the same.
+func TestWhitespaceNormalizerSplitWrites(t *testing.T) {
+	for _, test := range whitespaceCases {
+		lines := uint(bytes.Count([]byte(test.normalized), []byte("\n")))
+		for split := 0; split <= len(test.content); split++ {
+			var buf bytes.Buffer
+			normalizer := whitespaceNormalizer{out: &buf}
+			for _, part := range []string{test.content[:split], test.content[split:]} {
+				if n, err := normalizer.Write([]byte(part)); n != len(part) || err != nil {
+					t.Fatalf("%s: wrote %d bytes of %d: %v", test.name, n, len(part), err)
+				}
+			}
+			if buf.String() != test.normalized || normalizer.lines != lines {
+				t.Errorf(
+					"%s: split at %d, normalized %q to %q in %d lines, expected %q in %d lines",
+					test.name, split, test.content, buf.String(), normalizer.lines, test.normalized, lines,
+				)
+			}
+		}
+
+		// One byte at a time, only counting the lines
+		var counter whitespaceNormalizer
+		for i := range test.content {
+			_, _ = counter.Write([]byte{test.content[i]})
+		}
+		if counter.lines != lines {
+			t.Errorf("%s: counted %d lines byte by byte, expected %d", test.name, counter.lines, lines)
+		}
+	}
+}
+
+func TestFormattingChange(t *testing.T) {
+	release := func(lines, normalized uint) AnalysisResult {
+		analysis := testAnalysis("v1.0.0", lines)
+		analysis.normalizedLines, analysis.whitespaceNormalized = normalized, true
+		return analysis
+	}
+	failed := failedAnalysis("v1.1.0", "timed out")
+	notNormalized := testAnalysis("v1.1.0", 2000)
+	for _, test := range []struct {
+		name              string
+		previous, current AnalysisResult
+		threshold         float64
+		formatting        bool
+	}{
+		{"reformatted", release(1000, 900), release(2000, 910), 0.1, true},
+		{"at the threshold", release(1000, 900), release(2000, 1000), 0.1, true},
+		{"above the threshold", release(1000, 900), release(2000, 1001), 0.1, false},
+		{"shrinking", release(2000, 900), release(1000, 905), 0.1, true},
+		{"at the floor", release(1000, 900), release(1000+minFormattingChange, 900), 0.1, true},
+		{"below the floor", release(1000, 900), release(1000+minFormattingChange-1, 900), 0.1, false},
+		{"unchanged", release(1000, 900), release(1000, 900), 0.1, false},
+		{"disabled", release(1000, 900), release(2000, 910), 0, false},
+		{"not normalized", release(1000, 900), notNormalized, 0.1, false},
+		{"failed", release(1000, 900), failed, 0.1, false},
+		{"after a failure", failed, release(2000, 910), 0.1, false},
+	} {
+		if formatting := formattingChange(test.previous, test.current, test.threshold); formatting != test.formatting {
+			t.Errorf("%s: got %t, expected %t", test.name, formatting, test.formatting)
+		}
+	}
+}